@@ -37,7 +37,10 @@ func main() {
 	app := fiber.New()
 
 	// Routing
-	routes.RegisterRoutes(app, db, appLogger)
+	if err := routes.RegisterRoutes(app, cfg, db, appLogger); err != nil {
+		appLogger.Error("Route setup failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
 
 	// Start server
 	appLogger.Info("Server running on port " + cfg.AppPort)
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"mini-pay-backend/internal/config"
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/repositories"
+	"mini-pay-backend/internal/services"
+)
+
+// reconcile recomputes every wallet from its transaction history and prints
+// the report as JSON on stdout. It exits with status 1 when discrepancies are found.
+//
+// reconcile her cüzdanı işlem geçmişinden yeniden hesaplar ve raporu stdout'a
+// JSON olarak yazar. Sorun bulunursa 1 koduyla çıkar.
+func main() {
+	freeze := flag.Bool("freeze", false, "freeze wallets with discrepancies")
+	reportFile := flag.String("append-to", "", "also append the report as a JSON line to this file")
+	flag.Parse()
+
+	// Load configuration
+	cfg := config.LoadConfig()
+
+	// Logger init (logs go to stderr, the report to stdout)
+	appLogger, err := logger.NewZapLogger(cfg.LogLevel)
+	if err != nil {
+		log.Fatal("Logger failed to start:", err)
+	}
+
+	// DB init
+	db, err := database.NewGormDB(cfg)
+	if err != nil {
+		log.Fatal("Database connection failed:", err)
+	}
+
+	reconciliationService := services.NewReconciliationService(
+		repositories.NewUnitOfWork(db),
+		repositories.NewWalletRepository(db),
+		appLogger,
+	)
+
+	report, err := reconciliationService.Run(*freeze)
+	if err != nil {
+		log.Fatal("Reconciliation failed:", err)
+	}
+
+	if *reportFile != "" {
+		if err := services.AppendReport(*reportFile, report); err != nil {
+			log.Fatal("Failed to write report:", err)
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal("Failed to print report:", err)
+	}
+
+	if len(report.Discrepancies) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"mini-pay-backend/internal/config"
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/repositories"
+	"mini-pay-backend/internal/services"
+)
+
+// verifychain walks the hash chain of every wallet's transaction history and prints
+// the report as JSON on stdout. It exits with status 1 when a broken link is found.
+//
+// verifychain her cüzdanın işlem geçmişindeki hash zincirini dolaşır ve raporu stdout'a
+// JSON olarak yazar. Kırık bir halka bulunursa 1 koduyla çıkar.
+func main() {
+	backfill := flag.Bool("backfill", false, "hash rows written before the chain existed, then verify")
+	flag.Parse()
+
+	// Load configuration
+	cfg := config.LoadConfig()
+
+	// Logger init (logs go to stderr, the report to stdout)
+	appLogger, err := logger.NewZapLogger(cfg.LogLevel)
+	if err != nil {
+		log.Fatal("Logger failed to start:", err)
+	}
+
+	// DB init
+	db, err := database.NewGormDB(cfg)
+	if err != nil {
+		log.Fatal("Database connection failed:", err)
+	}
+
+	chainService := services.NewChainService(repositories.NewTransactionRepository(db), appLogger)
+
+	if *backfill {
+		if _, err := chainService.Backfill(); err != nil {
+			log.Fatal("Backfill failed:", err)
+		}
+	}
+
+	report, err := chainService.Verify()
+	if err != nil {
+		log.Fatal("Verification failed:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal("Failed to print report:", err)
+	}
+
+	if len(report.Breaks) > 0 {
+		os.Exit(1)
+	}
+}
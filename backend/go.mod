@@ -2,7 +2,10 @@ module mini-pay-backend
 
 go 1.24.4
 
-require golang.org/x/crypto v0.43.0
+require (
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	golang.org/x/crypto v0.43.0
+)
 
 require (
 	github.com/andybalholm/brotli v1.1.0 // indirect
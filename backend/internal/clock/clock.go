@@ -0,0 +1,67 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock interface defines where services read the current time from
+// Clock arayüzü servislerin mevcut zamanı nereden okuduğunu tanımlar
+//
+// Why do we use this?
+// Time-based jobs (schedules, expiries) can be driven by a manual clock,
+// so their behavior is deterministic.
+// Zamana bağlı işler (zamanlamalar, süre dolumları) elle ilerletilen bir saatle
+// çalıştırılabilir, böylece davranışları belirlenebilir olur.
+type Clock interface {
+	// Now returns the current time
+	// Now mevcut zamanı döndürür
+	Now() time.Time
+}
+
+// System is the real wall clock
+// System gerçek duvar saatidir
+type System struct{}
+
+// Now returns time.Now()
+// Now time.Now() döndürür
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+// Manual is a clock that only moves when told to
+// Manual sadece söylendiğinde ilerleyen bir saattir
+type Manual struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManual creates a manual clock stopped at the given time
+// NewManual verilen zamanda durmuş bir manuel saat oluşturur
+func NewManual(now time.Time) *Manual {
+	return &Manual{now: now}
+}
+
+// Now returns the time the clock is set to
+// Now saatin ayarlı olduğu zamanı döndürür
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Set moves the clock to the given time
+// Set saati verilen zamana taşır
+func (m *Manual) Set(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
+
+// Advance moves the clock forward by d
+// Advance saati d kadar ileri alır
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +17,89 @@ type AppConfig struct {
 	DBName    string
 	JWTSecret []byte
 	LogLevel  string
+
+	// DefaultCurrency is used for the wallet opened at registration and
+	// for requests that do not specify a currency
+	// DefaultCurrency kayıtta açılan cüzdan ve para birimi belirtmeyen istekler için kullanılır
+	DefaultCurrency string
+
+	// ReversalWindow is how long a sender may reverse their own transfer
+	// ReversalWindow göndericinin kendi transferini geri alabileceği süredir
+	ReversalWindow time.Duration
+
+	// RatesFile is an optional JSON exchange rate table; empty uses the built-in table
+	// RatesFile isteğe bağlı JSON kur tablosudur; boşsa gömülü tablo kullanılır
+	RatesFile string
+
+	// FxSpreadBps is the margin taken on conversions in basis points (50 = 0.5%)
+	// FxSpreadBps çevrimlerde alınan marjdır, baz puan cinsinden (50 = %0.5)
+	FxSpreadBps int64
+
+	// FxQuoteTTL is how long a quoted rate stays locked
+	// FxQuoteTTL teklif edilen kurun ne kadar süre sabit kalacağıdır
+	FxQuoteTTL time.Duration
+
+	// FeesFile is a JSON fee schedule; empty uses the built-in table
+	// FeesFile JSON ücret tablosudur; boşsa gömülü tablo kullanılır
+	FeesFile string
+
+	// HoldTTL is how long an authorization hold lasts when the request gives no expiry
+	// HoldTTL istek süre belirtmediğinde provizyonun ne kadar süre geçerli olacağıdır
+	HoldTTL time.Duration
+
+	// HoldSweepInterval is how often expired holds, money requests and payment links are closed and due escrows released
+	// HoldSweepInterval süresi dolan provizyon, para isteği ve ödeme bağlantılarının kapatılma ve zamanı gelen emanetlerin serbest bırakılma sıklığıdır
+	HoldSweepInterval time.Duration
+
+	// MoneyRequestTTL is how long a money request can be paid when no expiry is given
+	// MoneyRequestTTL süre verilmediğinde bir para isteğinin ne kadar süre ödenebileceğidir
+	MoneyRequestTTL time.Duration
+
+	// EscrowReleaseAfter is how long a buyer has to confirm or dispute before an escrow is released to the seller
+	// EscrowReleaseAfter bir emanet satıcıya serbest bırakılmadan önce alıcının onay veya itiraz için süresidir
+	EscrowReleaseAfter time.Duration
+
+	// QRCountryCode and QRMerchantCity are written into payment QR codes (EMVCo requires both)
+	// QRCountryCode ve QRMerchantCity ödeme QR kodlarına yazılır (EMVCo ikisini de zorunlu tutar)
+	QRCountryCode  string
+	QRMerchantCity string
+
+	// IdempotencyClaimTTL is how long an unfinished Idempotency-Key claim counts as still running before retries are told it did not finish
+	// IdempotencyClaimTTL bitmemiş bir Idempotency-Key sahiplenmesinin, tekrarlara bitmediği söylenmeden önce hâlâ çalışıyor sayıldığı süredir
+	IdempotencyClaimTTL time.Duration
+
+	// SchedulerInterval is how often due scheduled transfers are looked for
+	// SchedulerInterval zamanı gelen transferlerin ne sıklıkla arandığıdır
+	SchedulerInterval time.Duration
+
+	// ScheduleRetryDelay and ScheduleMaxAttempts control retries after insufficient funds
+	// ScheduleRetryDelay ve ScheduleMaxAttempts yetersiz bakiye sonrası tekrar denemeleri yönetir
+	ScheduleRetryDelay  time.Duration
+	ScheduleMaxAttempts int
+
+	// ReconcileInterval runs the in-process reconciliation job; 0 disables it
+	// ReconcileInterval uygulama içi mutabakat işini çalıştırır; 0 kapatır
+	ReconcileInterval time.Duration
+
+	// ReconcileFreeze makes the job freeze wallets with discrepancies
+	// ReconcileFreeze işin sorunlu cüzdanları dondurmasını sağlar
+	ReconcileFreeze bool
+
+	// ReconcileReportFile receives every job report as a JSON line; empty only logs
+	// ReconcileReportFile her iş raporunu JSON satırı olarak alır; boşsa sadece loglanır
+	ReconcileReportFile string
+
+	// Default withdraw and transfer caps in minor units of DefaultCurrency; 0 means no cap.
+	// Admins can override them per user.
+	//
+	// DefaultCurrency alt birimi cinsinden varsayılan çekim ve transfer sınırları; 0 sınır yok demektir.
+	// Adminler bunları kullanıcı bazında değiştirebilir.
+	LimitWithdrawPerTransaction int64
+	LimitWithdrawDaily          int64
+	LimitWithdrawMonthly        int64
+	LimitTransferPerTransaction int64
+	LimitTransferDaily          int64
+	LimitTransferMonthly        int64
 }
 
 // LoadConfig loads environment variables and constructs AppConfig
@@ -32,6 +117,40 @@ func LoadConfig() *AppConfig {
 		DBName:    getEnv("DB_NAME", "mini_pay.db"),
 		JWTSecret: []byte(getEnv("JWT_SECRET", "CHANGE_THIS_SECRET_LATER")),
 		LogLevel:  getEnv("LOG_LEVEL", "development"),
+
+		DefaultCurrency: getEnv("DEFAULT_CURRENCY", "TRY"),
+		ReversalWindow:  time.Duration(getEnvInt("REVERSAL_WINDOW_MINUTES", 30)) * time.Minute,
+
+		RatesFile:   getEnv("RATES_FILE", ""),
+		FxSpreadBps: int64(getEnvInt("FX_SPREAD_BPS", 50)),
+		FxQuoteTTL:  time.Duration(getEnvInt("FX_QUOTE_TTL_SECONDS", 60)) * time.Second,
+		FeesFile:    getEnv("FEES_FILE", ""),
+
+		HoldTTL:           time.Duration(getEnvInt("HOLD_TTL_HOURS", 168)) * time.Hour,
+		HoldSweepInterval: time.Duration(getEnvPositiveInt("HOLD_SWEEP_SECONDS", 60)) * time.Second,
+		MoneyRequestTTL:   time.Duration(getEnvInt("MONEY_REQUEST_TTL_HOURS", 72)) * time.Hour,
+
+		EscrowReleaseAfter: time.Duration(getEnvInt("ESCROW_RELEASE_HOURS", 336)) * time.Hour,
+
+		IdempotencyClaimTTL: time.Duration(getEnvInt("IDEMPOTENCY_CLAIM_TTL_SECONDS", 300)) * time.Second,
+
+		QRCountryCode:  getEnv("QR_COUNTRY_CODE", "TR"),
+		QRMerchantCity: getEnv("QR_MERCHANT_CITY", "Istanbul"),
+
+		SchedulerInterval:   time.Duration(getEnvPositiveInt("SCHEDULER_INTERVAL_SECONDS", 30)) * time.Second,
+		ScheduleRetryDelay:  time.Duration(getEnvInt("SCHEDULE_RETRY_MINUTES", 60)) * time.Minute,
+		ScheduleMaxAttempts: getEnvInt("SCHEDULE_MAX_ATTEMPTS", 3),
+
+		ReconcileInterval:   time.Duration(getEnvInt("RECONCILE_INTERVAL_MINUTES", 0)) * time.Minute,
+		ReconcileFreeze:     getEnvBool("RECONCILE_FREEZE", false),
+		ReconcileReportFile: getEnv("RECONCILE_REPORT_FILE", ""),
+
+		LimitWithdrawPerTransaction: int64(getEnvInt("LIMIT_WITHDRAW_PER_TRANSACTION", 500000)),
+		LimitWithdrawDaily:          int64(getEnvInt("LIMIT_WITHDRAW_DAILY", 1000000)),
+		LimitWithdrawMonthly:        int64(getEnvInt("LIMIT_WITHDRAW_MONTHLY", 5000000)),
+		LimitTransferPerTransaction: int64(getEnvInt("LIMIT_TRANSFER_PER_TRANSACTION", 1000000)),
+		LimitTransferDaily:          int64(getEnvInt("LIMIT_TRANSFER_DAILY", 2000000)),
+		LimitTransferMonthly:        int64(getEnvInt("LIMIT_TRANSFER_MONTHLY", 10000000)),
 	}
 
 	return cfg
@@ -45,3 +164,34 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// Helper: get integer env or fallback
+// Yardımcı: tamsayı env değişkeni yoksa veya geçersizse varsayılan değeri kullan
+func getEnvInt(key string, fallback int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// Helper: get positive integer env or fallback; used for intervals a ticker cannot run with at 0
+// Yardımcı: pozitif tamsayı env değişkeni yoksa veya geçersizse varsayılan değeri kullan; ticker'ın 0 ile çalışamadığı aralıklar için
+func getEnvPositiveInt(key string, fallback int) int {
+	if value := getEnvInt(key, fallback); value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// Helper: get boolean env or fallback
+// Yardımcı: boolean env değişkeni yoksa veya geçersizse varsayılan değeri kullan
+func getEnvBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
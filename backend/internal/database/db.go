@@ -21,4 +21,11 @@ type DB interface {
 	// Returns the underlying *gorm.DB instance.
 	// İçteki *gorm.DB bağlantısını döndürür.
 	GetDB() *gorm.DB
+
+	// Transaction
+	// Runs fn inside a database transaction. The DB passed to fn is bound to it;
+	// returning an error rolls everything back.
+	// fn'i bir veritabanı transaction'ı içinde çalıştırır. fn'e verilen DB bu
+	// transaction'a bağlıdır; hata dönmesi her şeyi geri alır.
+	Transaction(fn func(tx DB) error) error
 }
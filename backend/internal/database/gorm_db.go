@@ -2,6 +2,10 @@ package database
 
 import (
 	"errors"
+	"reflect"
+	"strings"
+	"time"
+
 	"mini-pay-backend/internal/config"
 	"mini-pay-backend/internal/models"
 
@@ -26,25 +30,45 @@ func NewGormDB(cfg *config.AppConfig) (*GormDB, error) {
 	var dialector gorm.Dialector
 
 	if cfg.DBDriver == "sqlite" {
-		dialector = sqlite.Open(cfg.DBName)
+		dialector = sqlite.Open(sqliteDSN(cfg.DBName))
 	} else {
 		return nil, errors.New("unsupported DB driver")
 	}
 
 	// Opens a SQLite database file named "mini_pay.db".
 	// "mini_pay.db" isminde bir SQLite veritabanı dosyasını açar.
-	database, err := gorm.Open(dialector, &gorm.Config{})
+	// Timestamps are written in UTC; see storeTimesInUTC.
+	// Zaman damgaları UTC olarak yazılır; bkz. storeTimesInUTC.
+	database, err := gorm.Open(dialector, &gorm.Config{
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	})
 	if err != nil {
 		// If an error occurs, return it to the caller.
 		// Bir hata olursa, çağırana hatayı döndürür.
 		return nil, err
 
 	}
+	storeTimesInUTC(database)
 	// Run database migrations
 	// Veritabanı migrasyonlarını çalıştırır
 	database.AutoMigrate(&models.User{})
 	database.AutoMigrate(&models.Wallet{})
 	database.AutoMigrate(&models.Transaction{})
+	database.AutoMigrate(&models.LedgerAccount{}, &models.JournalEntry{}, &models.Posting{})
+	database.AutoMigrate(&models.IdempotencyKey{})
+	database.AutoMigrate(&models.FxQuote{})
+	database.AutoMigrate(&models.Hold{})
+	database.AutoMigrate(&models.ScheduledTransfer{})
+	database.AutoMigrate(&models.MoneyRequest{})
+	database.AutoMigrate(&models.LimitOverride{})
+	database.AutoMigrate(&models.Pot{})
+	database.AutoMigrate(&models.JointWallet{}, &models.JointMember{}, &models.JointWithdrawal{}, &models.JointApproval{})
+	database.AutoMigrate(&models.Group{}, &models.GroupMember{}, &models.Expense{}, &models.ExpenseShare{}, &models.Settlement{})
+	database.AutoMigrate(&models.Escrow{})
+	database.AutoMigrate(&models.Merchant{}, &models.PaymentLink{}, &models.LinkPayment{})
+	migrateMultiCurrency(database)
+	migrateHistoryIndexes(database)
+	migrateJointWallets(database)
 
 	// Return a new GormDB containing the opened database connection.
 	// Açılan veritabanı bağlantısını içeren yeni bir GormDB döndürür.
@@ -57,3 +81,116 @@ func NewGormDB(cfg *config.AppConfig) (*GormDB, error) {
 func (g *GormDB) GetDB() *gorm.DB {
 	return g.db
 }
+
+// Transaction
+// Runs fn inside a GORM transaction; nested calls become savepoints.
+// fn'i bir GORM transaction'ı içinde çalıştırır; iç içe çağrılar savepoint olur.
+func (g *GormDB) Transaction(fn func(tx DB) error) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&GormDB{db: tx})
+	})
+}
+
+// migrateMultiCurrency
+// Upgrades databases created before multi-currency wallets:
+// drops the old one-wallet-per-user index and gives legacy system accounts a currency suffix.
+// Existing wallets, transactions and accounts default to TRY via their column defaults.
+//
+// Çoklu para birimi öncesi oluşturulmuş veritabanlarını günceller:
+// kullanıcı başına tek cüzdan index'ini kaldırır ve eski sistem hesaplarına para birimi eki verir.
+// Mevcut cüzdan, işlem ve hesaplar kolon varsayılanları ile TRY olur.
+func migrateMultiCurrency(database *gorm.DB) {
+	if database.Migrator().HasIndex(&models.Wallet{}, "idx_wallets_user_id") {
+		database.Migrator().DropIndex(&models.Wallet{}, "idx_wallets_user_id")
+	}
+
+	database.Model(&models.LedgerAccount{}).
+		Where("type = ? AND code IN ?", models.LedgerAccountSystem, []string{
+			models.SystemAccountDeposits,
+			models.SystemAccountWithdrawals,
+			models.SystemAccountOpening,
+		}).
+		Update("code", gorm.Expr("code || ':' || currency"))
+}
+
+// migrateHistoryIndexes
+// Adds the index behind paginated history: a user's rows in (created_at, id) order,
+// so each page is an index range scan instead of a sort over the whole history.
+//
+// Sayfalı geçmişin kullandığı index'i ekler: kullanıcının satırları (created_at, id) sırasında,
+// böylece her sayfa tüm geçmişi sıralamak yerine index üzerinde aralık taramasıdır.
+func migrateHistoryIndexes(database *gorm.DB) {
+	database.Exec("CREATE INDEX IF NOT EXISTS idx_transactions_user_created ON transactions (user_id, created_at, id)")
+}
+
+// migrateJointWallets
+// Drops the old (user_id, currency) wallet index: joint wallets all have user 0, so a wallet
+// is now unique by (user_id, joint_id, currency), which AutoMigrate creates as idx_wallet_holder.
+//
+// Eski (user_id, currency) cüzdan index'ini kaldırır: tüm ortak cüzdanların kullanıcısı 0'dır,
+// bu yüzden bir cüzdan artık AutoMigrate'in idx_wallet_holder olarak oluşturduğu
+// (user_id, joint_id, currency) ile benzersizdir.
+func migrateJointWallets(database *gorm.DB) {
+	if database.Migrator().HasIndex(&models.Wallet{}, "idx_wallet_user_currency") {
+		database.Migrator().DropIndex(&models.Wallet{}, "idx_wallet_user_currency")
+	}
+}
+
+// storeTimesInUTC
+// SQLite keeps a time as text in the zone it was written in and compares that text as is,
+// so a value written in local time and a bound UTC time do not line up. Every time field
+// of a created or updated model is moved to UTC before it is written; repositories bind
+// the times they compare against in UTC as well.
+//
+// SQLite bir zamanı yazıldığı dilimde metin olarak tutar ve bu metni olduğu gibi karşılaştırır,
+// bu yüzden yerel saatle yazılmış bir değer ile bağlanan bir UTC zamanı örtüşmez. Oluşturulan
+// veya güncellenen modelin her zaman alanı yazılmadan önce UTC'ye taşınır; repository'ler de
+// karşılaştırdıkları zamanları UTC olarak bağlar.
+func storeTimesInUTC(database *gorm.DB) {
+	toUTC := func(tx *gorm.DB) {
+		if tx.Statement.Schema == nil {
+			return
+		}
+		convert := func(value reflect.Value) {
+			for _, field := range tx.Statement.Schema.Fields {
+				current, zero := field.ValueOf(tx.Statement.Context, value)
+				if zero {
+					continue
+				}
+				switch t := current.(type) {
+				case time.Time:
+					field.Set(tx.Statement.Context, value, t.UTC())
+				case *time.Time:
+					utc := t.UTC()
+					field.Set(tx.Statement.Context, value, &utc)
+				}
+			}
+		}
+
+		switch value := reflect.Indirect(tx.Statement.ReflectValue); value.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < value.Len(); i++ {
+				convert(reflect.Indirect(value.Index(i)))
+			}
+		case reflect.Struct:
+			convert(value)
+		}
+	}
+
+	database.Callback().Create().Before("gorm:create").Register("minipay:utc_times", toUTC)
+	database.Callback().Update().Before("gorm:update").Register("minipay:utc_times", toUTC)
+}
+
+// sqliteDSN
+// Makes write transactions take the lock up front (BEGIN IMMEDIATE) and wait
+// for each other instead of failing with "database is locked".
+//
+// Yazma transaction'larının kilidi baştan almasını (BEGIN IMMEDIATE) ve
+// "database is locked" hatası yerine birbirini beklemesini sağlar.
+func sqliteDSN(name string) string {
+	separator := "?"
+	if strings.Contains(name, "?") {
+		separator = "&"
+	}
+	return name + separator + "_txlock=immediate&_busy_timeout=5000"
+}
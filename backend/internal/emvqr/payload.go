@@ -0,0 +1,336 @@
+package emvqr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Data object IDs of an EMVCo merchant-presented payload used by mini-pay
+// mini-pay'in kullandığı EMVCo satıcı tarafından gösterilen verinin veri nesnesi ID'leri
+const (
+	idFormatIndicator   = "00"
+	idInitiationMethod  = "01"
+	idAccountFirst      = 26
+	idAccountLast       = 51
+	idMerchantCategory  = "52"
+	idCurrency          = "53"
+	idAmount            = "54"
+	idCountryCode       = "58"
+	idMerchantName      = "59"
+	idMerchantCity      = "60"
+	idCRC               = "63"
+	subGloballyUniqueID = "00"
+	subAccountType      = "01"
+	subAccountRef       = "02"
+)
+
+// Values of the point of initiation method: static codes are reused, dynamic ones carry an amount
+// Başlatma yöntemi değerleri: statik kodlar tekrar kullanılır, dinamik olanlar bir tutar taşır
+const (
+	initiationStatic  = "11"
+	initiationDynamic = "12"
+)
+
+// GloballyUniqueID marks the merchant account template that belongs to mini-pay
+// GloballyUniqueID mini-pay'e ait satıcı hesap şablonunu işaretler
+const GloballyUniqueID = "com.minipay.wallet"
+
+// Account types a payload can point to
+// Bir verinin işaret edebileceği hesap türleri
+const (
+	AccountMerchant    = "merchant"
+	AccountUser        = "user"
+	AccountPaymentLink = "payment_link"
+)
+
+// maxAccountRefLength keeps the account template well inside its 99 character limit
+// maxAccountRefLength hesap şablonunu 99 karakterlik sınırının rahatça içinde tutar
+const maxAccountRefLength = 32
+
+var (
+	// ErrInvalidPayload is returned for text that is not a well-formed EMVCo payload
+	// ErrInvalidPayload iyi biçimlenmiş bir EMVCo verisi olmayan metin için döner
+	ErrInvalidPayload = errors.New("invalid QR payload")
+
+	// ErrChecksum is returned when the CRC of the payload does not match its content
+	// ErrChecksum verinin CRC'si içeriğiyle eşleşmediğinde döner
+	ErrChecksum = errors.New("QR payload checksum does not match")
+
+	// ErrForeignPayload is returned for valid payloads of other payment schemes
+	// ErrForeignPayload başka ödeme sistemlerine ait geçerli veriler için döner
+	ErrForeignPayload = errors.New("QR code is not a mini-pay payment code")
+)
+
+// amountPattern is the EMVCo amount format: digits with an optional "." and decimals.
+// Leading zeros are refused, as models.ParseDecimal does, so every valid code can be paid.
+//
+// amountPattern EMVCo tutar biçimidir: rakamlar ve isteğe bağlı "." ile ondalıklar.
+// models.ParseDecimal gibi baştaki sıfırlar reddedilir, böylece her geçerli kod ödenebilir.
+var amountPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
+// Payload is the content of a mini-pay QR code. A payload with an Amount is dynamic
+// (a single checkout); one without is static and the payer enters the amount.
+//
+// Payload bir mini-pay QR kodunun içeriğidir. Amount içeren veri dinamiktir
+// (tek bir ödeme); içermeyen statiktir ve tutarı ödeyen girer.
+type Payload struct {
+	// AccountType and AccountRef are who receives the money. The ref is an opaque token
+	// the issuer resolves, never a database ID, so codes cannot be enumerated.
+	//
+	// AccountType ve AccountRef parayı kimin alacağıdır. Ref, veren tarafın çözdüğü opak bir
+	// belirteçtir, asla bir veritabanı ID'si değildir; böylece kodlar sıralanarak taranamaz.
+	AccountType string
+	AccountRef  string
+
+	// MerchantCategoryCode is the ISO 18245 category ("0000" when not classified)
+	// MerchantCategoryCode ISO 18245 kategorisidir (sınıflandırılmamışsa "0000")
+	MerchantCategoryCode string
+
+	// Currency is the ISO 4217 numeric code (e.g. "949")
+	// Currency ISO 4217 sayısal kodudur (örn. "949")
+	Currency string
+
+	// Amount is a decimal string like "25.00"; empty in static codes
+	// Amount "25.00" gibi ondalık bir metindir; statik kodlarda boştur
+	Amount string
+
+	// CountryCode (ISO 3166-1 alpha-2), MerchantName and MerchantCity are shown by banking apps
+	// CountryCode (ISO 3166-1 alpha-2), MerchantName ve MerchantCity bankacılık uygulamalarında gösterilir
+	CountryCode  string
+	MerchantName string
+	MerchantCity string
+}
+
+// Dynamic reports whether the payload fixes the amount
+// Dynamic verinin tutarı sabitleyip sabitlemediğini bildirir
+func (p *Payload) Dynamic() bool {
+	return p.Amount != ""
+}
+
+// Encode validates the payload and writes it as EMVCo TLV text ending with its CRC
+// Encode veriyi doğrular ve CRC'siyle biten EMVCo TLV metni olarak yazar
+func Encode(p *Payload) (string, error) {
+	if err := p.validate(); err != nil {
+		return "", err
+	}
+
+	initiation := initiationStatic
+	if p.Dynamic() {
+		initiation = initiationDynamic
+	}
+
+	var b strings.Builder
+	writeTLV(&b, idFormatIndicator, "01")
+	writeTLV(&b, idInitiationMethod, initiation)
+
+	var account strings.Builder
+	writeTLV(&account, subGloballyUniqueID, GloballyUniqueID)
+	writeTLV(&account, subAccountType, p.AccountType)
+	writeTLV(&account, subAccountRef, p.AccountRef)
+	writeTLV(&b, strconv.Itoa(idAccountFirst), account.String())
+
+	writeTLV(&b, idMerchantCategory, p.MerchantCategoryCode)
+	writeTLV(&b, idCurrency, p.Currency)
+	if p.Dynamic() {
+		writeTLV(&b, idAmount, p.Amount)
+	}
+	writeTLV(&b, idCountryCode, p.CountryCode)
+	writeTLV(&b, idMerchantName, p.MerchantName)
+	writeTLV(&b, idMerchantCity, p.MerchantCity)
+
+	// The CRC covers everything before it, including its own ID and length
+	// CRC kendisinden önceki her şeyi, kendi ID'si ve uzunluğu dahil kapsar
+	b.WriteString(idCRC + "04")
+	return b.String() + fmt.Sprintf("%04X", CRC16(b.String())), nil
+}
+
+// Parse checks the CRC and structure of an EMVCo payload and reads the mini-pay account from it
+// Parse bir EMVCo verisinin CRC'sini ve yapısını kontrol eder ve içinden mini-pay hesabını okur
+func Parse(text string) (*Payload, error) {
+	text = strings.TrimSpace(text)
+	if len(text) < 8 || text[len(text)-8:len(text)-4] != idCRC+"04" {
+		return nil, fmt.Errorf("%w: missing CRC", ErrInvalidPayload)
+	}
+	want, err := strconv.ParseUint(text[len(text)-4:], 16, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed CRC", ErrInvalidPayload)
+	}
+	if uint16(want) != CRC16(text[:len(text)-4]) {
+		return nil, ErrChecksum
+	}
+
+	fields, order, err := readTLV(text[:len(text)-8])
+	if err != nil {
+		return nil, err
+	}
+	if len(order) == 0 || order[0] != idFormatIndicator || fields[idFormatIndicator] != "01" {
+		return nil, fmt.Errorf("%w: payload format indicator must come first", ErrInvalidPayload)
+	}
+
+	p := &Payload{
+		MerchantCategoryCode: fields[idMerchantCategory],
+		Currency:             fields[idCurrency],
+		Amount:               fields[idAmount],
+		CountryCode:          fields[idCountryCode],
+		MerchantName:         fields[idMerchantName],
+		MerchantCity:         fields[idMerchantCity],
+	}
+	switch fields[idInitiationMethod] {
+	case "", initiationStatic, initiationDynamic:
+	default:
+		return nil, fmt.Errorf("%w: unknown point of initiation method", ErrInvalidPayload)
+	}
+
+	// Other schemes may share the code; the first template with our identifier wins
+	// Diğer sistemler kodu paylaşabilir; bizim tanımlayıcımızı taşıyan ilk şablon kullanılır
+	found := false
+	for id := idAccountFirst; id <= idAccountLast && !found; id++ {
+		template, ok := fields[strconv.Itoa(id)]
+		if !ok {
+			continue
+		}
+		sub, _, err := readTLV(template)
+		if err != nil || sub[subGloballyUniqueID] != GloballyUniqueID {
+			continue
+		}
+
+		p.AccountType = sub[subAccountType]
+		p.AccountRef = sub[subAccountRef]
+		found = true
+	}
+	if !found {
+		return nil, ErrForeignPayload
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CRC16 is the CRC-16/CCITT-FALSE checksum EMVCo uses (polynomial 0x1021, initial value 0xFFFF)
+// CRC16 EMVCo'nun kullandığı CRC-16/CCITT-FALSE sağlamasıdır (polinom 0x1021, başlangıç değeri 0xFFFF)
+func CRC16(data string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// validate checks the fields against the lengths and formats of the EMVCo specification
+// validate alanları EMVCo belirtimindeki uzunluk ve biçimlere göre kontrol eder
+func (p *Payload) validate() error {
+	switch {
+	case p.AccountType != AccountMerchant && p.AccountType != AccountUser && p.AccountType != AccountPaymentLink:
+		return fmt.Errorf("%w: unknown account type %q", ErrInvalidPayload, p.AccountType)
+	case !accountRef(p.AccountRef):
+		return fmt.Errorf("%w: malformed account reference", ErrInvalidPayload)
+	case p.AccountType == AccountPaymentLink && !p.Dynamic():
+		return fmt.Errorf("%w: a payment link code must carry its amount", ErrInvalidPayload)
+	case !digits(p.MerchantCategoryCode, 4):
+		return fmt.Errorf("%w: merchant category code must be 4 digits", ErrInvalidPayload)
+	case !digits(p.Currency, 3):
+		return fmt.Errorf("%w: currency must be a 3 digit ISO 4217 code", ErrInvalidPayload)
+	case p.Amount != "" && (len(p.Amount) > 13 || !amountPattern.MatchString(p.Amount)):
+		return fmt.Errorf("%w: malformed amount", ErrInvalidPayload)
+	case len(p.CountryCode) != 2:
+		return fmt.Errorf("%w: country code must be 2 letters", ErrInvalidPayload)
+	case p.MerchantName == "" || len(p.MerchantName) > 25:
+		return fmt.Errorf("%w: merchant name must be 1-25 characters", ErrInvalidPayload)
+	case p.MerchantCity == "" || len(p.MerchantCity) > 15:
+		return fmt.Errorf("%w: merchant city must be 1-15 characters", ErrInvalidPayload)
+	}
+	return nil
+}
+
+// writeTLV appends one data object: two digit ID, two digit length, value
+// writeTLV bir veri nesnesi ekler: iki haneli ID, iki haneli uzunluk, değer
+func writeTLV(b *strings.Builder, id, value string) {
+	fmt.Fprintf(b, "%s%02d%s", id, len(value), value)
+}
+
+// readTLV splits text into data objects; IDs must not repeat and lengths must fit
+// readTLV metni veri nesnelerine böler; ID'ler tekrarlanmamalı ve uzunluklar sığmalıdır
+func readTLV(text string) (map[string]string, []string, error) {
+	fields := map[string]string{}
+	var order []string
+	for len(text) > 0 {
+		if len(text) < 4 || !digits(text[:2], 2) || !digits(text[2:4], 2) {
+			return nil, nil, fmt.Errorf("%w: malformed data object", ErrInvalidPayload)
+		}
+		id := text[:2]
+		length, _ := strconv.Atoi(text[2:4])
+		if len(text) < 4+length {
+			return nil, nil, fmt.Errorf("%w: data object %s is truncated", ErrInvalidPayload, id)
+		}
+		if _, ok := fields[id]; ok {
+			return nil, nil, fmt.Errorf("%w: data object %s repeats", ErrInvalidPayload, id)
+		}
+
+		fields[id] = text[4 : 4+length]
+		order = append(order, id)
+		text = text[4+length:]
+	}
+	return fields, order, nil
+}
+
+// digits reports whether s is exactly n ASCII digits
+// digits s'nin tam olarak n ASCII rakamdan oluşup oluşmadığını bildirir
+func digits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// accountRef reports whether s is a well-formed account reference: letters, digits and "_"
+// accountRef s'nin iyi biçimlenmiş bir hesap referansı olup olmadığını bildirir: harf, rakam ve "_"
+func accountRef(s string) bool {
+	if s == "" || len(s) > maxAccountRefLength {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// transliterate maps letters EMVCo text fields cannot carry to their closest ASCII letter
+// transliterate EMVCo metin alanlarının taşıyamadığı harfleri en yakın ASCII harfe çevirir
+var transliterate = strings.NewReplacer(
+	"ç", "c", "ğ", "g", "ı", "i", "ö", "o", "ş", "s", "ü", "u",
+	"Ç", "C", "Ğ", "G", "İ", "I", "Ö", "O", "Ş", "S", "Ü", "U",
+)
+
+// FitText turns a name into a value for an EMVCo text field: printable ASCII, at most max characters
+// FitText bir ismi EMVCo metin alanı için bir değere çevirir: yazdırılabilir ASCII, en fazla max karakter
+func FitText(s string, max int) string {
+	s = transliterate.Replace(strings.TrimSpace(s))
+
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 0x20 && r < 0x7F && b.Len() < max {
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
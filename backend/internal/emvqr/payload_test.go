@@ -0,0 +1,141 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// validPayload returns a dynamic payment link payload that passes validation
+// validPayload doğrulamadan geçen dinamik bir ödeme bağlantısı verisi döndürür
+func validPayload() *Payload {
+	return &Payload{
+		AccountType:          AccountPaymentLink,
+		AccountRef:           "pl_9c1e5a0b7d3f2e8a4b6c0d1f",
+		MerchantCategoryCode: "0000",
+		Currency:             "949",
+		Amount:               "12.50",
+		CountryCode:          "TR",
+		MerchantName:         "Kahve Dukkani Sisli",
+		MerchantCity:         "Istanbul",
+	}
+}
+
+// TestCRC16 checks the checksum against the CRC-16/CCITT-FALSE check values
+// TestCRC16 sağlamayı CRC-16/CCITT-FALSE kontrol değerlerine göre doğrular
+func TestCRC16(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint16
+	}{
+		{data: "", want: 0xFFFF},
+		{data: "A", want: 0xB915},
+		{data: "123456789", want: 0x29B1},
+	}
+
+	for _, tt := range tests {
+		if got := CRC16(tt.data); got != tt.want {
+			t.Errorf("CRC16(%q) = %04X, want %04X", tt.data, got, tt.want)
+		}
+	}
+}
+
+// TestEncodeParseRoundTrip encodes each kind of code and reads the same payload back
+// TestEncodeParseRoundTrip her tür kodu yazar ve aynı veriyi geri okur
+func TestEncodeParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *Payload)
+	}{
+		{name: "payment link", modify: func(p *Payload) {}},
+		{name: "static merchant", modify: func(p *Payload) {
+			p.AccountType, p.AccountRef, p.Amount = AccountMerchant, "qr_0123456789abcdef01234567", ""
+		}},
+		{name: "dynamic user", modify: func(p *Payload) {
+			p.AccountType, p.AccountRef, p.Amount = AccountUser, "qr_0123456789abcdef01234567", "0.05"
+		}},
+		{name: "whole amount", modify: func(p *Payload) { p.Amount = "100" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := validPayload()
+			tt.modify(want)
+
+			text, err := Encode(want)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			got, err := Parse(text)
+			if err != nil {
+				t.Fatalf("parse %q: %v", text, err)
+			}
+			if *got != *want {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestParseRejects checks that altered or broken checksums are refused before anything is read
+// TestParseRejects değiştirilmiş veya bozuk sağlamaların hiçbir şey okunmadan reddedildiğini kontrol eder
+func TestParseRejects(t *testing.T) {
+	text, err := Encode(validPayload())
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	body, crc := text[:len(text)-4], text[len(text)-4:]
+
+	tests := []struct {
+		name string
+		text string
+		want error
+	}{
+		{name: "altered amount", text: strings.Replace(text, "12.50", "92.50", 1), want: ErrChecksum},
+		{name: "wrong checksum", text: body + "0000", want: ErrChecksum},
+		{name: "lowercase checksum", text: body + strings.ToLower(crc), want: nil},
+		{name: "malformed checksum", text: body + "ZZZZ", want: ErrInvalidPayload},
+		{name: "missing checksum", text: text[:len(text)-8], want: ErrInvalidPayload},
+		{name: "truncated", text: text[4:], want: ErrChecksum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.text)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Parse = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestEncodeValidatesAmount checks that amounts follow the same rules as models.ParseDecimal
+// TestEncodeValidatesAmount tutarların models.ParseDecimal ile aynı kurallara uyduğunu kontrol eder
+func TestEncodeValidatesAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		valid  bool
+	}{
+		{amount: "12.50", valid: true},
+		{amount: "0.05", valid: true},
+		{amount: "7", valid: true},
+		{amount: "012.50", valid: false},
+		{amount: "00.05", valid: false},
+		{amount: "12.", valid: false},
+		{amount: "-1.00", valid: false},
+		{amount: "1e3", valid: false},
+		{amount: "12345678901.00", valid: false},
+	}
+
+	for _, tt := range tests {
+		p := validPayload()
+		p.Amount = tt.amount
+		_, err := Encode(p)
+		if tt.valid && err != nil {
+			t.Errorf("Encode(amount %q) = %v, want success", tt.amount, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidPayload) {
+			t.Errorf("Encode(amount %q) = %v, want %v", tt.amount, err, ErrInvalidPayload)
+		}
+	}
+}
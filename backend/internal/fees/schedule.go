@@ -0,0 +1,171 @@
+package fees
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Operations that can carry a fee
+// Ücret alınabilen işlemler
+const (
+	OperationWithdraw = "withdraw"
+	OperationTransfer = "transfer"
+)
+
+// defaultTable is used when no fee file is configured: withdrawals cost 0.5% (2.00-25.00 TRY),
+// transfers are free up to 1,000.00 TRY and cost 0.1% (at most 10.00 TRY) above that
+//
+// defaultTable ücret dosyası ayarlanmadığında kullanılır: çekimler %0.5 (2.00-25.00 TRY),
+// transferler 1.000,00 TRY'ye kadar ücretsiz, üstünde %0.1 (en fazla 10.00 TRY)
+var defaultTable = map[string]map[string]Rule{
+	OperationWithdraw: {
+		"TRY": {Pricing: Pricing{Bps: 50, Min: 200, Max: 2500}},
+	},
+	OperationTransfer: {
+		"TRY": {Tiers: []Tier{
+			{UpTo: 100000},
+			{Pricing: Pricing{Bps: 10, Max: 1000}},
+		}},
+	},
+}
+
+// Pricing is how a fee is computed, in minor units of the operation's currency:
+// Flat + Bps of the amount (rounded up), then raised to Min and lowered to Max (0 = unset)
+//
+// Pricing ücretin işlemin para biriminin alt birimleri cinsinden nasıl hesaplandığıdır:
+// Flat + tutarın Bps kadarı (yukarı yuvarlanır), sonra Min'e yükseltilir ve Max'a düşürülür (0 = yok)
+type Pricing struct {
+	Flat int64 `json:"flat"`
+	Bps  int64 `json:"bps"`
+	Min  int64 `json:"min"`
+	Max  int64 `json:"max"`
+}
+
+// Tier prices amounts up to UpTo (inclusive); 0 means no upper bound
+// Tier UpTo dahil olmak üzere o tutara kadar olan işlemleri fiyatlar; 0 üst sınır yok demektir
+type Tier struct {
+	UpTo int64 `json:"up_to"`
+	Pricing
+}
+
+// Rule prices one operation in one currency; the first matching tier replaces the base pricing
+// Rule bir işlemi bir para biriminde fiyatlar; eşleşen ilk kademe temel fiyatlandırmanın yerini alır
+type Rule struct {
+	Pricing
+	Tiers []Tier `json:"tiers,omitempty"`
+}
+
+// Schedule is the fee table, keyed by operation and currency. A fee file has the same layout:
+// {"withdraw": {"TRY": {"bps": 50, "min": 200}}, "transfer": {"USD": {"flat": 25}}}
+// Operations and currencies without a rule are free.
+//
+// Schedule işlem ve para birimine göre ücret tablosudur. Ücret dosyası aynı yapıdadır.
+// Kuralı olmayan işlem ve para birimleri ücretsizdir.
+type Schedule struct {
+	rules map[string]map[string]Rule
+}
+
+// LoadSchedule loads fees from a JSON file, or the built-in table if path is empty
+// LoadSchedule ücretleri JSON dosyasından, path boşsa gömülü tablodan yükler
+func LoadSchedule(path string) (*Schedule, error) {
+	table := defaultTable
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		table = nil
+		if err := json.Unmarshal(data, &table); err != nil {
+			return nil, err
+		}
+	}
+
+	rules := make(map[string]map[string]Rule, len(table))
+	for operation, currencies := range table {
+		if operation != OperationWithdraw && operation != OperationTransfer {
+			return nil, errors.New("unknown fee operation " + operation)
+		}
+		rules[operation] = make(map[string]Rule, len(currencies))
+		for code, rule := range currencies {
+			if err := rule.validate(); err != nil {
+				return nil, errors.New("invalid " + operation + " fee for " + code + ": " + err.Error())
+			}
+			rules[operation][strings.ToUpper(code)] = rule
+		}
+	}
+
+	return &Schedule{rules: rules}, nil
+}
+
+// Fee returns the fee for an amount in minor units; 0 when the schedule has no rule for it
+// Fee bir tutarın alt birim cinsinden ücretini döndürür; tabloda kuralı yoksa 0
+func (s *Schedule) Fee(operation, currency string, amount int64) int64 {
+	rule, ok := s.rules[operation][currency]
+	if !ok || amount <= 0 {
+		return 0
+	}
+	return rule.pricingFor(amount).apply(amount)
+}
+
+// pricingFor picks the tier the amount falls into, or the base pricing without tiers
+// pricingFor tutarın düştüğü kademeyi, kademe yoksa temel fiyatlandırmayı seçer
+func (r Rule) pricingFor(amount int64) Pricing {
+	for _, tier := range r.Tiers {
+		if tier.UpTo == 0 || amount <= tier.UpTo {
+			return tier.Pricing
+		}
+	}
+	if len(r.Tiers) > 0 {
+		// Amounts above the last bounded tier are free rather than guessed at
+		// Son sınırlı kademenin üstündeki tutarlar tahmin edilmek yerine ücretsizdir
+		return Pricing{}
+	}
+	return r.Pricing
+}
+
+// apply computes the fee; the percentage is split so large amounts cannot overflow
+// apply ücreti hesaplar; büyük tutarlar taşmasın diye yüzde bölünerek hesaplanır
+func (p Pricing) apply(amount int64) int64 {
+	fee := p.Flat + amount/10000*p.Bps + (amount%10000*p.Bps+9999)/10000
+
+	if p.Min > 0 && fee < p.Min {
+		fee = p.Min
+	}
+	if p.Max > 0 && fee > p.Max {
+		fee = p.Max
+	}
+	return fee
+}
+
+func (r Rule) validate() error {
+	if err := r.Pricing.validate(); err != nil {
+		return err
+	}
+	var previous int64
+	for i, tier := range r.Tiers {
+		if err := tier.Pricing.validate(); err != nil {
+			return err
+		}
+		if tier.UpTo == 0 && i != len(r.Tiers)-1 {
+			return errors.New("only the last tier may be unbounded")
+		}
+		if tier.UpTo != 0 && tier.UpTo <= previous {
+			return errors.New("tiers must be in ascending order")
+		}
+		previous = tier.UpTo
+	}
+	return nil
+}
+
+func (p Pricing) validate() error {
+	if p.Flat < 0 || p.Bps < 0 || p.Min < 0 || p.Max < 0 {
+		return errors.New("fees cannot be negative")
+	}
+	if p.Max > 0 && p.Min > p.Max {
+		return errors.New("min is above max")
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CreateEscrow endpoint
+// Alıcının parasını satıcı için emanete alır
+func CreateEscrow(escrowService *services.EscrowService, payeeService *services.PayeeService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			Seller           string         `json:"seller"`
+			Amount           models.Decimal `json:"amount"`
+			Currency         string         `json:"currency"`
+			Description      string         `json:"description"`
+			ReleaseInSeconds int64          `json:"release_in_seconds"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+		if body.ReleaseInSeconds < 0 {
+			return utils.BadRequestError(c, "Invalid release time")
+		}
+
+		seller, err := payeeService.Resolve(body.Seller)
+		if err != nil {
+			return utils.NotFoundError(c, err.Error())
+		}
+
+		result, err := escrowService.Create(userID, seller.ID, body.Currency, body.Amount, body.Description, time.Duration(body.ReleaseInSeconds)*time.Second)
+		if err != nil {
+			return escrowError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "Escrow funded",
+			"escrow":  result.Escrow,
+			"fee":     result.Fee,
+		})
+	}
+}
+
+// ListEscrows endpoint
+// Kullanıcının alıcı veya satıcı olduğu emanetleri döndürür
+func ListEscrows(escrowService *services.EscrowService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		escrows, err := escrowService.List(userID)
+		if err != nil {
+			return utils.InternalError(c, "Failed to retrieve escrows")
+		}
+
+		return c.JSON(fiber.Map{"escrows": escrows})
+	}
+}
+
+// GetEscrow endpoint
+// Kullanıcının taraf olduğu tek bir emaneti döndürür
+func GetEscrow(escrowService *services.EscrowService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		escrowID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid escrow id")
+		}
+
+		escrow, err := escrowService.Get(userID, escrowID)
+		if err != nil {
+			return escrowError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"escrow": escrow})
+	}
+}
+
+// ConfirmEscrow endpoint
+// Alıcı malı onaylar ve parayı satıcıya serbest bırakır
+func ConfirmEscrow(escrowService *services.EscrowService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		escrowID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid escrow id")
+		}
+
+		escrow, err := escrowService.Confirm(userID, escrowID)
+		if err != nil {
+			return escrowError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Escrow released",
+			"escrow":  escrow,
+		})
+	}
+}
+
+// CancelEscrow endpoint
+// Satıcı satışı iptal eder ve parayı alıcıya iade eder
+func CancelEscrow(escrowService *services.EscrowService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		escrowID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid escrow id")
+		}
+
+		var body struct {
+			Note string `json:"note"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		escrow, err := escrowService.Cancel(userID, escrowID, body.Note)
+		if err != nil {
+			return escrowError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Escrow cancelled and refunded",
+			"escrow":  escrow,
+		})
+	}
+}
+
+// DisputeEscrow endpoint
+// Taraflardan biri itiraz açar; otomatik serbest bırakma admin karar verene kadar durur
+func DisputeEscrow(escrowService *services.EscrowService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		escrowID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid escrow id")
+		}
+
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		escrow, err := escrowService.Dispute(userID, escrowID, body.Reason)
+		if err != nil {
+			return escrowError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Dispute opened",
+			"escrow":  escrow,
+		})
+	}
+}
+
+// AdminListEscrows endpoint
+// Verilen durumdaki emanetleri döndürür (varsayılan: itirazlı olanlar)
+func AdminListEscrows(escrowService *services.EscrowService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		escrows, err := escrowService.AdminList(c.Query("status"))
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{"escrows": escrows})
+	}
+}
+
+// AdminReleaseEscrow endpoint
+// Açık bir emaneti admin kararıyla satıcıya serbest bırakır
+func AdminReleaseEscrow(escrowService *services.EscrowService) fiber.Handler {
+	return adminResolveEscrow(escrowService, true)
+}
+
+// AdminRefundEscrow endpoint
+// Açık bir emaneti admin kararıyla alıcıya iade eder
+func AdminRefundEscrow(escrowService *services.EscrowService) fiber.Handler {
+	return adminResolveEscrow(escrowService, false)
+}
+
+// adminResolveEscrow releases or refunds an escrow on behalf of the calling admin
+// adminResolveEscrow çağıran admin adına bir emaneti serbest bırakır veya iade eder
+func adminResolveEscrow(escrowService *services.EscrowService, release bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		adminID := uint(c.Locals("user_id").(float64))
+
+		escrowID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid escrow id")
+		}
+
+		var body struct {
+			Note string `json:"note"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		escrow, err := escrowService.AdminResolve(adminID, escrowID, release, body.Note)
+		if err != nil {
+			return escrowError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Escrow " + escrow.Status,
+			"escrow":  escrow,
+		})
+	}
+}
+
+// escrowError maps service errors to HTTP responses
+// escrowError servis hatalarını HTTP cevaplarına çevirir
+func escrowError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.NotFoundError(c, "Escrow not found")
+	case errors.Is(err, services.ErrEscrowParty):
+		return utils.ForbiddenError(c, err.Error())
+	case errors.Is(err, repositories.ErrEscrowChanged):
+		return utils.ConflictError(c, err.Error())
+	case errors.Is(err, services.ErrLimitExceeded):
+		return utils.UnprocessableError(c, err.Error())
+	}
+	return utils.BadRequestError(c, err.Error())
+}
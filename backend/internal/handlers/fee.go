@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetFeeQuote endpoint
+// Bir çekim veya transferin ücretini işlem yapılmadan önce gösterir
+func GetFeeQuote(feeService *services.FeeService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		amount, err := models.ParseDecimal(c.Query("amount"))
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		fee, err := feeService.Preview(c.Query("operation"), c.Query("currency"), amount)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{"fee": fee})
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateQuote endpoint
+// Belirli bir süre için sabitlenmiş döviz kuru teklifi oluşturur
+func CreateQuote(fxService *services.FxService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return utils.BadRequestError(c, "Invalid request body")
+		}
+
+		quote, err := fxService.CreateQuote(userID, body.From, body.To)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{"quote": quote})
+	}
+}
+
+// Convert endpoint
+// Kullanıcının kendi cüzdanları arasında teklif kuruyla çevrim yapar
+func Convert(fxService *services.FxService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			QuoteID uint           `json:"quote_id"`
+			Amount  models.Decimal `json:"amount"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		conversion, err := fxService.Convert(userID, body.QuoteID, body.Amount)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{
+			"message":    "Conversion successful",
+			"conversion": conversion,
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"errors"
+
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CreateGroup endpoint
+// Kullanıcıyı ilk üyesi yaparak yeni bir harcama grubu açar
+func CreateGroup(groupService *services.GroupService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var input services.GroupInput
+		if err := c.BodyParser(&input); err != nil {
+			return invalidBody(c, err)
+		}
+
+		group, err := groupService.Create(userID, input)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(group)
+	}
+}
+
+// ListGroups endpoint
+// Kullanıcının üyesi olduğu harcama gruplarını döndürür
+func ListGroups(groupService *services.GroupService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		groups, err := groupService.List(userID)
+		if err != nil {
+			return utils.InternalError(c, "Failed to retrieve groups")
+		}
+
+		return c.JSON(fiber.Map{"groups": groups})
+	}
+}
+
+// GetGroup endpoint
+// Bir grubu üyeleri, net bakiyeleri ve hesaplaşma planıyla döndürür
+func GetGroup(groupService *services.GroupService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		groupID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid group id")
+		}
+
+		group, err := groupService.Get(userID, groupID)
+		if err != nil {
+			return groupError(c, err)
+		}
+
+		return c.JSON(group)
+	}
+}
+
+// AddGroupMember endpoint
+// Bir kullanıcıyı e-posta, telefon veya @handle ile gruba ekler
+func AddGroupMember(groupService *services.GroupService, payeeService *services.PayeeService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		groupID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid group id")
+		}
+
+		var body struct {
+			User string `json:"user"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		user, err := payeeService.Resolve(body.User)
+		if err != nil {
+			return utils.NotFoundError(c, err.Error())
+		}
+
+		member, err := groupService.AddMember(userID, groupID, user.ID)
+		if err != nil {
+			return groupError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "Member added",
+			"member":  member,
+		})
+	}
+}
+
+// RemoveGroupMember endpoint
+// Hesaplaşmış bir üyeyi gruptan çıkarır; üyeler kendilerini çıkararak ayrılabilir
+func RemoveGroupMember(groupService *services.GroupService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		groupID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid group id")
+		}
+		memberID, err := idParam(c, "user_id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid member id")
+		}
+
+		if err := groupService.RemoveMember(userID, groupID, memberID); err != nil {
+			return groupError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"message": "Member removed"})
+	}
+}
+
+// AddGroupExpense endpoint
+// Bir üyenin ödediği harcamayı kaydeder ve eşit, paylı veya tam tutarlarla böler
+func AddGroupExpense(groupService *services.GroupService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		groupID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid group id")
+		}
+
+		var input services.ExpenseInput
+		if err := c.BodyParser(&input); err != nil {
+			return invalidBody(c, err)
+		}
+
+		expense, err := groupService.AddExpense(userID, groupID, input)
+		if err != nil {
+			return groupError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "Expense added",
+			"expense": expense,
+		})
+	}
+}
+
+// ListGroupExpenses endpoint
+// Grubun harcamalarını paylarıyla birlikte yeniden eskiye döndürür
+func ListGroupExpenses(groupService *services.GroupService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		groupID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid group id")
+		}
+
+		expenses, err := groupService.Expenses(userID, groupID)
+		if err != nil {
+			return groupError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"expenses": expenses})
+	}
+}
+
+// DeleteGroupExpense endpoint
+// Bir harcamayı siler; sadece onu giren veya ödeyen üye
+func DeleteGroupExpense(groupService *services.GroupService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		groupID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid group id")
+		}
+		expenseID, err := idParam(c, "expense_id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid expense id")
+		}
+
+		if err := groupService.DeleteExpense(userID, groupID, expenseID); err != nil {
+			return groupError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"message": "Expense deleted"})
+	}
+}
+
+// ListGroupSettlements endpoint
+// Grupta yapılan hesaplaşma transferlerini döndürür
+func ListGroupSettlements(groupService *services.GroupService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		groupID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid group id")
+		}
+
+		settlements, err := groupService.Settlements(userID, groupID)
+		if err != nil {
+			return groupError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"settlements": settlements})
+	}
+}
+
+// SettleUpGroup endpoint
+// Hesaplaşma planında kullanıcının borçlu olduğu transferleri cüzdanından öder
+func SettleUpGroup(groupService *services.GroupService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		groupID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid group id")
+		}
+
+		result, err := groupService.SettleUp(userID, groupID)
+		if err != nil {
+			return groupError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message":     "Settled up",
+			"settlements": result.Settlements,
+			"fees":        result.Fees,
+		})
+	}
+}
+
+// groupError maps service errors to HTTP responses
+// groupError servis hatalarını HTTP cevaplarına çevirir
+func groupError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.NotFoundError(c, "Group not found")
+	case errors.Is(err, services.ErrLimitExceeded):
+		return utils.UnprocessableError(c, err.Error())
+	case errors.Is(err, services.ErrNothingToSettle):
+		return utils.ConflictError(c, err.Error())
+	}
+	return utils.BadRequestError(c, err.Error())
+}
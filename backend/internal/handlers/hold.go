@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// AuthorizeHold endpoint
+// Alıcı için kullanıcının cüzdanında provizyon alır
+func AuthorizeHold(holdService *services.HoldService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			PayeeID          uint           `json:"payee_id"`
+			Amount           models.Decimal `json:"amount"`
+			Currency         string         `json:"currency"`
+			ExpiresInSeconds int64          `json:"expires_in_seconds"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+		if body.ExpiresInSeconds < 0 {
+			return utils.BadRequestError(c, "Invalid expiry")
+		}
+
+		hold, err := holdService.Authorize(userID, body.PayeeID, body.Currency, body.Amount, time.Duration(body.ExpiresInSeconds)*time.Second)
+		if err != nil {
+			if errors.Is(err, services.ErrLimitExceeded) {
+				return utils.UnprocessableError(c, err.Error())
+			}
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Hold authorized",
+			"hold":    hold,
+		})
+	}
+}
+
+// CaptureHold endpoint
+// Alıcı provizyonu tamamen veya kısmen tahsil eder
+func CaptureHold(holdService *services.HoldService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		holdID, err := c.ParamsInt("id")
+		if err != nil || holdID <= 0 {
+			return utils.BadRequestError(c, "Invalid hold id")
+		}
+
+		var body struct {
+			Amount models.Decimal `json:"amount"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		hold, err := holdService.Capture(userID, uint(holdID), body.Amount)
+		if err != nil {
+			return holdError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Hold captured",
+			"hold":    hold,
+		})
+	}
+}
+
+// VoidHold endpoint
+// Provizyonu iptal eder ve parayı ödeyene geri verir
+func VoidHold(holdService *services.HoldService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		holdID, err := c.ParamsInt("id")
+		if err != nil || holdID <= 0 {
+			return utils.BadRequestError(c, "Invalid hold id")
+		}
+
+		hold, err := holdService.Void(userID, uint(holdID))
+		if err != nil {
+			return holdError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Hold voided",
+			"hold":    hold,
+		})
+	}
+}
+
+// GetHolds returns holds the logged user placed or may capture
+// GetHolds giriş yapan kullanıcının verdiği veya tahsil edebileceği provizyonları döndürür
+func GetHolds(holdService *services.HoldService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		holds, err := holdService.GetHolds(userID)
+		if err != nil {
+			return utils.InternalError(c, "Failed to retrieve holds")
+		}
+
+		return c.JSON(fiber.Map{"holds": holds})
+	}
+}
+
+// holdError maps service errors to HTTP responses
+// holdError servis hatalarını HTTP cevaplarına çevirir
+func holdError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.NotFoundError(c, "Hold not found")
+	}
+	if errors.Is(err, services.ErrLimitExceeded) {
+		return utils.UnprocessableError(c, err.Error())
+	}
+	return utils.BadRequestError(c, err.Error())
+}
@@ -0,0 +1,471 @@
+package handlers
+
+import (
+	"errors"
+
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CreateJointWallet endpoint
+// Kullanıcıyı sahibi yaparak yeni bir ortak cüzdan açar
+func CreateJointWallet(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var input services.JointWalletInput
+		if err := c.BodyParser(&input); err != nil {
+			return invalidBody(c, err)
+		}
+
+		joint, err := jointService.Create(userID, input)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(joint)
+	}
+}
+
+// ListJointWallets endpoint
+// Kullanıcının üyesi olduğu veya davet edildiği ortak cüzdanları döndürür
+func ListJointWallets(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		joints, err := jointService.List(userID)
+		if err != nil {
+			return utils.InternalError(c, "Failed to retrieve joint wallets")
+		}
+
+		return c.JSON(fiber.Map{"joint_wallets": joints})
+	}
+}
+
+// GetJointWallet endpoint
+// Bir ortak cüzdanı bakiyesi ve üyeleriyle döndürür
+func GetJointWallet(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+
+		joint, err := jointService.Get(userID, jointID)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		return c.JSON(joint)
+	}
+}
+
+// SetJointPolicy endpoint
+// Hangi çekimlerin kaç onay gerektirdiğini belirler; sadece sahipler
+func SetJointPolicy(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+
+		var input services.JointPolicyInput
+		if err := c.BodyParser(&input); err != nil {
+			return invalidBody(c, err)
+		}
+
+		joint, err := jointService.SetPolicy(userID, jointID, input)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message":      "Approval policy updated",
+			"joint_wallet": joint,
+		})
+	}
+}
+
+// InviteJointMember endpoint
+// Bir kullanıcıyı e-posta, telefon veya @handle ile ortak cüzdana davet eder; sadece sahipler
+func InviteJointMember(jointService *services.JointWalletService, payeeService *services.PayeeService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+
+		var body struct {
+			To string `json:"to"`
+			services.JointMemberInput
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		invitee, err := payeeService.Resolve(body.To)
+		if err != nil {
+			return utils.NotFoundError(c, err.Error())
+		}
+
+		member, err := jointService.Invite(userID, jointID, invitee.ID, body.JointMemberInput)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "Invitation sent",
+			"member":  member,
+		})
+	}
+}
+
+// AcceptJointInvitation endpoint
+// Kullanıcının ortak cüzdan davetini kabul eder
+func AcceptJointInvitation(jointService *services.JointWalletService) fiber.Handler {
+	return respondJointInvitation(jointService, true, "Invitation accepted")
+}
+
+// DeclineJointInvitation endpoint
+// Kullanıcının ortak cüzdan davetini reddeder
+func DeclineJointInvitation(jointService *services.JointWalletService) fiber.Handler {
+	return respondJointInvitation(jointService, false, "Invitation declined")
+}
+
+// UpdateJointMember endpoint
+// Bir üyenin rolünü ve günlük limitini değiştirir; sadece sahipler
+func UpdateJointMember(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+		memberID, err := idParam(c, "user_id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid member id")
+		}
+
+		var input services.JointMemberInput
+		if err := c.BodyParser(&input); err != nil {
+			return invalidBody(c, err)
+		}
+
+		member, err := jointService.UpdateMember(userID, jointID, memberID, input)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Member updated",
+			"member":  member,
+		})
+	}
+}
+
+// RemoveJointMember endpoint
+// Bir üyeyi ortak cüzdandan çıkarır; üyeler kendilerini çıkararak ayrılabilir
+func RemoveJointMember(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+		memberID, err := idParam(c, "user_id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid member id")
+		}
+
+		if err := jointService.RemoveMember(userID, jointID, memberID); err != nil {
+			return jointError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"message": "Member removed"})
+	}
+}
+
+// ContributeToJointWallet endpoint
+// Üyenin kendi cüzdanından ortak cüzdana para aktarır
+func ContributeToJointWallet(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+
+		var body struct {
+			Amount models.Decimal `json:"amount"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		joint, err := jointService.Contribute(userID, jointID, body.Amount)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message":   "Contribution successful",
+			"balance":   joint.Balance,
+			"available": joint.Available,
+		})
+	}
+}
+
+// WithdrawFromJointWallet endpoint
+// Ortak cüzdandan para çeker; eşiğin üzerindeki çekimler onay bekler (202)
+func WithdrawFromJointWallet(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+
+		var body struct {
+			Amount models.Decimal `json:"amount"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		result, err := jointService.Withdraw(userID, jointID, body.Amount)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		if result.Withdrawal != nil {
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+				"message":    "Withdrawal waiting for approvals",
+				"withdrawal": result.Withdrawal,
+			})
+		}
+		return c.JSON(fiber.Map{
+			"message": "Withdraw successful",
+			"fee":     result.Fee,
+		})
+	}
+}
+
+// TransferFromJointWallet endpoint
+// Ortak cüzdandan e-posta, telefon veya @handle ile belirtilen kullanıcıya ödeme yapar; eşiğin üzerindeki transferler onay bekler (202)
+func TransferFromJointWallet(jointService *services.JointWalletService, payeeService *services.PayeeService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+
+		var body struct {
+			To     string         `json:"to"`
+			Amount models.Decimal `json:"amount"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		payee, err := payeeService.Resolve(body.To)
+		if err != nil {
+			return utils.NotFoundError(c, err.Error())
+		}
+
+		result, err := jointService.Transfer(userID, jointID, payee.ID, body.Amount)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		if result.Withdrawal != nil {
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+				"message":    "Transfer waiting for approvals",
+				"withdrawal": result.Withdrawal,
+			})
+		}
+		return c.JSON(fiber.Map{
+			"message": "Transfer successful",
+			"fee":     result.Fee,
+		})
+	}
+}
+
+// ListJointWithdrawals endpoint
+// Ortak cüzdanın onay gerektiren çekimlerini listeler, önce bekleyenler
+func ListJointWithdrawals(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+
+		withdrawals, err := jointService.Withdrawals(userID, jointID)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"withdrawals": withdrawals})
+	}
+}
+
+// ApproveJointWithdrawal endpoint
+// Bekleyen bir çekimi onaylar; son gereken onay çekimi gerçekleştirir
+func ApproveJointWithdrawal(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+		withdrawalID, err := idParam(c, "withdrawal_id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid withdrawal id")
+		}
+
+		result, err := jointService.Approve(userID, jointID, withdrawalID)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		message := "Approval recorded"
+		if result.Withdrawal.Status == models.JointWithdrawalExecuted {
+			message = "Withdrawal approved and executed"
+		}
+		return c.JSON(fiber.Map{
+			"message":    message,
+			"withdrawal": result.Withdrawal,
+			"fee":        result.Fee,
+		})
+	}
+}
+
+// RejectJointWithdrawal endpoint
+// Bekleyen bir çekimi reddeder
+func RejectJointWithdrawal(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+		withdrawalID, err := idParam(c, "withdrawal_id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid withdrawal id")
+		}
+
+		withdrawal, err := jointService.Reject(userID, jointID, withdrawalID)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message":    "Withdrawal rejected",
+			"withdrawal": withdrawal,
+		})
+	}
+}
+
+// GetJointWalletHistory returns one page of a joint wallet's history, newest first;
+// paging works like /wallet/history (?limit= and ?cursor=)
+//
+// GetJointWalletHistory bir ortak cüzdanın geçmişinin bir sayfasını yeniden eskiye döndürür;
+// sayfalama /wallet/history gibi çalışır (?limit= ve ?cursor=)
+func GetJointWalletHistory(jointService *services.JointWalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+
+		limit := c.QueryInt("limit", services.DefaultHistoryLimit)
+		if limit <= 0 {
+			return utils.BadRequestError(c, "limit must be positive")
+		}
+
+		page, err := jointService.History(userID, jointID, c.Query("cursor"), limit)
+		if errors.Is(err, services.ErrInvalidCursor) {
+			return utils.BadRequestError(c, "Invalid cursor")
+		}
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"joint_wallet_id": jointID,
+			"transactions":    page.Transactions,
+			"next_cursor":     page.NextCursor,
+		})
+	}
+}
+
+// respondJointInvitation builds the accept and decline handlers, which only differ in the answer
+// respondJointInvitation sadece cevapta farklılaşan kabul ve ret handler'larını oluşturur
+func respondJointInvitation(jointService *services.JointWalletService, accept bool, message string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		jointID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid joint wallet id")
+		}
+
+		member, err := jointService.Respond(userID, jointID, accept)
+		if err != nil {
+			return jointError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": message,
+			"member":  member,
+		})
+	}
+}
+
+// idParam reads a positive numeric route parameter
+// idParam pozitif sayısal bir route parametresi okur
+func idParam(c *fiber.Ctx, name string) (uint, error) {
+	id, err := c.ParamsInt(name)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid " + name)
+	}
+	return uint(id), nil
+}
+
+// jointError maps service errors to HTTP responses
+// jointError servis hatalarını HTTP cevaplarına çevirir
+func jointError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.NotFoundError(c, "Joint wallet not found")
+	case errors.Is(err, services.ErrJointRole):
+		return utils.ForbiddenError(c, err.Error())
+	case errors.Is(err, services.ErrLimitExceeded):
+		return utils.UnprocessableError(c, err.Error())
+	case errors.Is(err, repositories.ErrWithdrawalNotPending):
+		return utils.ConflictError(c, err.Error())
+	}
+	return utils.BadRequestError(c, err.Error())
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetLimits endpoint
+// Kullanıcının çekim ve transfer sınırlarını ve kalan hakkını döndürür
+func GetLimits(limitService *services.LimitService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		limits, err := limitService.Status(userID)
+		if err != nil {
+			return utils.InternalError(c, "Limits could not be computed")
+		}
+
+		return c.JSON(fiber.Map{"limits": limits})
+	}
+}
+
+// AdminGetLimits endpoint
+// Admin bir kullanıcının sınırlarını ve kalan hakkını görür
+func AdminGetLimits(limitService *services.LimitService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, err := c.ParamsInt("id")
+		if err != nil || userID <= 0 {
+			return utils.BadRequestError(c, "Invalid user id")
+		}
+
+		limits, err := limitService.Status(uint(userID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.NotFoundError(c, "User not found")
+			}
+			return utils.InternalError(c, "Limits could not be computed")
+		}
+
+		return c.JSON(fiber.Map{"limits": limits})
+	}
+}
+
+// AdminSetLimits endpoint
+// Admin bir kullanıcı için bir işlemin sınırlarını değiştirir; null alan varsayılanı korur, 0 sınırı kaldırır
+func AdminSetLimits(limitService *services.LimitService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		adminID := uint(c.Locals("user_id").(float64))
+
+		userID, err := c.ParamsInt("id")
+		if err != nil || userID <= 0 {
+			return utils.BadRequestError(c, "Invalid user id")
+		}
+
+		var body struct {
+			PerTransaction *models.Decimal `json:"per_transaction"`
+			Daily          *models.Decimal `json:"daily"`
+			Monthly        *models.Decimal `json:"monthly"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		if _, err := limitService.SetOverride(adminID, uint(userID), c.Params("operation"), body.PerTransaction, body.Daily, body.Monthly); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.NotFoundError(c, "User not found")
+			}
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		// Answer with the resulting limits, so amounts come back as decimals in the limit currency
+		// Sonuç sınırlarıyla cevap ver, böylece tutarlar limit para biriminde ondalık olarak döner
+		limits, err := limitService.Status(uint(userID))
+		if err != nil {
+			return utils.InternalError(c, "Failed to load limits")
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Limits updated",
+			"limits":  limits,
+		})
+	}
+}
+
+// AdminClearLimits endpoint
+// Admin bir kullanıcının override'ını siler, varsayılan sınırlar tekrar geçerli olur
+func AdminClearLimits(limitService *services.LimitService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		adminID := uint(c.Locals("user_id").(float64))
+
+		userID, err := c.ParamsInt("id")
+		if err != nil || userID <= 0 {
+			return utils.BadRequestError(c, "Invalid user id")
+		}
+
+		if err := limitService.ClearOverride(adminID, uint(userID), c.Params("operation")); err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{"message": "Limits reset to defaults"})
+	}
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// merchantBody is the business profile sent when registering or updating a merchant
+// merchantBody işletme açılırken veya güncellenirken gönderilen işletme profilidir
+type merchantBody struct {
+	BusinessName       string `json:"business_name"`
+	Website            string `json:"website"`
+	SupportEmail       string `json:"support_email"`
+	SettlementCurrency string `json:"settlement_currency"`
+}
+
+func (b merchantBody) profile() services.MerchantProfile {
+	return services.MerchantProfile{
+		BusinessName:       b.BusinessName,
+		Website:            b.Website,
+		SupportEmail:       b.SupportEmail,
+		SettlementCurrency: b.SettlementCurrency,
+	}
+}
+
+// RegisterMerchant endpoint
+// Kullanıcıyı bir işletme hesabına çevirir ve tahsilat cüzdanını açar
+func RegisterMerchant(merchantService *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body merchantBody
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		merchant, err := merchantService.Register(userID, body.profile())
+		if err != nil {
+			return merchantError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"merchant": merchant})
+	}
+}
+
+// GetMerchant endpoint
+// Kullanıcının işletme hesabını döndürür
+func GetMerchant(merchantService *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		merchant, err := merchantService.Get(userID)
+		if err != nil {
+			return merchantError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"merchant": merchant})
+	}
+}
+
+// UpdateMerchant endpoint
+// İşletme profilini değiştirir
+func UpdateMerchant(merchantService *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body merchantBody
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		merchant, err := merchantService.Update(userID, body.profile())
+		if err != nil {
+			return merchantError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"merchant": merchant})
+	}
+}
+
+// CreatePaymentLink endpoint
+// Sabit tutarlı, tek veya çok kullanımlık bir ödeme bağlantısı oluşturur
+func CreatePaymentLink(merchantService *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			Amount           models.Decimal `json:"amount"`
+			Currency         string         `json:"currency"`
+			Description      string         `json:"description"`
+			ExpiresInSeconds int64          `json:"expires_in_seconds"`
+			MultiUse         bool           `json:"multi_use"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+		if body.ExpiresInSeconds < 0 {
+			return utils.BadRequestError(c, "Invalid expiry")
+		}
+
+		link, err := merchantService.CreateLink(userID, body.Currency, body.Amount, body.Description, time.Duration(body.ExpiresInSeconds)*time.Second, body.MultiUse)
+		if err != nil {
+			return merchantError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"link":     link,
+			"pay_path": "/pay/" + link.Code,
+		})
+	}
+}
+
+// ListPaymentLinks endpoint
+// İşletmenin ödeme bağlantılarını döndürür; ?status= tek bir duruma sınırlar
+func ListPaymentLinks(merchantService *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		links, err := merchantService.ListLinks(userID, c.Query("status"))
+		if err != nil {
+			return merchantError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"links": links})
+	}
+}
+
+// GetPaymentLink endpoint
+// İşletmenin tek bir ödeme bağlantısını durumu ve ödemeleriyle döndürür
+func GetPaymentLink(merchantService *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		linkID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid payment link id")
+		}
+
+		view, err := merchantService.GetLink(userID, linkID)
+		if err != nil {
+			return merchantError(c, err)
+		}
+
+		return c.JSON(view)
+	}
+}
+
+// CancelPaymentLink endpoint
+// Aktif bir ödeme bağlantısının daha fazla ödeme almasını durdurur
+func CancelPaymentLink(merchantService *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		linkID, err := idParam(c, "id")
+		if err != nil {
+			return utils.BadRequestError(c, "Invalid payment link id")
+		}
+
+		link, err := merchantService.CancelLink(userID, linkID)
+		if err != nil {
+			return merchantError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Payment link cancelled",
+			"link":    link,
+		})
+	}
+}
+
+// GetCheckout endpoint (public)
+// Bir ödeme bağlantısını ödemeden önce gösterir; giriş gerektirmez
+func GetCheckout(merchantService *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		checkout, err := merchantService.Checkout(c.Params("code"))
+		if err != nil {
+			return merchantError(c, err)
+		}
+
+		return c.JSON(checkout)
+	}
+}
+
+// PayPaymentLink endpoint
+// Bağlantının tutarını ödeyenden işletmeye transfer ederek bağlantıyı öder
+func PayPaymentLink(merchantService *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		result, err := merchantService.Pay(userID, c.Params("code"))
+		if err != nil {
+			return merchantError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Payment completed",
+			"payment": result.Payment,
+			"link":    result.Link,
+			"fee":     result.Fee,
+		})
+	}
+}
+
+// merchantError maps service errors to HTTP responses
+// merchantError servis hatalarını HTTP cevaplarına çevirir
+func merchantError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.NotFoundError(c, "Payment link not found")
+	case errors.Is(err, services.ErrNotMerchant):
+		return utils.NotFoundError(c, err.Error())
+	case errors.Is(err, services.ErrMerchantExists), errors.Is(err, repositories.ErrLinkNotPayable):
+		return utils.ConflictError(c, err.Error())
+	case errors.Is(err, services.ErrLimitExceeded):
+		return utils.UnprocessableError(c, err.Error())
+	}
+	return utils.BadRequestError(c, err.Error())
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CreateMoneyRequest endpoint
+// Başka bir kullanıcıdan not ile birlikte para ister
+func CreateMoneyRequest(requestService *services.MoneyRequestService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			PayerID          uint           `json:"payer_id"`
+			Amount           models.Decimal `json:"amount"`
+			Currency         string         `json:"currency"`
+			Note             string         `json:"note"`
+			ExpiresInSeconds int64          `json:"expires_in_seconds"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+		if body.ExpiresInSeconds < 0 {
+			return utils.BadRequestError(c, "Invalid expiry")
+		}
+
+		request, err := requestService.Create(userID, body.PayerID, body.Currency, body.Amount, body.Note, time.Duration(body.ExpiresInSeconds)*time.Second)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"request": request})
+	}
+}
+
+// ListMoneyRequests returns incoming (to pay) and outgoing (sent) requests;
+// ?direction=incoming or ?direction=outgoing limits the list to one side
+//
+// ListMoneyRequests gelen (ödenecek) ve giden (gönderilmiş) istekleri döndürür;
+// ?direction=incoming veya ?direction=outgoing listeyi tek tarafa sınırlar
+func ListMoneyRequests(requestService *services.MoneyRequestService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+		direction := c.Query("direction")
+
+		if direction != "" && direction != "incoming" && direction != "outgoing" {
+			return utils.BadRequestError(c, "direction must be incoming or outgoing")
+		}
+
+		response := fiber.Map{}
+		if direction != "outgoing" {
+			incoming, err := requestService.ListIncoming(userID)
+			if err != nil {
+				return utils.InternalError(c, "Failed to retrieve money requests")
+			}
+			response["incoming"] = incoming
+		}
+		if direction != "incoming" {
+			outgoing, err := requestService.ListOutgoing(userID)
+			if err != nil {
+				return utils.InternalError(c, "Failed to retrieve money requests")
+			}
+			response["outgoing"] = outgoing
+		}
+
+		return c.JSON(response)
+	}
+}
+
+// AcceptMoneyRequest endpoint
+// Ödeyen isteği kabul eder ve transfer yapılır
+func AcceptMoneyRequest(requestService *services.MoneyRequestService) fiber.Handler {
+	return respondMoneyRequest(requestService.Accept, "Money request paid")
+}
+
+// DeclineMoneyRequest endpoint
+// Ödeyen isteği reddeder
+func DeclineMoneyRequest(requestService *services.MoneyRequestService) fiber.Handler {
+	return respondMoneyRequest(requestService.Decline, "Money request declined")
+}
+
+// CancelMoneyRequest endpoint
+// İsteyen isteğini geri çeker
+func CancelMoneyRequest(requestService *services.MoneyRequestService) fiber.Handler {
+	return respondMoneyRequest(requestService.Cancel, "Money request cancelled")
+}
+
+// respondMoneyRequest builds a handler that answers the request in the :id param
+// respondMoneyRequest :id parametresindeki isteği cevaplayan bir handler oluşturur
+func respondMoneyRequest(respond func(userID, requestID uint) (*models.MoneyRequest, error), message string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		requestID, err := c.ParamsInt("id")
+		if err != nil || requestID <= 0 {
+			return utils.BadRequestError(c, "Invalid request id")
+		}
+
+		request, err := respond(userID, uint(requestID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.NotFoundError(c, "Money request not found")
+			}
+			if errors.Is(err, services.ErrLimitExceeded) {
+				return utils.UnprocessableError(c, err.Error())
+			}
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{
+			"message": message,
+			"request": request,
+		})
+	}
+}
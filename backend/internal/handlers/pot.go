@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CreatePot endpoint
+// Kullanıcının cüzdanı altında hedefli veya hedefsiz bir kumbara açar
+func CreatePot(potService *services.PotService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var input services.PotInput
+		if err := c.BodyParser(&input); err != nil {
+			return invalidBody(c, err)
+		}
+
+		pot, err := potService.Create(userID, input)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"pot": pot})
+	}
+}
+
+// ListPots endpoint
+// Giriş yapan kullanıcının kumbaralarını döndürür
+func ListPots(potService *services.PotService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		pots, err := potService.List(userID)
+		if err != nil {
+			return utils.InternalError(c, "Failed to retrieve pots")
+		}
+
+		return c.JSON(fiber.Map{"pots": pots})
+	}
+}
+
+// GetPot endpoint
+// Kullanıcının kumbaralarından birini döndürür
+func GetPot(potService *services.PotService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		potID, err := c.ParamsInt("id")
+		if err != nil || potID <= 0 {
+			return utils.BadRequestError(c, "Invalid pot id")
+		}
+
+		pot, err := potService.Get(userID, uint(potID))
+		if err != nil {
+			return potError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"pot": pot})
+	}
+}
+
+// MoveIntoPot endpoint
+// Cüzdandaki parayı kumbaraya ayırır
+func MoveIntoPot(potService *services.PotService) fiber.Handler {
+	return movePot(potService.MoveIn, "Money moved into pot")
+}
+
+// MoveOutOfPot endpoint
+// Kumbaradaki parayı cüzdana geri getirir
+func MoveOutOfPot(potService *services.PotService) fiber.Handler {
+	return movePot(potService.MoveOut, "Money moved out of pot")
+}
+
+// ClosePot endpoint
+// Kumbarada kalanı cüzdana aktarır ve kumbarayı kapatır
+func ClosePot(potService *services.PotService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		potID, err := c.ParamsInt("id")
+		if err != nil || potID <= 0 {
+			return utils.BadRequestError(c, "Invalid pot id")
+		}
+
+		pot, err := potService.Close(userID, uint(potID))
+		if err != nil {
+			return potError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Pot closed",
+			"pot":     pot,
+		})
+	}
+}
+
+// movePot builds the move-in and move-out handlers, which only differ in the service call
+// movePot sadece servis çağrısında farklılaşan giriş ve çıkış handler'larını oluşturur
+func movePot(move func(userID, potID uint, amount models.Decimal) (*models.Pot, error), message string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		potID, err := c.ParamsInt("id")
+		if err != nil || potID <= 0 {
+			return utils.BadRequestError(c, "Invalid pot id")
+		}
+
+		var body struct {
+			Amount models.Decimal `json:"amount"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		pot, err := move(userID, uint(potID), body.Amount)
+		if err != nil {
+			return potError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": message,
+			"pot":     pot,
+		})
+	}
+}
+
+// potError maps service errors to HTTP responses
+// potError servis hatalarını HTTP cevaplarına çevirir
+func potError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.NotFoundError(c, "Pot not found")
+	}
+	return utils.BadRequestError(c, err.Error())
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetHandle endpoint
+// Kullanıcının başkalarının para gönderebileceği @handle adını belirler
+func SetHandle(profileService *services.ProfileService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			Handle string `json:"handle"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return utils.BadRequestError(c, "Invalid request body")
+		}
+
+		user, err := profileService.SetHandle(userID, body.Handle)
+		if errors.Is(err, services.ErrHandleTaken) {
+			return utils.ConflictError(c, err.Error())
+		}
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{"profile": user})
+	}
+}
+
+// StartPhoneVerification endpoint
+// Telefon numarasına doğrulama kodu gönderir
+func StartPhoneVerification(profileService *services.ProfileService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			Phone string `json:"phone"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return utils.BadRequestError(c, "Invalid request body")
+		}
+
+		if err := profileService.StartPhoneVerification(userID, body.Phone); err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Verification code sent"})
+	}
+}
+
+// VerifyPhone endpoint
+// Gönderilen kod ile telefon numarasını doğrular
+func VerifyPhone(profileService *services.ProfileService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			Code string `json:"code"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return utils.BadRequestError(c, "Invalid request body")
+		}
+
+		user, err := profileService.VerifyPhone(userID, body.Code)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{"profile": user})
+	}
+}
+
+// LookupPayee returns the masked name behind ?to= (email, phone or @handle),
+// so the sender can confirm the recipient before sending
+//
+// LookupPayee ?to= (e-posta, telefon veya @handle) arkasındaki maskelenmiş adı döndürür,
+// böylece gönderici para göndermeden önce alıcıyı onaylayabilir
+func LookupPayee(payeeService *services.PayeeService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		to := c.Query("to")
+		if to == "" {
+			return utils.BadRequestError(c, "to is required")
+		}
+
+		payee, err := payeeService.Lookup(to)
+		if err != nil {
+			return utils.NotFoundError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{"payee": payee})
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+
+	"mini-pay-backend/internal/emvqr"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetQRCode endpoint
+// Kullanıcıya veya işletmesine ödeme için EMVCo QR kodu döndürür; ?format=png sadece resmi döndürür
+func GetQRCode(qrService *services.QRService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var amount models.Decimal
+		if value := c.Query("amount"); value != "" {
+			var err error
+			if amount, err = models.ParseDecimal(value); err != nil {
+				return utils.BadRequestError(c, err.Error())
+			}
+		}
+
+		code, err := qrService.Generate(userID, c.Query("account"), c.Query("currency"), amount)
+		if err != nil {
+			return qrError(c, err)
+		}
+
+		if c.Query("format") == "png" {
+			c.Set(fiber.HeaderContentType, "image/png")
+			return c.Send(code.PNG)
+		}
+		return c.JSON(code)
+	}
+}
+
+// CreateQRCheckout endpoint
+// İşletme için tutarı sabit, tek kullanımlık bir ödeme bağlantısına bağlı dinamik QR kodu oluşturur
+func CreateQRCheckout(qrService *services.QRService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			Amount   models.Decimal `json:"amount"`
+			Currency string         `json:"currency"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		code, err := qrService.Checkout(userID, body.Currency, body.Amount)
+		if err != nil {
+			return qrError(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(code)
+	}
+}
+
+// ScanQRCode endpoint
+// Bir QR verisini doğrular ve ödemeden önce alıcıyı ve tutarı gösterir
+func ScanQRCode(qrService *services.QRService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			Payload string `json:"payload"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		scanned, err := qrService.Scan(body.Payload)
+		if err != nil {
+			return qrError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"qr": scanned})
+	}
+}
+
+// PayQRCode endpoint
+// Bir QR kodunun alıcısına transfer yapar; statik kodlar tutar ister
+func PayQRCode(qrService *services.QRService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			Payload string         `json:"payload"`
+			Amount  models.Decimal `json:"amount"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		payment, err := qrService.Pay(userID, body.Payload, body.Amount)
+		if err != nil {
+			return qrError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Payment completed",
+			"payment": payment,
+		})
+	}
+}
+
+// qrError maps service errors to HTTP responses
+// qrError servis hatalarını HTTP cevaplarına çevirir
+func qrError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.NotFoundError(c, "QR code recipient not found")
+	case errors.Is(err, services.ErrNotMerchant):
+		return utils.NotFoundError(c, err.Error())
+	case errors.Is(err, repositories.ErrLinkNotPayable):
+		return utils.ConflictError(c, err.Error())
+	case errors.Is(err, emvqr.ErrChecksum), errors.Is(err, emvqr.ErrForeignPayload):
+		return utils.UnprocessableError(c, err.Error())
+	case errors.Is(err, services.ErrLimitExceeded):
+		return utils.UnprocessableError(c, err.Error())
+	}
+	return utils.BadRequestError(c, err.Error())
+}
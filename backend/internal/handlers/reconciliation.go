@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RunReconciliation endpoint
+// Admin tüm cüzdanlar için mutabakat çalıştırır ve raporu döndürür
+func RunReconciliation(reconciliationService *services.ReconciliationService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			Freeze bool `json:"freeze"`
+		}
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&body); err != nil {
+				return utils.BadRequestError(c, "Invalid request body")
+			}
+		}
+
+		report, err := reconciliationService.Run(body.Freeze)
+		if err != nil {
+			return utils.InternalError(c, "Reconciliation failed")
+		}
+
+		return c.JSON(fiber.Map{"report": report})
+	}
+}
+
+// UnfreezeWallet endpoint
+// Admin dondurulmuş bir cüzdanı çözer
+func UnfreezeWallet(reconciliationService *services.ReconciliationService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		walletID, err := c.ParamsInt("id")
+		if err != nil || walletID <= 0 {
+			return utils.BadRequestError(c, "Invalid wallet id")
+		}
+
+		wallet, err := reconciliationService.Unfreeze(uint(walletID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.NotFoundError(c, "Wallet not found")
+			}
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Wallet unfrozen",
+			"wallet":  wallet,
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ReverseTransaction endpoint
+// Kullanıcının kendi gönderdiği transferi geri alır
+func ReverseTransaction(reversalService *services.ReversalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		transactionID, err := c.ParamsInt("id")
+		if err != nil || transactionID <= 0 {
+			return utils.BadRequestError(c, "Invalid transaction id")
+		}
+
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return utils.BadRequestError(c, "Invalid request body")
+		}
+
+		reversal, err := reversalService.ReverseOwnTransfer(userID, uint(transactionID), body.Reason)
+		if err != nil {
+			return reversalError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message":     "Reversal successful",
+			"transaction": reversal,
+		})
+	}
+}
+
+// AdminReverseTransaction endpoint
+// Admin bir yatırma veya transferi tamamen ya da kısmen geri alır
+func AdminReverseTransaction(reversalService *services.ReversalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		transactionID, err := c.ParamsInt("id")
+		if err != nil || transactionID <= 0 {
+			return utils.BadRequestError(c, "Invalid transaction id")
+		}
+
+		var body struct {
+			Amount models.Decimal `json:"amount"`
+			Reason string         `json:"reason"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		reversal, err := reversalService.AdminReverse(uint(transactionID), body.Amount, body.Reason)
+		if err != nil {
+			return reversalError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message":     "Reversal successful",
+			"transaction": reversal,
+		})
+	}
+}
+
+// reversalError maps service errors to HTTP responses
+// reversalError servis hatalarını HTTP cevaplarına çevirir
+func reversalError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.NotFoundError(c, "Transaction not found")
+	case errors.Is(err, services.ErrReasonRequired), errors.Is(err, models.ErrInvalidAmount),
+		errors.Is(err, models.ErrExcessPrecision), errors.Is(err, models.ErrAmountOverflow):
+		return utils.BadRequestError(c, err.Error())
+	case errors.Is(err, services.ErrAlreadyReversed), errors.Is(err, repositories.ErrReversalExceedsAmount):
+		return utils.ConflictError(c, err.Error())
+	case errors.Is(err, services.ErrNotReversible), errors.Is(err, services.ErrReversalWindowExpired),
+		errors.Is(err, services.ErrInsufficientFunds), errors.Is(err, repositories.ErrWalletFrozen):
+		return utils.UnprocessableError(c, err.Error())
+	}
+
+	// Anything else is our fault, and its message is not for the client
+	// Diğer her şey bizim hatamızdır ve mesajı istemciye yönelik değildir
+	return utils.InternalError(c, "Reversal failed")
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ListSchedules returns the logged user's scheduled transfers
+// ListSchedules giriş yapan kullanıcının zamanlanmış transferlerini döndürür
+func ListSchedules(scheduleService *services.ScheduleService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		schedules, err := scheduleService.List(userID)
+		if err != nil {
+			return utils.InternalError(c, "Failed to retrieve schedules")
+		}
+
+		return c.JSON(fiber.Map{"schedules": schedules})
+	}
+}
+
+// CreateSchedule endpoint
+// İleri tarihli veya tekrarlayan bir transfer oluşturur
+func CreateSchedule(scheduleService *services.ScheduleService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body services.ScheduleInput
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		schedule, err := scheduleService.Create(userID, body)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"schedule": schedule})
+	}
+}
+
+// GetSchedule endpoint
+// Kullanıcının tek bir zamanlanmış transferini döndürür
+func GetSchedule(scheduleService *services.ScheduleService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		scheduleID, err := c.ParamsInt("id")
+		if err != nil || scheduleID <= 0 {
+			return utils.BadRequestError(c, "Invalid schedule id")
+		}
+
+		schedule, err := scheduleService.Get(userID, uint(scheduleID))
+		if err != nil {
+			return scheduleError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"schedule": schedule})
+	}
+}
+
+// UpdateSchedule endpoint
+// Zamanlanmış transferin ayarlarını değiştirir
+func UpdateSchedule(scheduleService *services.ScheduleService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		scheduleID, err := c.ParamsInt("id")
+		if err != nil || scheduleID <= 0 {
+			return utils.BadRequestError(c, "Invalid schedule id")
+		}
+
+		var body services.ScheduleInput
+		if err := c.BodyParser(&body); err != nil {
+			return invalidBody(c, err)
+		}
+
+		schedule, err := scheduleService.Update(userID, uint(scheduleID), body)
+		if err != nil {
+			return scheduleError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"schedule": schedule})
+	}
+}
+
+// CancelSchedule endpoint
+// Zamanlanmış transferi iptal eder
+func CancelSchedule(scheduleService *services.ScheduleService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		scheduleID, err := c.ParamsInt("id")
+		if err != nil || scheduleID <= 0 {
+			return utils.BadRequestError(c, "Invalid schedule id")
+		}
+
+		schedule, err := scheduleService.Cancel(userID, uint(scheduleID))
+		if err != nil {
+			return scheduleError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message":  "Schedule cancelled",
+			"schedule": schedule,
+		})
+	}
+}
+
+// scheduleError maps service errors to HTTP responses
+// scheduleError servis hatalarını HTTP cevaplarına çevirir
+func scheduleError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.NotFoundError(c, "Schedule not found")
+	}
+	return utils.BadRequestError(c, err.Error())
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"time"
+
+	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// periodDate is the date-only form accepted by from and to (statements and history)
+// periodDate from ve to için kabul edilen sadece tarih biçimidir (hesap özeti ve geçmiş)
+const periodDate = "2006-01-02"
+
+// parsePeriodTime accepts RFC 3339 or a UTC date; a date used as the end of the period
+// includes that whole day
+//
+// parsePeriodTime RFC 3339 veya UTC tarih kabul eder; dönem sonu olarak verilen tarih
+// o günün tamamını kapsar
+func parsePeriodTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(periodDate, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// GetStatement streams the wallet statement of a period as CSV, OFX or JSON Lines.
+// ?from= and ?to= default to the last month, ?format= to csv, ?currency= to the default currency.
+//
+// GetStatement bir döneme ait cüzdan hesap özetini CSV, OFX veya JSON Lines olarak akıtır.
+// ?from= ve ?to= varsayılan olarak son bir aydır, ?format= csv, ?currency= varsayılan para birimidir.
+func GetStatement(statementService *services.StatementService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		to := time.Now()
+		if value := c.Query("to"); value != "" {
+			parsed, err := parsePeriodTime(value, true)
+			if err != nil {
+				return utils.BadRequestError(c, "to must be a date (YYYY-MM-DD) or RFC 3339 time")
+			}
+			to = parsed
+		}
+
+		from := to.AddDate(0, -1, 0)
+		if value := c.Query("from"); value != "" {
+			parsed, err := parsePeriodTime(value, false)
+			if err != nil {
+				return utils.BadRequestError(c, "from must be a date (YYYY-MM-DD) or RFC 3339 time")
+			}
+			from = parsed
+		}
+
+		statement, err := statementService.Open(userID, c.Query("currency"), c.Query("format"), from, to)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NotFoundError(c, "Wallet not found")
+		}
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		// The period ends before to, so the file name shows the last day it covers
+		// Dönem to'dan önce biter, bu yüzden dosya adı kapsadığı son günü gösterir
+		contentType, extension := services.StatementContentType(statement.Format)
+		c.Set(fiber.HeaderContentType, contentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="statement-%s-%s-%s.%s"`,
+			statement.Currency.Code, from.UTC().Format(periodDate), to.Add(-time.Nanosecond).UTC().Format(periodDate), extension))
+
+		// The body is written after the handler returns, one batch of history at a time;
+		// errors past this point can only cut the download short (and are logged by the service)
+		//
+		// Gövde handler döndükten sonra, her seferinde bir grup geçmiş olarak yazılır;
+		// bu noktadan sonraki hatalar sadece indirmeyi yarıda keser (ve servis tarafından loglanır)
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			_ = statementService.Write(w, statement)
+		})
+
+		return nil
+	}
+}
@@ -1,26 +1,167 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
 	"mini-pay-backend/internal/services"
 	"mini-pay-backend/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
-// GetTransactionHistory returns the logged user's transaction list
-// GetTransactionHistory giriş yapan kullanıcının işlem geçmişini döndürür
+// GetTransactionHistory returns one page of the logged user's transactions, newest first.
+// Filters: ?type= (comma separated), ?currency=, ?min_amount=, ?max_amount=, ?from=, ?to=,
+// ?counterparty_id=; paging: ?limit= and ?cursor= (the next_cursor of the previous page).
+//
+// GetTransactionHistory giriş yapan kullanıcının işlemlerinin bir sayfasını yeniden eskiye döndürür.
+// Filtreler: ?type= (virgülle ayrılmış), ?currency=, ?min_amount=, ?max_amount=, ?from=, ?to=,
+// ?counterparty_id=; sayfalama: ?limit= ve ?cursor= (önceki sayfanın next_cursor değeri).
 func GetTransactionHistory(transactionService *services.TransactionService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		userID := uint(c.Locals("user_id").(float64))
 
-		history, err := transactionService.GetHistory(userID)
+		filter, err := historyFilter(c)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		limit := c.QueryInt("limit", services.DefaultHistoryLimit)
+		if limit <= 0 {
+			return utils.BadRequestError(c, "limit must be positive")
+		}
+
+		page, err := transactionService.GetHistory(userID, filter, c.Query("cursor"), limit)
+		if errors.Is(err, services.ErrInvalidCursor) {
+			return utils.BadRequestError(c, "Invalid cursor")
+		}
 		if err != nil {
 			return utils.InternalError(c, "Failed to retrieve transaction history")
 		}
 
 		return c.JSON(fiber.Map{
 			"user_id":      userID,
-			"transactions": history,
+			"transactions": page.Transactions,
+			"next_cursor":  page.NextCursor,
 		})
 	}
 }
+
+// historyFilter reads the history filters from the query string
+// historyFilter geçmiş filtrelerini sorgu parametrelerinden okur
+func historyFilter(c *fiber.Ctx) (repositories.HistoryFilter, error) {
+	var filter repositories.HistoryFilter
+
+	if value := c.Query("type"); value != "" {
+		for _, txType := range strings.Split(value, ",") {
+			if txType = strings.TrimSpace(txType); txType != "" {
+				filter.Types = append(filter.Types, txType)
+			}
+		}
+	}
+
+	var currency models.Currency
+	if value := c.Query("currency"); value != "" {
+		var err error
+		if currency, err = models.LookupCurrency(value); err != nil {
+			return filter, err
+		}
+		filter.Currency = currency.Code
+	}
+
+	// Amount bounds are decimals, so they only mean something in a given currency
+	// Tutar sınırları ondalıktır, bu yüzden sadece belirli bir para biriminde anlamlıdır
+	amounts := []struct {
+		name   string
+		target **int64
+	}{
+		{"min_amount", &filter.MinAmount},
+		{"max_amount", &filter.MaxAmount},
+	}
+	for _, amount := range amounts {
+		if value := c.Query(amount.name); value != "" {
+			if filter.Currency == "" {
+				return filter, fmt.Errorf("%s requires a currency", amount.name)
+			}
+			decimal, err := models.ParseDecimal(value)
+			if err != nil {
+				return filter, fmt.Errorf("%s: %w", amount.name, err)
+			}
+			parsed, err := decimal.Minor(currency)
+			if err != nil {
+				return filter, fmt.Errorf("%s: %w", amount.name, err)
+			}
+			*amount.target = &parsed
+		}
+	}
+
+	if value := c.Query("from"); value != "" {
+		from, err := parsePeriodTime(value, false)
+		if err != nil {
+			return filter, errors.New("from must be a date (YYYY-MM-DD) or RFC 3339 time")
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := parsePeriodTime(value, true)
+		if err != nil {
+			return filter, errors.New("to must be a date (YYYY-MM-DD) or RFC 3339 time")
+		}
+		filter.To = &to
+	}
+
+	if value := c.Query("counterparty_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || parsed == 0 {
+			return filter, errors.New("counterparty_id must be a user id")
+		}
+		counterpartyID := uint(parsed)
+		filter.CounterpartyID = &counterpartyID
+	}
+
+	return filter, nil
+}
+
+// GetTransaction returns one of the logged user's transactions with its counterparty,
+// the linked entries of the same money movement and its status
+//
+// GetTransaction giriş yapan kullanıcının bir işlemini karşı tarafı, aynı para hareketinin
+// bağlı kayıtları ve durumu ile birlikte döndürür
+func GetTransaction(transactionService *services.TransactionService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		transactionID, err := c.ParamsInt("id")
+		if err != nil || transactionID <= 0 {
+			return utils.BadRequestError(c, "Invalid transaction id")
+		}
+
+		detail, err := transactionService.GetDetail(userID, uint(transactionID))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NotFoundError(c, "Transaction not found")
+		}
+		if err != nil {
+			return utils.InternalError(c, "Failed to retrieve transaction")
+		}
+
+		return c.JSON(detail)
+	}
+}
+
+// VerifyTransactionChain walks the hash chain of every wallet's history (admin only)
+// VerifyTransactionChain her cüzdan geçmişinin hash zincirini dolaşır (sadece admin)
+func VerifyTransactionChain(chainService *services.ChainService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		report, err := chainService.Verify()
+		if err != nil {
+			return utils.InternalError(c, "Failed to verify transaction chain")
+		}
+
+		return c.JSON(fiber.Map{"report": report})
+	}
+}
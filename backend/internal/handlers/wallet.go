@@ -1,31 +1,74 @@
 package handlers
 
 import (
+	"errors"
+	"strings"
+
+	"mini-pay-backend/internal/models"
 	"mini-pay-backend/internal/services"
 	"mini-pay-backend/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-// GetBalance returns current user's wallet balance
-// GetBalance giriş yapan kullanıcının bakiyesini döndürür
-func GetBalance(walletService *services.WalletService) fiber.Handler {
+// GetBalance returns current user's wallet balances, one per currency, with what is set aside in pots
+// GetBalance giriş yapan kullanıcının para birimi başına bakiyelerini kumbaralara ayrılanla birlikte döndürür
+func GetBalance(walletService *services.WalletService, potService *services.PotService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 
 		// Extract user_id stored by AuthMiddleware
 		// AuthMiddleware tarafından saklanan user_id değerini al
 		userID := uint(c.Locals("user_id").(float64))
 
-		balance, err := walletService.GetBalance(userID)
+		wallets, err := walletService.GetBalances(userID)
 		if err != nil {
 			return utils.NotFoundError(c, "Wallet not found")
 		}
+		pots, err := potService.Totals(userID)
+		if err != nil {
+			return utils.InternalError(c, "Failed to retrieve pots")
+		}
+
+		// Amounts are exact decimal strings in each currency's precision
+		// Tutarlar her para biriminin hassasiyetinde kesin ondalık metinlerdir
+		balances := make([]fiber.Map, 0, len(wallets))
+		for _, wallet := range wallets {
+			balances = append(balances, fiber.Map{
+				"currency":  wallet.Currency,
+				"balance":   models.NewMoney(wallet.Balance, wallet.Currency),
+				"available": models.NewMoney(wallet.Available(), wallet.Currency),
+				"held":      models.NewMoney(wallet.Held, wallet.Currency),
+				"pots":      models.NewMoney(pots[wallet.Currency], wallet.Currency),
+			})
+		}
 
-		// Convert cents → float for display
-		// Kuruşu gösterim için çevir
 		return c.JSON(fiber.Map{
-			"balance": float64(balance) / 100.0,
+			"balances": balances,
+		})
+	}
+}
+
+// OpenWallet endpoint
+// Kullanıcı için başka bir para biriminde cüzdan açar
+func OpenWallet(walletService *services.WalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		var body struct {
+			Currency string `json:"currency"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return utils.BadRequestError(c, "Invalid request body")
+		}
+
+		wallet, err := walletService.OpenWallet(userID, body.Currency)
+		if err != nil {
+			return utils.BadRequestError(c, err.Error())
+		}
+
+		return c.JSON(fiber.Map{
+			"message":  "Wallet ready",
+			"currency": wallet.Currency,
 		})
 	}
 }
@@ -37,13 +80,14 @@ func Deposit(walletService *services.WalletService) fiber.Handler {
 		userID := uint(c.Locals("user_id").(float64))
 
 		var body struct {
-			Amount int64 `json:"amount"`
+			Amount   models.Decimal `json:"amount"`
+			Currency string         `json:"currency"`
 		}
 		if err := c.BodyParser(&body); err != nil {
-			return utils.BadRequestError(c, "Invalid request body")
+			return invalidBody(c, err)
 		}
 
-		if err := walletService.Deposit(userID, body.Amount); err != nil {
+		if err := walletService.Deposit(userID, body.Currency, body.Amount); err != nil {
 			return utils.BadRequestError(c, err.Error())
 		}
 
@@ -58,38 +102,98 @@ func Withdraw(walletService *services.WalletService) fiber.Handler {
 		userID := uint(c.Locals("user_id").(float64))
 
 		var body struct {
-			Amount int64 `json:"amount"`
+			Amount   models.Decimal `json:"amount"`
+			Currency string         `json:"currency"`
 		}
 		if err := c.BodyParser(&body); err != nil {
-			return utils.BadRequestError(c, "Invalid request body")
+			return invalidBody(c, err)
 		}
 
-		if err := walletService.Withdraw(userID, body.Amount); err != nil {
+		fee, err := walletService.Withdraw(userID, body.Currency, body.Amount)
+		if err != nil {
+			if errors.Is(err, services.ErrLimitExceeded) {
+				return utils.UnprocessableError(c, err.Error())
+			}
 			return utils.BadRequestError(c, err.Error())
 		}
 
-		return c.JSON(fiber.Map{"message": "Withdraw successful"})
+		return c.JSON(fiber.Map{
+			"message": "Withdraw successful",
+			"fee":     fee,
+		})
 	}
 }
 
 // Transfer endpoint
 // İki kullanıcı arasında para transferi yapar
-func Transfer(walletService *services.WalletService, db any) fiber.Handler {
+func Transfer(walletService *services.WalletService, payeeService *services.PayeeService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		fromUserID := uint(c.Locals("user_id").(float64))
 
 		var body struct {
-			ToUserID uint  `json:"to_user_id"`
-			Amount   int64 `json:"amount"`
+			To         string         `json:"to"`
+			ToUserID   uint           `json:"to_user_id"`
+			Amount     models.Decimal `json:"amount"`
+			Currency   string         `json:"currency"`
+			ToCurrency string         `json:"to_currency"`
+			QuoteID    uint           `json:"quote_id"`
 		}
 		if err := c.BodyParser(&body); err != nil {
-			return utils.BadRequestError(c, "Invalid request body")
+			return invalidBody(c, err)
+		}
+
+		// "to" names the recipient by email, phone or @handle; to_user_id is kept for older clients
+		// "to" alıcıyı e-posta, telefon veya @handle ile belirtir; to_user_id eski istemciler için korunur
+		if body.To != "" {
+			payee, err := payeeService.Resolve(body.To)
+			if err != nil {
+				return utils.NotFoundError(c, err.Error())
+			}
+			body.ToUserID = payee.ID
 		}
 
-		if err := walletService.Transfer(db.(*gorm.DB), fromUserID, body.ToUserID, body.Amount); err != nil {
+		// Cross-currency transfers use the currencies and rate of the quote
+		// Farklı para birimleri arası transferler teklifin para birimlerini ve kurunu kullanır
+		if body.QuoteID != 0 {
+			conversion, err := walletService.TransferConverted(fromUserID, body.ToUserID, body.QuoteID, body.Amount)
+			if errors.Is(err, services.ErrLimitExceeded) {
+				return utils.UnprocessableError(c, err.Error())
+			}
+			if err != nil {
+				return utils.BadRequestError(c, err.Error())
+			}
+			return c.JSON(fiber.Map{
+				"message":    "Transfer successful",
+				"conversion": conversion,
+			})
+		}
+
+		// Without a quote both sides must use the same currency
+		// Teklif olmadan iki taraf da aynı para birimini kullanmalıdır
+		if body.ToCurrency != "" && !strings.EqualFold(body.ToCurrency, body.Currency) {
+			return utils.BadRequestError(c, "Transfers between different currencies require a quote_id")
+		}
+
+		fee, err := walletService.Transfer(fromUserID, body.ToUserID, body.Currency, body.Amount)
+		if err != nil {
+			if errors.Is(err, services.ErrLimitExceeded) {
+				return utils.UnprocessableError(c, err.Error())
+			}
 			return utils.BadRequestError(c, err.Error())
 		}
 
-		return c.JSON(fiber.Map{"message": "Transfer successful"})
+		return c.JSON(fiber.Map{
+			"message": "Transfer successful",
+			"fee":     fee,
+		})
+	}
+}
+
+// invalidBody answers a body that could not be parsed; amount errors keep their own message
+// invalidBody çözümlenemeyen bir gövdeye cevap verir; tutar hataları kendi mesajını korur
+func invalidBody(c *fiber.Ctx, err error) error {
+	if errors.Is(err, models.ErrInvalidAmount) || errors.Is(err, models.ErrAmountOverflow) {
+		return utils.BadRequestError(c, err.Error())
 	}
+	return utils.BadRequestError(c, "Invalid request body")
 }
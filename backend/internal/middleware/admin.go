@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"mini-pay-backend/internal/repositories"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminMiddleware allows only users flagged as admin
+// Must run after AuthMiddleware.
+//
+// AdminMiddleware sadece admin olarak işaretlenmiş kullanıcılara izin verir
+// AuthMiddleware'den sonra çalışmalıdır.
+func AdminMiddleware(userRepo *repositories.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := uint(c.Locals("user_id").(float64))
+
+		user, err := userRepo.FindByID(userID)
+		if err != nil || !user.IsAdmin {
+			return utils.ForbiddenError(c, "Admin access required")
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+	"mini-pay-backend/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IdempotencyHeader is the header clients use to make retries safe
+// IdempotencyHeader istemcilerin tekrar denemeleri güvenli hale getirdiği header'dır
+const IdempotencyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength limits stored key size
+// maxIdempotencyKeyLength saklanan anahtar boyutunu sınırlar
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyMiddleware replays the stored response when a request is retried
+// with the same Idempotency-Key, and rejects a key reused with a different body.
+// Requests without the header are passed through unchanged. A claim that never finished
+// (the process died, or its result could not be stored) is never run again, because the
+// operation may already have committed; once it is older than claimTTL retries are told to
+// check their history instead of waiting. Must run after AuthMiddleware.
+//
+// IdempotencyMiddleware aynı Idempotency-Key ile tekrarlanan isteklerde kayıtlı
+// cevabı döndürür, farklı body ile kullanılan anahtarı reddeder.
+// Header içermeyen istekler olduğu gibi geçer. Hiç bitmemiş bir sahiplenme (süreç öldü
+// veya sonucu saklanamadı) asla yeniden çalıştırılmaz, çünkü işlem commit edilmiş olabilir;
+// claimTTL'den eski olduğunda tekrar denemelere beklemek yerine geçmişlerini kontrol etmeleri söylenir.
+// AuthMiddleware'den sonra çalışmalıdır.
+func IdempotencyMiddleware(repo *repositories.IdempotencyRepository, claimTTL time.Duration, log logger.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+
+		key := c.Get(IdempotencyHeader)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return utils.BadRequestError(c, "Idempotency-Key is too long")
+		}
+
+		userID := uint(c.Locals("user_id").(float64))
+
+		// Fingerprint the request so a reused key with a different body is detected
+		// Farklı body ile tekrar kullanılan anahtarı yakalamak için isteğin parmak izini al
+		hash := sha256.New()
+		hash.Write([]byte(c.Method()))
+		hash.Write([]byte(c.Path()))
+		hash.Write(c.Body())
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		record := &models.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ClaimedAt:   time.Now(),
+		}
+
+		// Claim the key; the unique index rejects a second claim
+		// Anahtarı sahiplen; benzersiz index ikinci sahiplenmeyi reddeder
+		if err := repo.Create(record); err != nil {
+			existing, findErr := repo.FindByUserAndKey(userID, key)
+			if findErr != nil {
+				return utils.InternalError(c, "Failed to process Idempotency-Key")
+			}
+			return replay(c, existing, fingerprint, claimTTL)
+		}
+
+		if err := c.Next(); err != nil {
+			_ = repo.Delete(record)
+			return err
+		}
+
+		// Server errors are not stored so the client can retry
+		// Sunucu hataları saklanmaz, böylece istemci tekrar deneyebilir
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			_ = repo.Delete(record)
+			return nil
+		}
+
+		record.StatusCode = status
+		record.ResponseBody = string(c.Response().Body())
+		if err := repo.Update(record); err != nil {
+
+			// The operation already ran; the claim stays open so it is never run again
+			// İşlem zaten çalıştı; sahiplenme açık kalır, böylece asla yeniden çalıştırılmaz
+			log.Error("Idempotency response could not be stored", map[string]interface{}{
+				"user_id": userID,
+				"key":     key,
+				"status":  status,
+				"error":   err.Error(),
+			})
+		}
+
+		return nil
+	}
+}
+
+// replay answers a retried request from the stored record
+// replay tekrarlanan isteği kayıtlı sonuçtan cevaplar
+func replay(c *fiber.Ctx, record *models.IdempotencyKey, fingerprint string, claimTTL time.Duration) error {
+	if record.Fingerprint != fingerprint {
+		return utils.UnprocessableError(c, "Idempotency-Key was already used with a different request")
+	}
+
+	if record.StatusCode == 0 {
+		if time.Since(record.ClaimedAt) > claimTTL {
+			return utils.ConflictError(c, "A request with this Idempotency-Key did not finish and may have been applied; check your history before retrying with a new key")
+		}
+		return utils.ConflictError(c, "A request with this Idempotency-Key is still being processed")
+	}
+
+	c.Set("Idempotent-Replayed", "true")
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(record.StatusCode).SendString(record.ResponseBody)
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedCurrency is returned for codes missing from the currency table
+// ErrUnsupportedCurrency tabloda olmayan para birimi kodları için döner
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// Currency describes an ISO 4217 currency and its minor-unit precision
+// Currency bir ISO 4217 para birimini ve alt birim hassasiyetini tanımlar
+type Currency struct {
+	// Code is the ISO 4217 alphabetic code (e.g. "TRY")
+	// Code ISO 4217 alfabetik kodudur (örn. "TRY")
+	Code string `json:"code"`
+
+	// Numeric is the ISO 4217 numeric code (e.g. "949"), used where letters do not fit, such as QR codes
+	// Numeric ISO 4217 sayısal kodudur (örn. "949"); QR kodları gibi harflerin uymadığı yerlerde kullanılır
+	Numeric string `json:"-"`
+
+	// MinorUnits is the number of decimals (2 for cents, 0 for JPY, 3 for KWD)
+	// MinorUnits ondalık basamak sayısıdır (kuruş için 2, JPY için 0, KWD için 3)
+	MinorUnits int `json:"minor_units"`
+}
+
+// currencies lists every currency a wallet can be opened in
+// currencies cüzdan açılabilecek tüm para birimlerini listeler
+var currencies = map[string]Currency{
+	"TRY": {Code: "TRY", Numeric: "949", MinorUnits: 2},
+	"USD": {Code: "USD", Numeric: "840", MinorUnits: 2},
+	"EUR": {Code: "EUR", Numeric: "978", MinorUnits: 2},
+	"GBP": {Code: "GBP", Numeric: "826", MinorUnits: 2},
+	"CHF": {Code: "CHF", Numeric: "756", MinorUnits: 2},
+	"JPY": {Code: "JPY", Numeric: "392", MinorUnits: 0},
+	"KWD": {Code: "KWD", Numeric: "414", MinorUnits: 3},
+}
+
+// LookupCurrency normalizes a code and returns its definition
+// LookupCurrency kodu normalize eder ve tanımını döndürür
+func LookupCurrency(code string) (Currency, error) {
+	currency, ok := currencies[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Currency{}, ErrUnsupportedCurrency
+	}
+	return currency, nil
+}
+
+// LookupCurrencyNumeric returns the currency with the given ISO 4217 numeric code
+// LookupCurrencyNumeric verilen ISO 4217 sayısal koduna sahip para birimini döndürür
+func LookupCurrencyNumeric(numeric string) (Currency, error) {
+	for _, currency := range currencies {
+		if currency.Numeric == numeric {
+			return currency, nil
+		}
+	}
+	return Currency{}, ErrUnsupportedCurrency
+}
+
+// FormatMinor renders minor units as an exact decimal string (e.g. -1234 cents → "-12.34")
+// FormatMinor alt birimleri kesin bir ondalık metne çevirir (örn. -1234 kuruş → "-12.34")
+func (c Currency) FormatMinor(amount int64) string {
+	sign := ""
+	magnitude := uint64(amount)
+	if amount < 0 {
+		sign = "-"
+		magnitude = uint64(-amount)
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	if c.MinorUnits == 0 {
+		return sign + digits
+	}
+	if len(digits) <= c.MinorUnits {
+		digits = strings.Repeat("0", c.MinorUnits-len(digits)+1) + digits
+	}
+
+	point := len(digits) - c.MinorUnits
+	return sign + digits[:point] + "." + digits[point:]
+}
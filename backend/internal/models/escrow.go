@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Escrow statuses. An escrow starts funded; funded and disputed are open,
+// released and refunded are final.
+//
+// Emanet durumları. Bir emanet funded olarak başlar; funded ve disputed açık,
+// released ve refunded son durumlardır.
+const (
+	EscrowStatusFunded   = "funded"
+	EscrowStatusDisputed = "disputed"
+	EscrowStatusReleased = "released"
+	EscrowStatusRefunded = "refunded"
+)
+
+// Escrow resolutions: why an escrow was released or refunded
+// Emanet sonuçları: bir emanetin neden serbest bırakıldığı veya iade edildiği
+const (
+	EscrowResolutionConfirmed    = "confirmed"
+	EscrowResolutionAutoReleased = "auto_released"
+	EscrowResolutionCancelled    = "cancelled"
+	EscrowResolutionAdmin        = "admin"
+)
+
+// Escrow holds a buyer's payment for a sale until the buyer confirms it, the
+// release time passes, or it is cancelled or a dispute is resolved. While open,
+// the money sits in the escrow's own ledger account, in neither wallet.
+//
+// Escrow bir satış için alıcının ödemesini, alıcı onaylayana, serbest bırakma
+// zamanı geçene, iptal edilene veya bir itiraz çözülene kadar tutar. Açıkken para
+// hiçbir cüzdanda değil, emanetin kendi defter hesabında durur.
+type Escrow struct {
+	gorm.Model
+
+	// BuyerID paid into the escrow; SellerID receives the money on release
+	// BuyerID emanete ödeme yapmıştır; SellerID serbest bırakmada parayı alır
+	BuyerID  uint `gorm:"index;not null" json:"buyer_id"`
+	SellerID uint `gorm:"index;not null" json:"seller_id"`
+
+	// Currency and Amount describe the money held (minor units)
+	// Currency ve Amount tutulan parayı tanımlar (alt birim)
+	Currency string `gorm:"type:text;not null" json:"currency"`
+	Amount   int64  `gorm:"not null" json:"amount"`
+
+	// Description says what was sold (e.g. "Used bike")
+	// Description neyin satıldığını söyler (örn. "İkinci el bisiklet")
+	Description string `gorm:"not null" json:"description"`
+
+	// Status moves from funded to released or refunded, possibly through disputed
+	// Status funded'dan, belki disputed üzerinden, released veya refunded'a geçer
+	Status string `gorm:"type:text;not null;index" json:"status"`
+
+	// ReleaseAt is when a funded escrow is released to the seller automatically; disputes stop the clock
+	// ReleaseAt funded bir emanetin satıcıya otomatik serbest bırakılacağı zamandır; itirazlar saati durdurur
+	ReleaseAt time.Time `gorm:"not null;index" json:"release_at"`
+
+	// DisputedBy is the party who opened a dispute, with their reason
+	// DisputedBy itiraz açan taraftır, gerekçesiyle birlikte
+	DisputedBy    *uint  `json:"disputed_by,omitempty"`
+	DisputeReason string `json:"dispute_reason,omitempty"`
+
+	// ReleaseFailures counts failed automatic releases and ReleaseError keeps the last reason,
+	// so a stuck escrow is visible to support and does not hold up the others
+	// ReleaseFailures başarısız otomatik serbest bırakmaları sayar ve ReleaseError son nedeni tutar,
+	// böylece takılmış bir emanet destek ekibine görünür ve diğerlerini bekletmez
+	ReleaseFailures int    `gorm:"not null;default:0" json:"release_failures,omitempty"`
+	ReleaseError    string `json:"release_error,omitempty"`
+
+	// Resolution tells why the escrow closed; ResolvedBy is the user who closed it (nil when automatic)
+	// Resolution emanetin neden kapandığını söyler; ResolvedBy onu kapatan kullanıcıdır (otomatikse nil)
+	Resolution string     `json:"resolution,omitempty"`
+	ResolvedBy *uint      `json:"resolved_by,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+}
+
+// IsOpen tells whether the escrow still holds money
+// IsOpen emanetin hâlâ para tutup tutmadığını söyler
+func (e *Escrow) IsOpen() bool {
+	return e.Status == EscrowStatusFunded || e.Status == EscrowStatusDisputed
+}
+
+// MarshalJSON writes the amount as a decimal string in the escrow's currency
+// MarshalJSON tutarı emanetin para biriminde ondalık metin olarak yazar
+func (e Escrow) MarshalJSON() ([]byte, error) {
+	type plain Escrow
+	return json.Marshal(struct {
+		plain
+		Amount Money `json:"amount"`
+	}{plain(e), NewMoney(e.Amount, e.Currency)})
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FxQuote locks an exchange rate for a user until it expires or is used once
+// FxQuote bir döviz kurunu kullanıcı için süresi dolana veya bir kez kullanılana kadar sabitler
+type FxQuote struct {
+	gorm.Model
+
+	// UserID is the only user allowed to use the quote
+	// UserID teklifi kullanabilecek tek kullanıcıdır
+	UserID uint `gorm:"index;not null" json:"user_id"`
+
+	// FromCurrency and ToCurrency define the direction of the conversion
+	// FromCurrency ve ToCurrency çevrimin yönünü belirler
+	FromCurrency string `gorm:"type:text;not null" json:"from_currency"`
+	ToCurrency   string `gorm:"type:text;not null" json:"to_currency"`
+
+	// MidRate is the provider rate; Rate is what the customer gets after the spread
+	// MidRate sağlayıcı kurudur; Rate marj sonrası müşteriye uygulanan kurdur
+	MidRate   string `gorm:"type:text;not null" json:"mid_rate"`
+	Rate      string `gorm:"type:text;not null" json:"rate"`
+	SpreadBps int64  `json:"spread_bps"`
+
+	// ExpiresAt is when the locked rate stops being valid
+	// ExpiresAt sabitlenen kurun geçerliliğini yitirdiği zamandır
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+
+	// UsedAt is set when the quote is consumed by a conversion
+	// UsedAt teklif bir çevrimde kullanıldığında atanır
+	UsedAt *time.Time `json:"used_at,omitempty"`
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Expense split methods
+// Harcama bölüşüm yöntemleri
+const (
+	// SplitEqual divides the amount evenly among the participants
+	// SplitEqual tutarı katılımcılar arasında eşit böler
+	SplitEqual = "equal"
+
+	// SplitShares divides the amount in proportion to each participant's shares
+	// SplitShares tutarı her katılımcının payı oranında böler
+	SplitShares = "shares"
+
+	// SplitExact takes each participant's amount as given; they must add up to the expense
+	// SplitExact her katılımcının tutarını verildiği gibi alır; toplamları harcamaya eşit olmalıdır
+	SplitExact = "exact"
+)
+
+// Group member statuses
+// Grup üye durumları
+const (
+	GroupMemberActive = "active"
+	GroupMemberLeft   = "left"
+)
+
+// Group is a set of users who share expenses (a flat, a trip, a dinner).
+// Expenses are bookkeeping only; money moves when members settle up.
+//
+// Group harcamaları paylaşan bir kullanıcı kümesidir (ev, gezi, akşam yemeği).
+// Harcamalar sadece kayıttır; para üyeler hesaplaştığında taşınır.
+type Group struct {
+	gorm.Model
+
+	// Name is shown to the members (e.g. "Istanbul trip")
+	// Name üyelere gösterilir (örn. "İstanbul gezisi")
+	Name string `gorm:"not null" json:"name"`
+
+	// Currency is the currency of every expense and settlement of the group
+	// Currency grubun her harcamasının ve hesaplaşmasının para birimidir
+	Currency string `gorm:"type:text;not null" json:"currency"`
+
+	// CreatedBy is the user who created the group
+	// CreatedBy grubu oluşturan kullanıcıdır
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+}
+
+// GroupMember is one user's membership of a group
+// GroupMember bir kullanıcının bir gruptaki üyeliğidir
+type GroupMember struct {
+	gorm.Model
+
+	GroupID uint `gorm:"not null;uniqueIndex:idx_group_member" json:"group_id"`
+	UserID  uint `gorm:"not null;uniqueIndex:idx_group_member;index" json:"user_id"`
+
+	// Status is active until the member leaves; members can only leave when settled
+	// Status üye ayrılana kadar active'dir; üyeler ancak hesaplaşınca ayrılabilir
+	Status string `gorm:"type:text;not null" json:"status"`
+
+	// AddedBy is the member who added this user
+	// AddedBy bu kullanıcıyı ekleyen üyedir
+	AddedBy uint `gorm:"not null" json:"added_by"`
+}
+
+// Expense is one bill paid by a member and split among participants
+// Expense bir üyenin ödediği ve katılımcılar arasında bölünen tek bir faturadır
+type Expense struct {
+	gorm.Model
+
+	GroupID     uint   `gorm:"not null;index" json:"group_id"`
+	Description string `gorm:"not null" json:"description"`
+
+	// PaidBy fronted the whole Amount; CreatedBy entered the expense
+	// PaidBy tüm Amount'u ödemiştir; CreatedBy harcamayı girmiştir
+	PaidBy    uint `gorm:"not null" json:"paid_by"`
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	Amount   int64  `gorm:"not null" json:"amount"`
+	Currency string `gorm:"type:text;not null" json:"currency"`
+
+	// Split is equal, shares or exact
+	// Split equal, shares veya exact'tir
+	Split string `gorm:"type:text;not null" json:"split"`
+
+	Shares []ExpenseShare `gorm:"foreignKey:ExpenseID" json:"shares"`
+}
+
+// MarshalJSON writes the amount as a decimal string in the expense's currency
+// MarshalJSON tutarı harcamanın para biriminde ondalık metin olarak yazar
+func (e Expense) MarshalJSON() ([]byte, error) {
+	type plain Expense
+	return json.Marshal(struct {
+		plain
+		Amount Money `json:"amount"`
+	}{plain(e), NewMoney(e.Amount, e.Currency)})
+}
+
+// ExpenseShare is what one participant owes of an expense; the shares of an expense add up to its amount
+// ExpenseShare bir katılımcının bir harcamadan borcudur; bir harcamanın payları tutarına eşittir
+type ExpenseShare struct {
+	ID        uint `gorm:"primarykey" json:"id"`
+	ExpenseID uint `gorm:"not null;index" json:"expense_id"`
+	UserID    uint `gorm:"not null" json:"user_id"`
+
+	// Weight is the number of shares for the shares split (1 for equal, 0 for exact)
+	// Weight shares bölüşümü için pay sayısıdır (equal için 1, exact için 0)
+	Weight int `gorm:"not null;default:0" json:"weight"`
+
+	Amount   int64  `gorm:"not null" json:"amount"`
+	Currency string `gorm:"type:text;not null" json:"currency"`
+}
+
+// MarshalJSON writes the amount as a decimal string in the share's currency
+// MarshalJSON tutarı payın para biriminde ondalık metin olarak yazar
+func (s ExpenseShare) MarshalJSON() ([]byte, error) {
+	type plain ExpenseShare
+	return json.Marshal(struct {
+		plain
+		Amount Money `json:"amount"`
+	}{plain(s), NewMoney(s.Amount, s.Currency)})
+}
+
+// Settlement is a transfer between two members that pays down group debt
+// Settlement grup borcunu azaltan iki üye arasındaki bir transferdir
+type Settlement struct {
+	ID         uint   `gorm:"primarykey" json:"id"`
+	GroupID    uint   `gorm:"not null;index" json:"group_id"`
+	FromUserID uint   `gorm:"not null" json:"from_user_id"`
+	ToUserID   uint   `gorm:"not null" json:"to_user_id"`
+	Amount     int64  `gorm:"not null" json:"amount"`
+	Currency   string `gorm:"type:text;not null" json:"currency"`
+
+	// TransactionID is the sender's transfer_sent row
+	// TransactionID göndericinin transfer_sent satırıdır
+	TransactionID uint      `gorm:"not null" json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// MarshalJSON writes the amount as a decimal string in the settlement's currency
+// MarshalJSON tutarı hesaplaşmanın para biriminde ondalık metin olarak yazar
+func (s Settlement) MarshalJSON() ([]byte, error) {
+	type plain Settlement
+	return json.Marshal(struct {
+		plain
+		Amount Money `json:"amount"`
+	}{plain(s), NewMoney(s.Amount, s.Currency)})
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Hold statuses
+// Provizyon durumları
+const (
+	HoldStatusAuthorized = "authorized"
+	HoldStatusCaptured   = "captured"
+	HoldStatusVoided     = "voided"
+	HoldStatusExpired    = "expired"
+)
+
+// Hold reserves money in the payer's wallet until it is captured, voided or expires
+// Hold ödeyenin cüzdanındaki parayı tahsil, iptal veya süre dolana kadar ayırır
+type Hold struct {
+	gorm.Model
+
+	// UserID is the payer whose wallet the money is reserved in
+	// UserID parası ayrılan ödeyen kullanıcıdır
+	UserID uint `gorm:"index;not null" json:"user_id"`
+
+	// PayeeID is the user allowed to capture the hold
+	// PayeeID provizyonu tahsil edebilecek kullanıcıdır
+	PayeeID uint `gorm:"index;not null" json:"payee_id"`
+
+	// Currency and Amount describe the reserved money (minor units)
+	// Currency ve Amount ayrılan parayı tanımlar (alt birim)
+	Currency string `gorm:"type:text;not null" json:"currency"`
+	Amount   int64  `gorm:"not null" json:"amount"`
+
+	// CapturedAmount is what the payee took; the rest went back to the payer
+	// CapturedAmount alıcının tahsil ettiği tutardır; kalanı ödeyene geri döner
+	CapturedAmount int64 `gorm:"not null;default:0" json:"captured_amount"`
+
+	// Status is authorized until the hold is captured, voided or expired
+	// Status provizyon tahsil, iptal edilene veya süresi dolana kadar authorized'dır
+	Status string `gorm:"type:text;not null;index" json:"status"`
+
+	// ExpiresAt is when an uncaptured hold is released automatically
+	// ExpiresAt tahsil edilmemiş provizyonun otomatik serbest bırakılacağı zamandır
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+// MarshalJSON writes amounts as decimal strings in the hold's currency
+// MarshalJSON tutarları provizyonun para biriminde ondalık metin olarak yazar
+func (h Hold) MarshalJSON() ([]byte, error) {
+	type plain Hold
+	return json.Marshal(struct {
+		plain
+		Amount         Money `json:"amount"`
+		CapturedAmount Money `json:"captured_amount"`
+	}{plain(h), NewMoney(h.Amount, h.Currency), NewMoney(h.CapturedAmount, h.Currency)})
+}
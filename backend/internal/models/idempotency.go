@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKey stores the outcome of a request sent with an Idempotency-Key header
+// IdempotencyKey, Idempotency-Key header'ı ile gönderilen isteğin sonucunu saklar
+type IdempotencyKey struct {
+	gorm.Model
+
+	// UserID and Key together identify the request; keys are scoped per user
+	// UserID ve Key birlikte isteği tanımlar; anahtarlar kullanıcı bazlıdır
+	UserID uint   `gorm:"uniqueIndex:idx_idempotency_user_key;not null" json:"user_id"`
+	Key    string `gorm:"uniqueIndex:idx_idempotency_user_key;not null" json:"key"`
+
+	// Fingerprint is a SHA-256 of method, path and body of the original request
+	// Fingerprint orijinal isteğin method, path ve body bilgisinin SHA-256 özetidir
+	Fingerprint string `gorm:"not null" json:"fingerprint"`
+
+	// StatusCode is 0 while the original request is still being processed
+	// StatusCode orijinal istek işlenirken 0'dır
+	StatusCode int `json:"status_code"`
+
+	// ClaimedAt is when the running request took the key; an old claim that never finished is reported as abandoned
+	// ClaimedAt çalışan isteğin anahtarı aldığı zamandır; hiç bitmemiş eski bir sahiplenme terk edilmiş olarak bildirilir
+	ClaimedAt time.Time `json:"claimed_at"`
+
+	// ResponseBody is the original response replayed on retries
+	// ResponseBody tekrar denemelerde aynen döndürülen orijinal cevaptır
+	ResponseBody string `json:"-"`
+}
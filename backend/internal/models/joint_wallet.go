@@ -0,0 +1,195 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Joint wallet member roles
+// Ortak cüzdan üye rolleri
+const (
+	// JointRoleOwner manages members and the approval policy and can spend
+	// JointRoleOwner üyeleri ve onay politikasını yönetir, harcama yapabilir
+	JointRoleOwner = "owner"
+
+	// JointRoleSpender can add money, spend and approve withdrawals
+	// JointRoleSpender para ekleyebilir, harcayabilir ve çekimleri onaylayabilir
+	JointRoleSpender = "spender"
+
+	// JointRoleViewer only sees the balance and history
+	// JointRoleViewer sadece bakiyeyi ve geçmişi görür
+	JointRoleViewer = "viewer"
+)
+
+// Joint wallet member statuses
+// Ortak cüzdan üye durumları
+const (
+	JointMemberInvited  = "invited"
+	JointMemberActive   = "active"
+	JointMemberDeclined = "declined"
+	JointMemberRemoved  = "removed"
+)
+
+// Joint withdrawal statuses
+// Ortak çekim durumları
+const (
+	JointWithdrawalPending  = "pending"
+	JointWithdrawalExecuted = "executed"
+	JointWithdrawalRejected = "rejected"
+)
+
+// JointWallet is a wallet shared by several users. The money lives in a Wallet row
+// whose UserID is 0 and whose JointID points back here; members reach it through
+// their JointMember rows.
+//
+// JointWallet birden fazla kullanıcının paylaştığı bir cüzdandır. Para, UserID'si 0
+// olan ve JointID'si buraya işaret eden bir Wallet satırında durur; üyeler ona
+// JointMember satırları üzerinden erişir.
+type JointWallet struct {
+	gorm.Model
+
+	// Name is shown to the members (e.g. "Household")
+	// Name üyelere gösterilir (örn. "Ev")
+	Name string `gorm:"not null" json:"name"`
+
+	// Currency is the currency of the shared wallet
+	// Currency ortak cüzdanın para birimidir
+	Currency string `gorm:"type:text;not null" json:"currency"`
+
+	// CreatedBy is the user who opened the joint wallet (its first owner)
+	// CreatedBy ortak cüzdanı açan kullanıcıdır (ilk sahibi)
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// Withdrawals above ApprovalThreshold need ApprovalsRequired approvals from owners
+	// and spenders, the initiator's included; a nil threshold turns approvals off
+	// ApprovalThreshold üzerindeki çekimler, başlatan dahil sahip ve harcayıcılardan
+	// ApprovalsRequired onay gerektirir; nil eşik onayları kapatır
+	ApprovalThreshold *int64 `json:"approval_threshold,omitempty"`
+	ApprovalsRequired int    `gorm:"not null;default:0" json:"approvals_required"`
+}
+
+// NeedsApproval tells whether a withdrawal or transfer of amount must wait for approvals
+// NeedsApproval bu tutardaki bir çekim veya transferin onay beklemesi gerekip gerekmediğini söyler
+func (j *JointWallet) NeedsApproval(amount int64) bool {
+	return j.ApprovalThreshold != nil && amount > *j.ApprovalThreshold && j.ApprovalsRequired > 1
+}
+
+// MarshalJSON writes amounts as decimal strings in the joint wallet's currency
+// MarshalJSON tutarları ortak cüzdanın para biriminde ondalık metin olarak yazar
+func (j JointWallet) MarshalJSON() ([]byte, error) {
+	type plain JointWallet
+
+	var threshold *Money
+	if j.ApprovalThreshold != nil {
+		money := NewMoney(*j.ApprovalThreshold, j.Currency)
+		threshold = &money
+	}
+
+	return json.Marshal(struct {
+		plain
+		ApprovalThreshold *Money `json:"approval_threshold,omitempty"`
+	}{plain(j), threshold})
+}
+
+// JointMember is one user's membership of a joint wallet, from invitation to removal
+// JointMember bir kullanıcının davetten çıkarılmaya kadar bir ortak cüzdandaki üyeliğidir
+type JointMember struct {
+	gorm.Model
+
+	JointWalletID uint `gorm:"not null;uniqueIndex:idx_joint_member" json:"joint_wallet_id"`
+	UserID        uint `gorm:"not null;uniqueIndex:idx_joint_member;index" json:"user_id"`
+
+	// Role is owner, spender or viewer
+	// Role owner, spender veya viewer'dır
+	Role string `gorm:"type:text;not null" json:"role"`
+
+	// Status moves from invited to active, declined or removed
+	// Status invited'dan active, declined veya removed'a geçer
+	Status string `gorm:"type:text;not null;index" json:"status"`
+
+	// DailyLimit caps what the member may spend per UTC day, in the wallet's
+	// Currency; nil means no cap
+	// DailyLimit üyenin UTC günü başına harcayabileceği tutarı cüzdanın Currency
+	// biriminde sınırlar; nil sınır yok demektir
+	DailyLimit *int64 `json:"daily_limit,omitempty"`
+	Currency   string `gorm:"type:text;not null" json:"currency"`
+
+	// InvitedBy is the owner who sent the invitation; JoinedAt is set on acceptance
+	// InvitedBy daveti gönderen sahiptir; JoinedAt kabulde dolar
+	InvitedBy uint       `gorm:"not null" json:"invited_by"`
+	JoinedAt  *time.Time `json:"joined_at,omitempty"`
+}
+
+// CanSpend tells whether the member may move money out of the joint wallet
+// CanSpend üyenin ortak cüzdandan para çıkarıp çıkaramayacağını söyler
+func (m *JointMember) CanSpend() bool {
+	return m.Status == JointMemberActive && (m.Role == JointRoleOwner || m.Role == JointRoleSpender)
+}
+
+// MarshalJSON writes the daily limit as a decimal string
+// MarshalJSON günlük limiti ondalık metin olarak yazar
+func (m JointMember) MarshalJSON() ([]byte, error) {
+	type plain JointMember
+
+	var limit *Money
+	if m.DailyLimit != nil {
+		money := NewMoney(*m.DailyLimit, m.Currency)
+		limit = &money
+	}
+
+	return json.Marshal(struct {
+		plain
+		DailyLimit *Money `json:"daily_limit,omitempty"`
+	}{plain(m), limit})
+}
+
+// JointWithdrawal is a withdrawal or transfer above the approval threshold waiting for its approvals
+// JointWithdrawal onay eşiğinin üzerinde olup onaylarını bekleyen bir çekim veya transferdir
+type JointWithdrawal struct {
+	gorm.Model
+
+	JointWalletID uint   `gorm:"not null;index" json:"joint_wallet_id"`
+	InitiatedBy   uint   `gorm:"not null" json:"initiated_by"`
+	Amount        int64  `gorm:"not null" json:"amount"`
+	Currency      string `gorm:"type:text;not null" json:"currency"`
+
+	// ToUserID is the recipient when the money goes to a user instead of out of the system
+	// ToUserID para sistem dışına değil bir kullanıcıya gidiyorsa alıcıdır
+	ToUserID *uint `json:"to_user_id,omitempty"`
+
+	// Status is pending until enough approvals execute it or a member rejects it
+	// Status yeterli onay onu gerçekleştirene veya bir üye reddedene kadar pending'dir
+	Status string `gorm:"type:text;not null;index" json:"status"`
+
+	// TransactionID is the withdraw or transfer row written on execution
+	// TransactionID gerçekleştirmede yazılan çekim veya transfer satırıdır
+	TransactionID *uint `json:"transaction_id,omitempty"`
+
+	// RejectedBy is the member who rejected the withdrawal
+	// RejectedBy çekimi reddeden üyedir
+	RejectedBy *uint      `json:"rejected_by,omitempty"`
+	DecidedAt  *time.Time `json:"decided_at,omitempty"`
+
+	Approvals []JointApproval `gorm:"foreignKey:WithdrawalID" json:"approvals"`
+}
+
+// MarshalJSON writes the amount as a decimal string in the withdrawal's currency
+// MarshalJSON tutarı çekimin para biriminde ondalık metin olarak yazar
+func (w JointWithdrawal) MarshalJSON() ([]byte, error) {
+	type plain JointWithdrawal
+	return json.Marshal(struct {
+		plain
+		Amount Money `json:"amount"`
+	}{plain(w), NewMoney(w.Amount, w.Currency)})
+}
+
+// JointApproval is one member's approval of a pending withdrawal
+// JointApproval bir üyenin bekleyen bir çekimi onayıdır
+type JointApproval struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	WithdrawalID uint      `gorm:"not null;uniqueIndex:idx_joint_approval" json:"withdrawal_id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_joint_approval" json:"user_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
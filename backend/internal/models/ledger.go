@@ -0,0 +1,125 @@
+package models
+
+import "gorm.io/gorm"
+
+// Ledger account types
+// Defter hesap türleri
+const (
+	// LedgerAccountWallet is an account backing a user's wallet
+	// LedgerAccountWallet bir kullanıcı cüzdanını temsil eden hesaptır
+	LedgerAccountWallet = "wallet"
+
+	// LedgerAccountSystem is an internal account (deposits, withdrawals, ...)
+	// LedgerAccountSystem dahili bir hesaptır (yatırma, çekme, ...)
+	LedgerAccountSystem = "system"
+
+	// LedgerAccountPot is an account backing a savings pot
+	// LedgerAccountPot bir birikim kumbarasını temsil eden hesaptır
+	LedgerAccountPot = "pot"
+
+	// LedgerAccountEscrow is an account holding the money of one escrow payment
+	// LedgerAccountEscrow tek bir emanet ödemesinin parasını tutan hesaptır
+	LedgerAccountEscrow = "escrow"
+)
+
+// System ledger account codes; the currency is appended (e.g. "system:deposits:USD")
+// Sistem defter hesap kodları; sonuna para birimi eklenir (örn. "system:deposits:USD")
+const (
+	// SystemAccountDeposits is the counter account for money entering the platform
+	// SystemAccountDeposits platforma giren paranın karşı hesabıdır
+	SystemAccountDeposits = "system:deposits"
+
+	// SystemAccountWithdrawals is the counter account for money leaving the platform
+	// SystemAccountWithdrawals platformdan çıkan paranın karşı hesabıdır
+	SystemAccountWithdrawals = "system:withdrawals"
+
+	// SystemAccountOpening holds opening balances of wallets created before the ledger
+	// SystemAccountOpening defterden önce açılmış cüzdanların açılış bakiyelerini tutar
+	SystemAccountOpening = "system:opening"
+
+	// SystemAccountFx is the platform's position in each currency from conversions
+	// SystemAccountFx platformun çevrimlerden doğan para birimi pozisyonudur
+	SystemAccountFx = "system:fx"
+
+	// SystemAccountFees is the house revenue account fees are paid into
+	// SystemAccountFees ücretlerin ödendiği platform gelir hesabıdır
+	SystemAccountFees = "system:fees"
+)
+
+// Journal entry types not covered by transaction types
+// İşlem türleriyle karşılanmayan yevmiye kayıt türleri
+const (
+	// JournalEntryOpeningBalance brings pre-ledger balances into the books
+	// JournalEntryOpeningBalance defter öncesi bakiyeleri deftere alır
+	JournalEntryOpeningBalance = "opening_balance"
+
+	// JournalEntryTransfer moves money between two wallets
+	// JournalEntryTransfer iki cüzdan arasında para taşır
+	JournalEntryTransfer = "transfer"
+
+	// JournalEntryReversal compensates a previous deposit or transfer
+	// JournalEntryReversal önceki bir yatırma veya transferi telafi eder
+	JournalEntryReversal = "reversal"
+
+	// JournalEntryConversion exchanges money between two currencies
+	// JournalEntryConversion iki para birimi arasında para çevirir
+	JournalEntryConversion = "conversion"
+)
+
+// LedgerAccount is a double-entry account. Every wallet has exactly one.
+// LedgerAccount çift taraflı kayıt hesabıdır. Her cüzdanın tam olarak bir hesabı vardır.
+type LedgerAccount struct {
+	gorm.Model
+
+	// Code is the unique, human readable account identifier (e.g. "wallet:12")
+	// Code benzersiz ve okunabilir hesap kimliğidir (örn. "wallet:12")
+	Code string `gorm:"uniqueIndex;not null" json:"code"`
+
+	// Type is "wallet", "pot" or "system"
+	// Type "wallet", "pot" ya da "system" olabilir
+	Type string `gorm:"type:text;not null" json:"type"`
+
+	// WalletID links the account to a wallet (only for wallet accounts)
+	// WalletID hesabı bir cüzdana bağlar (sadece cüzdan hesapları için)
+	WalletID *uint `gorm:"index" json:"wallet_id,omitempty"`
+
+	// Currency of every posting on this account
+	// Bu hesaptaki tüm hareketlerin para birimi
+	Currency string `gorm:"type:text;not null;default:'TRY'" json:"currency"`
+}
+
+// JournalEntry groups postings that describe a single money movement
+// JournalEntry tek bir para hareketini anlatan kayıtları gruplar
+type JournalEntry struct {
+	gorm.Model
+
+	// Type mirrors the transaction type that produced the entry
+	// Type kaydı üreten işlem türünü yansıtır
+	Type string `gorm:"type:text;not null" json:"type"`
+
+	// Description is a free-form note for auditors
+	// Description denetçiler için serbest açıklamadır
+	Description string `json:"description"`
+
+	// Postings must sum to zero per currency
+	// Postings toplamı her para birimi için sıfır olmalıdır
+	Postings []Posting `json:"postings"`
+}
+
+// Posting is one side of a journal entry. Positive amounts increase the account.
+// Posting bir yevmiye kaydının bir tarafıdır. Pozitif tutarlar hesabı artırır.
+type Posting struct {
+	gorm.Model
+
+	// JournalEntryID links the posting to its entry
+	// JournalEntryID kaydı yevmiye girişine bağlar
+	JournalEntryID uint `gorm:"index;not null" json:"journal_entry_id"`
+
+	// AccountID is the ledger account affected
+	// AccountID etkilenen defter hesabıdır
+	AccountID uint `gorm:"index;not null" json:"account_id"`
+
+	// Amount in cents; signed
+	// Amount kuruş cinsinden, işaretli
+	Amount int64 `gorm:"not null" json:"amount"`
+}
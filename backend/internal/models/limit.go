@@ -0,0 +1,59 @@
+package models
+
+import "gorm.io/gorm"
+
+// Operations with limits
+// Limit uygulanan işlemler
+const (
+	LimitOperationWithdraw = "withdraw"
+	LimitOperationTransfer = "transfer"
+)
+
+// LimitOperations lists every operation the limits engine knows
+// LimitOperations limit motorunun bildiği tüm işlemleri listeler
+var LimitOperations = []string{LimitOperationWithdraw, LimitOperationTransfer}
+
+// Limits are the caps of one operation in minor units of the limit currency; 0 means no cap
+// Limits bir işlemin limit para biriminin alt birimleri cinsinden sınırlarıdır; 0 sınır yok demektir
+type Limits struct {
+	PerTransaction int64 `json:"per_transaction"`
+	Daily          int64 `json:"daily"`
+	Monthly        int64 `json:"monthly"`
+}
+
+// LimitOverride replaces the default caps of one operation for one user.
+// A nil field keeps the default; 0 removes the cap.
+//
+// LimitOverride bir kullanıcı için bir işlemin varsayılan sınırlarını değiştirir.
+// nil alan varsayılanı korur; 0 sınırı kaldırır.
+type LimitOverride struct {
+	gorm.Model
+
+	// UserID and Operation identify the override; each pair has at most one row
+	// UserID ve Operation override'ı tanımlar; her çift için en fazla bir satır vardır
+	UserID    uint   `gorm:"uniqueIndex:idx_limit_overrides_user_operation;not null" json:"user_id"`
+	Operation string `gorm:"uniqueIndex:idx_limit_overrides_user_operation;type:text;not null" json:"operation"`
+
+	PerTransaction *int64 `json:"per_transaction"`
+	Daily          *int64 `json:"daily"`
+	Monthly        *int64 `json:"monthly"`
+
+	// SetBy is the admin who made the change
+	// SetBy değişikliği yapan admindir
+	SetBy uint `json:"set_by"`
+}
+
+// Apply returns the defaults with the overridden fields replaced
+// Apply varsayılanları override edilen alanlar değiştirilmiş olarak döndürür
+func (o *LimitOverride) Apply(defaults Limits) Limits {
+	if o.PerTransaction != nil {
+		defaults.PerTransaction = *o.PerTransaction
+	}
+	if o.Daily != nil {
+		defaults.Daily = *o.Daily
+	}
+	if o.Monthly != nil {
+		defaults.Monthly = *o.Monthly
+	}
+	return defaults
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Payment link statuses
+// Ödeme bağlantısı durumları
+const (
+	PaymentLinkActive    = "active"
+	PaymentLinkCompleted = "completed"
+	PaymentLinkCancelled = "cancelled"
+	PaymentLinkExpired   = "expired"
+)
+
+// Merchant turns a user into a business account that collects payments.
+// Payments land in the user's wallets; SettlementCurrency names the wallet that
+// is opened with the merchant and used for links that give no currency.
+//
+// Merchant bir kullanıcıyı ödeme toplayan bir işletme hesabına çevirir.
+// Ödemeler kullanıcının cüzdanlarına düşer; SettlementCurrency işletmeyle birlikte
+// açılan ve para birimi verilmeyen bağlantılarda kullanılan cüzdanı belirtir.
+type Merchant struct {
+	gorm.Model
+
+	// UserID owns the business; a user has at most one merchant account
+	// UserID işletmenin sahibidir; bir kullanıcının en fazla bir işletme hesabı olur
+	UserID uint `gorm:"uniqueIndex;not null" json:"user_id"`
+
+	// BusinessName is shown to payers on checkout
+	// BusinessName ödeme sayfasında ödeyenlere gösterilir
+	BusinessName string `gorm:"not null" json:"business_name"`
+
+	// Website and SupportEmail are optional contact details shown to payers
+	// Website ve SupportEmail ödeyenlere gösterilen isteğe bağlı iletişim bilgileridir
+	Website      string `json:"website,omitempty"`
+	SupportEmail string `json:"support_email,omitempty"`
+
+	// SettlementCurrency is the currency of the merchant's settlement wallet
+	// SettlementCurrency işletmenin tahsilat cüzdanının para birimidir
+	SettlementCurrency string `gorm:"type:text;not null" json:"settlement_currency"`
+
+	// QRToken is the random reference static QR codes of the merchant carry; created with the first code
+	// QRToken işletmenin statik QR kodlarının taşıdığı rastgele referanstır; ilk kodla oluşturulur
+	QRToken *string `gorm:"uniqueIndex" json:"-"`
+}
+
+// PaymentLink is a hosted checkout for a fixed amount. A single-use link completes
+// on its first payment; a multi-use link can be paid until it expires or is cancelled.
+//
+// PaymentLink sabit bir tutar için barındırılan bir ödeme sayfasıdır. Tek kullanımlık
+// bağlantı ilk ödemede tamamlanır; çok kullanımlık bağlantı süresi dolana veya iptal
+// edilene kadar ödenebilir.
+type PaymentLink struct {
+	gorm.Model
+
+	MerchantID uint `gorm:"index;not null" json:"merchant_id"`
+
+	// Code is the public, unguessable part of the link URL
+	// Code bağlantı adresinin herkese açık, tahmin edilemez parçasıdır
+	Code string `gorm:"uniqueIndex;not null" json:"code"`
+
+	// Amount (minor units) and Currency every payment of the link is for
+	// Bağlantının her ödemesinin tutarı (alt birim) ve para birimi
+	Amount   int64  `gorm:"not null" json:"amount"`
+	Currency string `gorm:"type:text;not null" json:"currency"`
+
+	// Description tells the payer what they pay for
+	// Description ödeyene neyin ödendiğini söyler
+	Description string `gorm:"not null" json:"description"`
+
+	// MultiUse links accept any number of payments; others only one
+	// MultiUse bağlantılar istenen sayıda ödeme kabul eder; diğerleri yalnızca bir
+	MultiUse bool `gorm:"not null;default:false" json:"multi_use"`
+
+	// Status follows active → completed | cancelled | expired
+	// Status active → completed | cancelled | expired akışını izler
+	Status string `gorm:"type:text;not null;index" json:"status"`
+
+	// ExpiresAt is when the link stops being payable; nil never expires
+	// ExpiresAt bağlantının artık ödenemeyeceği zamandır; nil hiç dolmaz
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at,omitempty"`
+
+	// PaymentCount and PaidTotal sum up the payments received so far
+	// PaymentCount ve PaidTotal şu ana kadar alınan ödemeleri özetler
+	PaymentCount int   `gorm:"not null;default:0" json:"payment_count"`
+	PaidTotal    int64 `gorm:"not null;default:0" json:"paid_total"`
+}
+
+// MarshalJSON writes amounts as decimal strings in the link's currency
+// MarshalJSON tutarları bağlantının para biriminde ondalık metin olarak yazar
+func (l PaymentLink) MarshalJSON() ([]byte, error) {
+	type plain PaymentLink
+	return json.Marshal(struct {
+		plain
+		Amount    Money `json:"amount"`
+		PaidTotal Money `json:"paid_total"`
+	}{plain(l), NewMoney(l.Amount, l.Currency), NewMoney(l.PaidTotal, l.Currency)})
+}
+
+// LinkPayment is one payment made through a payment link
+// LinkPayment bir ödeme bağlantısı üzerinden yapılan tek bir ödemedir
+type LinkPayment struct {
+	ID         uint   `gorm:"primarykey" json:"id"`
+	LinkID     uint   `gorm:"not null;index" json:"link_id"`
+	MerchantID uint   `gorm:"not null;index" json:"merchant_id"`
+	PayerID    uint   `gorm:"not null;index" json:"payer_id"`
+	Amount     int64  `gorm:"not null" json:"amount"`
+	Currency   string `gorm:"type:text;not null" json:"currency"`
+
+	// TransactionID is the payer's transfer_sent row
+	// TransactionID ödeyenin transfer_sent satırıdır
+	TransactionID uint      `gorm:"not null" json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// MarshalJSON writes the amount as a decimal string in the payment's currency
+// MarshalJSON tutarı ödemenin para biriminde ondalık metin olarak yazar
+func (p LinkPayment) MarshalJSON() ([]byte, error) {
+	type plain LinkPayment
+	return json.Marshal(struct {
+		plain
+		Amount Money `json:"amount"`
+	}{plain(p), NewMoney(p.Amount, p.Currency)})
+}
+
+// NewLinkCode returns a random public code for a payment link (e.g. "pl_3f9a...")
+// NewLinkCode bir ödeme bağlantısı için rastgele herkese açık bir kod döndürür (örn. "pl_3f9a...")
+func NewLinkCode() string {
+	code := make([]byte, 12)
+	_, _ = rand.Read(code)
+	return "pl_" + hex.EncodeToString(code)
+}
+
+// NewQRToken returns a random reference for the QR codes of a user or merchant (e.g. "qr_3f9a...")
+// NewQRToken bir kullanıcının veya işletmenin QR kodları için rastgele bir referans döndürür (örn. "qr_3f9a...")
+func NewQRToken() string {
+	token := make([]byte, 12)
+	_, _ = rand.Read(token)
+	return "qr_" + hex.EncodeToString(token)
+}
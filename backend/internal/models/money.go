@@ -0,0 +1,170 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidAmount is returned for amounts that are not a plain decimal string
+	// ErrInvalidAmount düz ondalık metin olmayan tutarlar için döner
+	ErrInvalidAmount = errors.New(`amount must be a decimal string like "12.34"`)
+
+	// ErrExcessPrecision is returned for amounts with more decimals than their currency has
+	// ErrExcessPrecision para biriminin sahip olduğundan fazla ondalığı olan tutarlar için döner
+	ErrExcessPrecision = errors.New("amount has more decimals than the currency allows")
+
+	// ErrAmountOverflow is returned when an amount or a sum does not fit in int64 minor units
+	// ErrAmountOverflow bir tutar veya toplam int64 alt birime sığmadığında döner
+	ErrAmountOverflow = errors.New("amount is out of range")
+
+	// ErrCurrencyMismatch is returned when money in different currencies is added up
+	// ErrCurrencyMismatch farklı para birimlerindeki paralar toplandığında döner
+	ErrCurrencyMismatch = errors.New("currencies do not match")
+)
+
+// decimalPattern allows "0", "12", "12.34" and "0.05"; no sign, exponent, spaces or leading zeros
+// decimalPattern "0", "12", "12.34" ve "0.05" kabul eder; işaret, üs, boşluk veya baştaki sıfırlar olmaz
+var decimalPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
+// Decimal is an exact, non-negative amount as a client wrote it ("12.34"). It only becomes
+// minor units once its currency is known, which is where excess precision is rejected.
+//
+// Decimal istemcinin yazdığı kesin ve negatif olmayan bir tutardır ("12.34"). Ancak para birimi
+// bilindiğinde alt birime çevrilir; fazla hassasiyet de orada reddedilir.
+type Decimal struct {
+	coefficient int64
+	scale       int
+}
+
+// ParseDecimal reads a decimal string strictly
+// ParseDecimal ondalık bir metni katı kurallarla okur
+func ParseDecimal(value string) (Decimal, error) {
+	if !decimalPattern.MatchString(value) {
+		return Decimal{}, ErrInvalidAmount
+	}
+
+	digits, fraction, _ := strings.Cut(value, ".")
+	coefficient, err := strconv.ParseInt(digits+fraction, 10, 64)
+	if err != nil {
+		return Decimal{}, ErrAmountOverflow
+	}
+	return Decimal{coefficient: coefficient, scale: len(fraction)}, nil
+}
+
+// DecimalOf turns minor units of a currency back into a Decimal
+// DecimalOf bir para biriminin alt birimlerini tekrar Decimal'e çevirir
+func DecimalOf(amount int64, currency Currency) Decimal {
+	return Decimal{coefficient: amount, scale: currency.MinorUnits}
+}
+
+// IsZero reports whether the amount is zero or was not given at all
+// IsZero tutarın sıfır olduğunu veya hiç verilmediğini bildirir
+func (d Decimal) IsZero() bool {
+	return d.coefficient == 0
+}
+
+// Minor converts the amount into minor units of the currency
+// Minor tutarı para biriminin alt birimlerine çevirir
+func (d Decimal) Minor(currency Currency) (int64, error) {
+	if d.scale > currency.MinorUnits {
+		return 0, fmt.Errorf("%w: %s has %d decimals", ErrExcessPrecision, currency.Code, currency.MinorUnits)
+	}
+
+	amount := d.coefficient
+	for i := d.scale; i < currency.MinorUnits; i++ {
+		if amount > math.MaxInt64/10 {
+			return 0, ErrAmountOverflow
+		}
+		amount *= 10
+	}
+	return amount, nil
+}
+
+func (d Decimal) String() string {
+	return Currency{MinorUnits: d.scale}.FormatMinor(d.coefficient)
+}
+
+// UnmarshalJSON accepts a JSON string only; numbers are refused, since a float
+// cannot carry money exactly and a bare integer would be ambiguous
+//
+// UnmarshalJSON sadece JSON metni kabul eder; sayılar reddedilir, çünkü float parayı
+// kesin taşıyamaz ve yalın bir tam sayının anlamı belirsiz olurdu
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Decimal{}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return ErrInvalidAmount
+	}
+	parsed, err := ParseDecimal(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Money is an amount in minor units of a currency. Arithmetic is checked: sums that
+// overflow int64 or mix currencies fail instead of wrapping around.
+//
+// Money bir para biriminin alt birimleri cinsinden tutardır. Aritmetik kontrollüdür:
+// int64'ü taşan veya para birimlerini karıştıran toplamlar sarmak yerine hata verir.
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: currency}
+}
+
+// Add returns m + other
+// Add m + other döndürür
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if (other.Minor > 0 && m.Minor > math.MaxInt64-other.Minor) ||
+		(other.Minor < 0 && m.Minor < math.MinInt64-other.Minor) {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{Minor: m.Minor + other.Minor, Currency: m.Currency}, nil
+}
+
+// Sub returns m - other
+// Sub m - other döndürür
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Minor == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.Add(Money{Minor: -other.Minor, Currency: other.Currency})
+}
+
+// String renders the amount as an exact decimal in its currency's precision ("12.34")
+// String tutarı para biriminin hassasiyetinde kesin bir ondalık olarak yazar ("12.34")
+func (m Money) String() string {
+	currency, err := LookupCurrency(m.Currency)
+	if err != nil {
+		return strconv.FormatInt(m.Minor, 10)
+	}
+	return currency.FormatMinor(m.Minor)
+}
+
+// MarshalJSON writes the decimal string; the currency is always a sibling field in the API
+// MarshalJSON ondalık metni yazar; para birimi API'de her zaman kardeş bir alandır
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
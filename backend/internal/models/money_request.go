@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Money request statuses
+// Para isteği durumları
+const (
+	MoneyRequestPending   = "pending"
+	MoneyRequestPaid      = "paid"
+	MoneyRequestDeclined  = "declined"
+	MoneyRequestCancelled = "cancelled"
+	MoneyRequestExpired   = "expired"
+)
+
+// MoneyRequest asks another user to pay an amount.
+// It stays pending until the payer pays or declines it, the requester cancels it, or it expires.
+//
+// MoneyRequest başka bir kullanıcıdan bir tutar ödemesini ister.
+// Ödeyen ödeyene veya reddedene, isteyen iptal edene ya da süresi dolana kadar pending kalır.
+type MoneyRequest struct {
+	gorm.Model
+
+	// RequesterID receives the money; PayerID is asked to send it
+	// RequesterID parayı alır; PayerID'den göndermesi istenir
+	RequesterID uint `gorm:"index;not null" json:"requester_id"`
+	PayerID     uint `gorm:"index;not null" json:"payer_id"`
+
+	// Amount (minor units) and Currency requested
+	// İstenen tutar (alt birim) ve para birimi
+	Amount   int64  `gorm:"not null" json:"amount"`
+	Currency string `gorm:"type:text;not null" json:"currency"`
+
+	// Note tells the payer what the money is for
+	// Note ödeyene paranın ne için olduğunu söyler
+	Note string `json:"note,omitempty"`
+
+	// Status follows pending → paid | declined | cancelled | expired
+	// Status pending → paid | declined | cancelled | expired akışını izler
+	Status string `gorm:"type:text;not null;index" json:"status"`
+
+	// ExpiresAt is when a pending request stops being payable
+	// ExpiresAt bekleyen isteğin artık ödenemeyeceği zamandır
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+
+	// RespondedAt is when the request left the pending state
+	// RespondedAt isteğin pending durumundan çıktığı zamandır
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+
+	// TransactionID links a paid request to the payer's transfer_sent entry
+	// TransactionID ödenmiş isteği ödeyenin transfer_sent kaydına bağlar
+	TransactionID *uint `json:"transaction_id,omitempty"`
+}
+
+// MarshalJSON writes the amount as a decimal string in the request's currency
+// MarshalJSON tutarı isteğin para biriminde ondalık metin olarak yazar
+func (r MoneyRequest) MarshalJSON() ([]byte, error) {
+	type plain MoneyRequest
+	return json.Marshal(struct {
+		plain
+		Amount Money `json:"amount"`
+	}{plain(r), NewMoney(r.Amount, r.Currency)})
+}
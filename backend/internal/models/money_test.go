@@ -0,0 +1,144 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestParseDecimal checks which decimal strings are accepted and which are refused
+// TestParseDecimal hangi ondalık metinlerin kabul edilip hangilerinin reddedildiğini kontrol eder
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		err   error
+	}{
+		{value: "0", want: "0"},
+		{value: "12", want: "12"},
+		{value: "12.34", want: "12.34"},
+		{value: "0.05", want: "0.05"},
+		{value: "12.340", want: "12.340"},
+		{value: "9223372036854775807", want: "9223372036854775807"},
+		{value: "", err: ErrInvalidAmount},
+		{value: "012", err: ErrInvalidAmount},
+		{value: "00.5", err: ErrInvalidAmount},
+		{value: "-1", err: ErrInvalidAmount},
+		{value: "+1", err: ErrInvalidAmount},
+		{value: "1e3", err: ErrInvalidAmount},
+		{value: " 1", err: ErrInvalidAmount},
+		{value: "1.", err: ErrInvalidAmount},
+		{value: ".5", err: ErrInvalidAmount},
+		{value: "1,5", err: ErrInvalidAmount},
+		{value: "9223372036854775808", err: ErrAmountOverflow},
+		{value: "92233720368547758.08", err: ErrAmountOverflow},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDecimal(tt.value)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseDecimal(%q) error = %v, want %v", tt.value, err, tt.err)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestDecimalMinor converts decimals into minor units of currencies with 0, 2 and 3 decimals
+// TestDecimalMinor ondalıkları 0, 2 ve 3 ondalıklı para birimlerinin alt birimlerine çevirir
+func TestDecimalMinor(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     int64
+		err      error
+	}{
+		{value: "12.34", currency: "TRY", want: 1234},
+		{value: "12", currency: "TRY", want: 1200},
+		{value: "12.3", currency: "TRY", want: 1230},
+		{value: "12.340", currency: "TRY", err: ErrExcessPrecision},
+		{value: "12.345", currency: "KWD", want: 12345},
+		{value: "1500", currency: "JPY", want: 1500},
+		{value: "1500.5", currency: "JPY", err: ErrExcessPrecision},
+		{value: "92233720368547758.07", currency: "TRY", want: math.MaxInt64},
+		{value: "92233720368547759", currency: "TRY", err: ErrAmountOverflow},
+	}
+
+	for _, tt := range tests {
+		currency, err := LookupCurrency(tt.currency)
+		if err != nil {
+			t.Fatalf("lookup %s: %v", tt.currency, err)
+		}
+		value, err := ParseDecimal(tt.value)
+		if err != nil {
+			t.Fatalf("ParseDecimal(%q): %v", tt.value, err)
+		}
+
+		got, err := value.Minor(currency)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s %s: Minor error = %v, want %v", tt.value, tt.currency, err, tt.err)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%s %s: Minor = %d, want %d", tt.value, tt.currency, got, tt.want)
+		}
+	}
+}
+
+// TestMoneyArithmetic checks that Add and Sub fail at the int64 edges and across currencies
+// TestMoneyArithmetic Add ve Sub'ın int64 sınırlarında ve para birimleri arasında hata verdiğini kontrol eder
+func TestMoneyArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(a, b Money) (Money, error)
+		a, b Money
+		want int64
+		err  error
+	}{
+		{name: "add", op: Money.Add, a: NewMoney(1050, "TRY"), b: NewMoney(5, "TRY"), want: 1055},
+		{name: "add negative", op: Money.Add, a: NewMoney(1050, "TRY"), b: NewMoney(-1100, "TRY"), want: -50},
+		{name: "add up to the max", op: Money.Add, a: NewMoney(math.MaxInt64-1, "TRY"), b: NewMoney(1, "TRY"), want: math.MaxInt64},
+		{name: "add past the max", op: Money.Add, a: NewMoney(math.MaxInt64, "TRY"), b: NewMoney(1, "TRY"), err: ErrAmountOverflow},
+		{name: "add past the min", op: Money.Add, a: NewMoney(math.MinInt64, "TRY"), b: NewMoney(-1, "TRY"), err: ErrAmountOverflow},
+		{name: "add other currency", op: Money.Add, a: NewMoney(1, "TRY"), b: NewMoney(1, "USD"), err: ErrCurrencyMismatch},
+		{name: "sub", op: Money.Sub, a: NewMoney(1050, "TRY"), b: NewMoney(1100, "TRY"), want: -50},
+		{name: "sub down to the min", op: Money.Sub, a: NewMoney(math.MinInt64+1, "TRY"), b: NewMoney(1, "TRY"), want: math.MinInt64},
+		{name: "sub past the min", op: Money.Sub, a: NewMoney(math.MinInt64, "TRY"), b: NewMoney(1, "TRY"), err: ErrAmountOverflow},
+		{name: "sub the min", op: Money.Sub, a: NewMoney(0, "TRY"), b: NewMoney(math.MinInt64, "TRY"), err: ErrAmountOverflow},
+		{name: "sub other currency", op: Money.Sub, a: NewMoney(1, "TRY"), b: NewMoney(1, "EUR"), err: ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.op(tt.a, tt.b)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err == nil && (got.Minor != tt.want || got.Currency != tt.a.Currency) {
+			t.Errorf("%s: got %d %s, want %d %s", tt.name, got.Minor, got.Currency, tt.want, tt.a.Currency)
+		}
+	}
+}
+
+// TestMoneyString renders amounts in the precision of their currency
+// TestMoneyString tutarları para birimlerinin hassasiyetinde yazar
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: NewMoney(1234, "TRY"), want: "12.34"},
+		{money: NewMoney(5, "TRY"), want: "0.05"},
+		{money: NewMoney(-5, "TRY"), want: "-0.05"},
+		{money: NewMoney(1500, "JPY"), want: "1500"},
+		{money: NewMoney(12345, "KWD"), want: "12.345"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("%d %s = %q, want %q", tt.money.Minor, tt.money.Currency, got, tt.want)
+		}
+	}
+}
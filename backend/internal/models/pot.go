@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Pot statuses
+// Kumbara durumları
+const (
+	PotStatusOpen   = "open"
+	PotStatusClosed = "closed"
+)
+
+// Pot is a named savings pot under one of the user's wallets. Money in a pot is
+// not part of the wallet balance, so it cannot be spent until it is moved back.
+//
+// Pot kullanıcının cüzdanlarından birinin altındaki isimli bir birikim kumbarasıdır.
+// Kumbaradaki para cüzdan bakiyesinin parçası değildir, geri taşınana kadar harcanamaz.
+type Pot struct {
+	gorm.Model
+
+	// UserID owns the pot; Currency is the currency of the wallet it belongs to
+	// UserID kumbaranın sahibidir; Currency ait olduğu cüzdanın para birimidir
+	UserID   uint   `gorm:"index;not null" json:"user_id"`
+	Currency string `gorm:"type:text;not null" json:"currency"`
+
+	// Name is shown to the user (e.g. "Holiday")
+	// Name kullanıcıya gösterilir (örn. "Tatil")
+	Name string `gorm:"not null" json:"name"`
+
+	// Balance is the money set aside, in minor units
+	// Balance kenara ayrılan paradır, alt birim cinsinden
+	Balance int64 `gorm:"not null;default:0" json:"balance"`
+
+	// TargetAmount and TargetDate describe the optional savings goal
+	// TargetAmount ve TargetDate isteğe bağlı birikim hedefini tanımlar
+	TargetAmount *int64     `json:"target_amount,omitempty"`
+	TargetDate   *time.Time `json:"target_date,omitempty"`
+
+	// Status is open until the pot is closed; closed pots are kept for history
+	// Status kumbara kapatılana kadar open'dır; kapalı kumbaralar geçmiş için saklanır
+	Status   string     `gorm:"type:text;not null;index" json:"status"`
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
+
+	// Version is bumped on every balance change (optimistic locking)
+	// Version her bakiye değişikliğinde artar (iyimser kilitleme)
+	Version int64 `gorm:"not null;default:0" json:"-"`
+}
+
+// MarshalJSON writes amounts as decimal strings in the pot's currency
+// MarshalJSON tutarları kumbaranın para biriminde ondalık metin olarak yazar
+func (p Pot) MarshalJSON() ([]byte, error) {
+	type plain Pot
+
+	var target *Money
+	if p.TargetAmount != nil {
+		money := NewMoney(*p.TargetAmount, p.Currency)
+		target = &money
+	}
+
+	return json.Marshal(struct {
+		plain
+		Balance      Money  `json:"balance"`
+		TargetAmount *Money `json:"target_amount,omitempty"`
+	}{plain(p), NewMoney(p.Balance, p.Currency), target})
+}
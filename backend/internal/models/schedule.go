@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schedule frequencies
+// Zamanlama sıklıkları
+const (
+	ScheduleOnce    = "once"
+	ScheduleDaily   = "daily"
+	ScheduleWeekly  = "weekly"
+	ScheduleMonthly = "monthly"
+)
+
+// Schedule statuses
+// Zamanlama durumları
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusFailed    = "failed"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+// ScheduledTransfer is a future-dated or recurring transfer run by the scheduler
+// ScheduledTransfer zamanlayıcının çalıştırdığı ileri tarihli veya tekrarlayan transferdir
+type ScheduledTransfer struct {
+	gorm.Model
+
+	// UserID is the sender; ToUserID the recipient
+	// UserID gönderen, ToUserID alıcıdır
+	UserID   uint `gorm:"index;not null" json:"user_id"`
+	ToUserID uint `gorm:"not null" json:"to_user_id"`
+
+	// Amount (minor units) and Currency of every run
+	// Her çalıştırmanın tutarı (alt birim) ve para birimi
+	Amount   int64  `gorm:"not null" json:"amount"`
+	Currency string `gorm:"type:text;not null" json:"currency"`
+
+	// Note is a free-form label such as "rent"
+	// Note "kira" gibi serbest bir etikettir
+	Note string `json:"note,omitempty"`
+
+	// Frequency is once, daily, weekly or monthly; runs are counted from StartAt
+	// Frequency once, daily, weekly veya monthly'dir; çalıştırmalar StartAt'tan sayılır
+	Frequency string    `gorm:"type:text;not null" json:"frequency"`
+	StartAt   time.Time `gorm:"not null" json:"start_at"`
+
+	// EndAt and MaxRuns optionally stop a recurring schedule (0 = no limit)
+	// EndAt ve MaxRuns tekrarlayan zamanlamayı isteğe bağlı olarak durdurur (0 = sınırsız)
+	EndAt   *time.Time `json:"end_at,omitempty"`
+	MaxRuns int        `gorm:"not null;default:0" json:"max_runs"`
+
+	// NextRunAt is when the scheduler runs it next (including retries)
+	// NextRunAt zamanlayıcının bir sonraki çalıştırma zamanıdır (tekrar denemeler dahil)
+	NextRunAt time.Time `gorm:"not null;index" json:"next_run_at"`
+
+	// RunCount is the number of successful runs
+	// RunCount başarılı çalıştırma sayısıdır
+	RunCount int `gorm:"not null;default:0" json:"run_count"`
+
+	// Attempts counts failed tries of the current run
+	// Attempts mevcut çalıştırmanın başarısız deneme sayısıdır
+	Attempts int `gorm:"not null;default:0" json:"attempts"`
+
+	// LastRunAt and LastError describe the most recent try
+	// LastRunAt ve LastError en son denemeyi anlatır
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+
+	// Status is active until the schedule completes, fails or is cancelled
+	// Status zamanlama tamamlanana, başarısız olana veya iptal edilene kadar active'dir
+	Status string `gorm:"type:text;not null;index" json:"status"`
+
+	// Version guards against the scheduler and the owner changing it at the same time
+	// Version zamanlayıcı ile sahibinin aynı anda değiştirmesine karşı korur
+	Version int64 `gorm:"not null;default:0" json:"-"`
+}
+
+// MarshalJSON writes the amount as a decimal string in the schedule's currency
+// MarshalJSON tutarı zamanlamanın para biriminde ondalık metin olarak yazar
+func (s ScheduledTransfer) MarshalJSON() ([]byte, error) {
+	type plain ScheduledTransfer
+	return json.Marshal(struct {
+		plain
+		Amount Money `json:"amount"`
+	}{plain(s), NewMoney(s.Amount, s.Currency)})
+}
@@ -1,26 +1,73 @@
 package models
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
 	"gorm.io/gorm"
 )
 
+// Transaction types
+// İşlem türleri
+const (
+	TransactionTypeDeposit              = "deposit"
+	TransactionTypeWithdraw             = "withdraw"
+	TransactionTypeTransferSent         = "transfer_sent"
+	TransactionTypeTransferReceived     = "transfer_received"
+	TransactionTypeReversalDebit        = "reversal_debit"
+	TransactionTypeReversalCredit       = "reversal_credit"
+	TransactionTypeConversionOut        = "conversion_out"
+	TransactionTypeConversionIn         = "conversion_in"
+	TransactionTypeHoldPlaced           = "hold_placed"
+	TransactionTypeHoldCaptured         = "hold_captured"
+	TransactionTypeCaptureReceived      = "capture_received"
+	TransactionTypeHoldReleased         = "hold_released"
+	TransactionTypeFee                  = "fee"
+	TransactionTypePotIn                = "pot_in"
+	TransactionTypePotOut               = "pot_out"
+	TransactionTypeJointContribution    = "joint_contribution"
+	TransactionTypeContributionReceived = "contribution_received"
+	TransactionTypeEscrowFunded         = "escrow_funded"
+	TransactionTypeEscrowPending        = "escrow_pending"
+	TransactionTypeEscrowReleased       = "escrow_released"
+	TransactionTypeEscrowRefunded       = "escrow_refunded"
+)
+
 // Transaction represents a single wallet operation
 // Transaction, tek bir cüzdan işlemini temsil eder
 type Transaction struct {
 	gorm.Model
 
-	// UserID is the owner of the wallet performing the operation
-	// UserID, işlemi yapan cüzdan sahibini belirtir
+	// UserID is the owner of the wallet performing the operation (0 for a joint wallet)
+	// UserID, işlemi yapan cüzdan sahibini belirtir (ortak cüzdan için 0)
 	UserID uint `json:"user_id"`
 
+	// JointID is set on the rows of a joint wallet; with UserID and Currency it names the wallet
+	// JointID ortak cüzdanın satırlarında doludur; UserID ve Currency ile cüzdanı belirtir
+	JointID uint `gorm:"not null;default:0;index" json:"joint_id,omitempty"`
+
+	// InitiatedBy is the member who started a joint wallet operation
+	// InitiatedBy ortak cüzdan işlemini başlatan üyedir
+	InitiatedBy *uint `json:"initiated_by,omitempty"`
+
 	// Type indicates transaction category: deposit, withdraw, transfer
 	// Type işlemin türünü belirtir: deposit, withdraw, transfer
 	Type string `gorm:"type:text;not null" json:"type"`
 
-	// Amount is stored in cents for accuracy
-	// Amount, hassasiyet için kuruş cinsinden saklanır
+	// Amount is stored in minor units (cents) for accuracy
+	// Amount, hassasiyet için alt birim (kuruş) cinsinden saklanır
 	Amount int64 `json:"amount"`
 
+	// Currency is the ISO 4217 code of Amount and BalanceAfter
+	// Currency, Amount ve BalanceAfter değerlerinin ISO 4217 kodudur
+	Currency string `gorm:"type:text;not null;default:'TRY'" json:"currency"`
+
+	// Rate is the exchange rate applied when the money changed currency
+	// Rate para birimi değiştiğinde uygulanan döviz kurudur
+	Rate string `json:"rate,omitempty"`
+
 	// TargetUserID is used only for transfer operations
 	// TargetUserID sadece transfer işlemlerinde kullanılır
 	TargetUserID *uint `json:"target_user_id,omitempty"`
@@ -28,4 +75,185 @@ type Transaction struct {
 	// BalanceAfter represents user's balance after the transaction
 	// BalanceAfter, işlem sonrası kullanıcının bakiyesini gösterir
 	BalanceAfter int64 `json:"balance_after"`
+
+	// ReversalOfID links a compensating entry to the transaction it reverses
+	// ReversalOfID telafi kaydını geri aldığı işleme bağlar
+	ReversalOfID *uint `gorm:"index" json:"reversal_of_id,omitempty"`
+
+	// ReversedAmount is how much of this transaction has been reversed so far
+	// ReversedAmount bu işlemin şu ana kadar ne kadarının geri alındığını gösterir
+	ReversedAmount int64 `gorm:"not null;default:0" json:"reversed_amount"`
+
+	// HoldID links authorize, capture and release entries to their hold
+	// HoldID provizyon, tahsil ve serbest bırakma kayıtlarını provizyona bağlar
+	HoldID *uint `gorm:"index" json:"hold_id,omitempty"`
+
+	// PotID links money moved into or out of a savings pot to the pot
+	// PotID bir birikim kumbarasına giren veya çıkan parayı kumbaraya bağlar
+	PotID *uint `gorm:"index" json:"pot_id,omitempty"`
+
+	// EscrowID links the funding, release and refund of an escrow payment to it
+	// EscrowID bir emanet ödemesinin fonlama, serbest bırakma ve iade kayıtlarını ona bağlar
+	EscrowID *uint `gorm:"index" json:"escrow_id,omitempty"`
+
+	// Purpose tells what a transfer paid for; it is empty for a plain transfer between users
+	// Purpose bir transferin neyi ödediğini söyler; kullanıcılar arası düz bir transferde boştur
+	Purpose string `json:"purpose,omitempty"`
+
+	// CorrelationID is shared by every row one money movement writes (both sides of a
+	// transfer, the legs of a conversion, a capture or a reversal)
+	// CorrelationID tek bir para hareketinin yazdığı tüm satırlarda ortaktır (transferin iki
+	// tarafı, dönüşümün iki bacağı, tahsil veya geri alma)
+	CorrelationID string `gorm:"index" json:"correlation_id,omitempty"`
+
+	// Reason explains why a reversal was made or a hold was released; pot moves carry the pot name
+	// Reason geri alma veya provizyon serbest bırakma nedenini açıklar; kumbara hareketleri kumbara adını taşır
+	Reason string `json:"reason,omitempty"`
+
+	// PrevHash is the Hash of the previous entry of the same wallet ("" for the first)
+	// PrevHash aynı cüzdanın önceki kaydının Hash değeridir (ilk kayıt için "")
+	PrevHash string `json:"prev_hash"`
+
+	// Hash is a SHA-256 over the canonical contents and PrevHash, so editing
+	// or deleting any row breaks the chain from that row on
+	// Hash kanonik içerik ve PrevHash üzerinden SHA-256'dır; herhangi bir satırı
+	// düzenlemek veya silmek zinciri o satırdan itibaren kırar
+	Hash string `json:"hash"`
+}
+
+// canonicalTransaction fixes the fields and their order that go into Hash.
+// ReversedAmount is left out because it legitimately changes after the row is written.
+// Fields added later are omitted when empty, so rows written before them keep their hash.
+//
+// canonicalTransaction Hash'e giren alanları ve sıralarını sabitler.
+// ReversedAmount dahil edilmez çünkü satır yazıldıktan sonra meşru şekilde değişir.
+// Sonradan eklenen alanlar boşken dahil edilmez, böylece daha önce yazılan satırların hash'i değişmez.
+type canonicalTransaction struct {
+	UserID       uint   `json:"user_id"`
+	Type         string `json:"type"`
+	Amount       int64  `json:"amount"`
+	Currency     string `json:"currency"`
+	Rate         string `json:"rate"`
+	TargetUserID *uint  `json:"target_user_id"`
+	BalanceAfter int64  `json:"balance_after"`
+	ReversalOfID *uint  `json:"reversal_of_id"`
+	HoldID       *uint  `json:"hold_id"`
+	Reason       string `json:"reason"`
+	CreatedAt    int64  `json:"created_at"`
+	PrevHash     string `json:"prev_hash"`
+	Purpose      string `json:"purpose,omitempty"`
+
+	CorrelationID string `json:"correlation_id,omitempty"`
+	PotID         *uint  `json:"pot_id,omitempty"`
+	JointID       uint   `json:"joint_id,omitempty"`
+	InitiatedBy   *uint  `json:"initiated_by,omitempty"`
+	EscrowID      *uint  `json:"escrow_id,omitempty"`
+}
+
+// ComputeHash returns the chain hash of the transaction as it is now
+// ComputeHash işlemin mevcut haliyle zincir hash değerini döndürür
+func (t *Transaction) ComputeHash() string {
+	canonical, _ := json.Marshal(canonicalTransaction{
+		UserID:       t.UserID,
+		Type:         t.Type,
+		Amount:       t.Amount,
+		Currency:     t.Currency,
+		Rate:         t.Rate,
+		TargetUserID: t.TargetUserID,
+		BalanceAfter: t.BalanceAfter,
+		ReversalOfID: t.ReversalOfID,
+		HoldID:       t.HoldID,
+		Reason:       t.Reason,
+		CreatedAt:    t.CreatedAt.UnixMicro(),
+		PrevHash:     t.PrevHash,
+		Purpose:      t.Purpose,
+
+		CorrelationID: t.CorrelationID,
+		PotID:         t.PotID,
+		JointID:       t.JointID,
+		InitiatedBy:   t.InitiatedBy,
+		EscrowID:      t.EscrowID,
+	})
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// Transfer purposes. Only plain transfers, which have none, can be reversed by their sender;
+// the others settled something that a reversal would leave looking paid.
+//
+// Transfer amaçları. Sadece amacı olmayan düz transferler göndericisi tarafından geri alınabilir;
+// diğerleri bir şeyi kapatmıştır ve geri alma onu ödenmiş gibi bırakırdı.
+const (
+	TransferPurposeMoneyRequest = "money_request"
+	TransferPurposeSettlement   = "settlement"
+	TransferPurposePaymentLink  = "payment_link"
+	TransferPurposeQRPayment    = "qr_payment"
+)
+
+// Transaction statuses, derived from how much of the amount was reversed
+// İşlem durumları, tutarın ne kadarının geri alındığından türetilir
+const (
+	TransactionStatusCompleted         = "completed"
+	TransactionStatusPartiallyReversed = "partially_reversed"
+	TransactionStatusReversed          = "reversed"
+)
+
+// NewCorrelationID returns a random ID to tag the rows of one money movement
+// NewCorrelationID bir para hareketinin satırlarını etiketlemek için rastgele bir ID döndürür
+func NewCorrelationID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Status tells whether the transaction still stands or was (partly) reversed
+// Status işlemin geçerli olup olmadığını veya (kısmen) geri alındığını söyler
+func (t *Transaction) Status() string {
+	switch {
+	case t.ReversedAmount <= 0:
+		return TransactionStatusCompleted
+	case t.ReversedAmount < t.Amount:
+		return TransactionStatusPartiallyReversed
+	}
+	return TransactionStatusReversed
+}
+
+// BalanceDelta returns how the transaction changed the wallet balance.
+// The boolean is false for types the wallet balance does not know about.
+//
+// BalanceDelta işlemin cüzdan bakiyesini nasıl değiştirdiğini döndürür.
+// Cüzdan bakiyesinin bilmediği türler için boolean false döner.
+func (t *Transaction) BalanceDelta() (int64, bool) {
+	switch t.Type {
+	case TransactionTypeDeposit, TransactionTypeTransferReceived, TransactionTypeReversalCredit,
+		TransactionTypeConversionIn, TransactionTypeCaptureReceived, TransactionTypePotOut,
+		TransactionTypeContributionReceived, TransactionTypeEscrowReleased, TransactionTypeEscrowRefunded:
+		return t.Amount, true
+	case TransactionTypeWithdraw, TransactionTypeTransferSent, TransactionTypeReversalDebit,
+		TransactionTypeConversionOut, TransactionTypeHoldCaptured, TransactionTypeFee, TransactionTypePotIn,
+		TransactionTypeJointContribution, TransactionTypeEscrowFunded:
+		return -t.Amount, true
+	case TransactionTypeHoldPlaced, TransactionTypeHoldReleased:
+		// Holds change the available balance only
+		// Provizyonlar sadece kullanılabilir bakiyeyi değiştirir
+		return 0, true
+	case TransactionTypeEscrowPending:
+		// Tells the seller money is waiting in escrow; their balance moves on release
+		// Satıcıya emanette bekleyen para olduğunu bildirir; bakiyesi serbest bırakmada değişir
+		return 0, true
+	}
+	return 0, false
+}
+
+// MarshalJSON writes amounts as decimal strings in the transaction's currency
+// MarshalJSON tutarları işlemin para biriminde ondalık metin olarak yazar
+func (t Transaction) MarshalJSON() ([]byte, error) {
+	type plain Transaction
+	return json.Marshal(struct {
+		plain
+		Amount         Money `json:"amount"`
+		BalanceAfter   Money `json:"balance_after"`
+		ReversedAmount Money `json:"reversed_amount"`
+	}{plain(t), NewMoney(t.Amount, t.Currency), NewMoney(t.BalanceAfter, t.Currency), NewMoney(t.ReversedAmount, t.Currency)})
 }
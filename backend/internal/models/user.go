@@ -1,6 +1,9 @@
 package models
 
 import (
+	"strings"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -18,4 +21,40 @@ type User struct {
 	// Password hash stored in DB; never exposed in JSON (json:"-").
 	// Şifre hash’i DB’de saklanır; JSON’da asla gösterilmez (json:"-").
 	PasswordHash string `gorm:"not null" json:"-"`
+
+	// IsAdmin grants access to /admin endpoints; set directly in the DB.
+	// IsAdmin /admin endpoint'lerine erişim verir; doğrudan DB üzerinden atanır.
+	IsAdmin bool `gorm:"not null;default:false" json:"-"`
+
+	// Handle is a unique public name others can send money to (stored without "@")
+	// Handle başkalarının para gönderebileceği benzersiz herkese açık addır ("@" olmadan saklanır)
+	Handle *string `gorm:"uniqueIndex" json:"handle,omitempty"`
+
+	// Phone is the verified E.164 number; only verified numbers can receive money
+	// Phone doğrulanmış E.164 numarasıdır; sadece doğrulanmış numaralar para alabilir
+	Phone *string `gorm:"uniqueIndex" json:"phone,omitempty"`
+
+	// QRToken is the random reference QR codes paying the user carry; created with the first code
+	// QRToken kullanıcıya ödeme yapan QR kodlarının taşıdığı rastgele referanstır; ilk kodla oluşturulur
+	QRToken *string `gorm:"uniqueIndex" json:"-"`
+
+	// PendingPhone waits for the code sent to it before it becomes Phone
+	// PendingPhone, Phone olmadan önce kendisine gönderilen kodu bekler
+	PendingPhone string `json:"-"`
+
+	// PhoneCodeHash, PhoneCodeExpiresAt and PhoneCodeAttempts track the open verification
+	// PhoneCodeHash, PhoneCodeExpiresAt ve PhoneCodeAttempts açık doğrulamayı takip eder
+	PhoneCodeHash      string     `json:"-"`
+	PhoneCodeExpiresAt *time.Time `json:"-"`
+	PhoneCodeAttempts  int        `gorm:"not null;default:0" json:"-"`
+}
+
+// DisplayName is what other users see instead of the full email (e.g. "k***@example.com")
+// DisplayName diğer kullanıcıların tam e-posta yerine gördüğü addır (örn. "k***@example.com")
+func (u *User) DisplayName() string {
+	local, domain, found := strings.Cut(u.Email, "@")
+	if !found || local == "" {
+		return "***"
+	}
+	return string([]rune(local)[:1]) + "***@" + domain
 }
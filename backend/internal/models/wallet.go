@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
 
 // Wallet represents a user's wallet record stored in database
 // Wallet, kullanıcının veritabanındaki cüzdan kaydını temsil eder
@@ -9,11 +13,51 @@ type Wallet struct {
 	// GORM temel alanları: ID, CreatedAt, UpdatedAt, DeletedAt
 	gorm.Model
 
-	// UserID links wallet to a specific user. One user = one wallet.
-	// UserID, cüzdanı bir kullanıcıya bağlar. Bir kullanıcı = bir cüzdan.
-	UserID uint `gorm:"uniqueIndex" json:"user_id"`
+	// UserID links wallet to a specific user. One wallet per user and currency.
+	// UserID, cüzdanı bir kullanıcıya bağlar. Kullanıcı ve para birimi başına bir cüzdan.
+	UserID uint `gorm:"uniqueIndex:idx_wallet_holder" json:"user_id"`
+
+	// JointID is set on the wallet of a joint wallet, whose UserID is 0 because it
+	// belongs to its members; personal wallets keep 0
+	// JointID ortak cüzdanın cüzdanında dolu olur; üyelerine ait olduğu için UserID 0'dır,
+	// kişisel cüzdanlarda 0 kalır
+	JointID uint `gorm:"not null;default:0;uniqueIndex:idx_wallet_holder" json:"joint_id,omitempty"`
+
+	// Currency is the ISO 4217 code of the wallet
+	// Currency cüzdanın ISO 4217 para birimi kodudur
+	Currency string `gorm:"type:text;not null;default:'TRY';uniqueIndex:idx_wallet_holder" json:"currency"`
 
-	// Balance stores money in integer cents, not floating point.
-	// Balance, para değerini float değil kuruş bazlı integer olarak saklar.
+	// Balance stores money in integer minor units (cents), not floating point.
+	// Balance, para değerini float değil alt birim (kuruş) bazlı integer olarak saklar.
 	Balance int64 `json:"balance"`
+
+	// Held is the part of Balance reserved by open authorization holds
+	// Held, Balance'ın açık provizyonlar tarafından ayrılmış kısmıdır
+	Held int64 `gorm:"not null;default:0" json:"held"`
+
+	// Frozen wallets reject every balance change until an admin unfreezes them
+	// Dondurulmuş cüzdanlar bir admin çözene kadar tüm bakiye değişikliklerini reddeder
+	Frozen       bool   `gorm:"not null;default:false" json:"frozen"`
+	FrozenReason string `json:"frozen_reason,omitempty"`
+
+	// Version is bumped on every balance change (optimistic locking)
+	// Version her bakiye değişikliğinde artar (iyimser kilitleme)
+	Version int64 `gorm:"not null;default:0" json:"-"`
+}
+
+// Available is the balance that can still be spent (ledger balance minus holds)
+// Available hâlâ harcanabilecek bakiyedir (defter bakiyesi eksi provizyonlar)
+func (w *Wallet) Available() int64 {
+	return w.Balance - w.Held
+}
+
+// MarshalJSON writes balances as decimal strings in the wallet's currency
+// MarshalJSON bakiyeleri cüzdanın para biriminde ondalık metin olarak yazar
+func (w Wallet) MarshalJSON() ([]byte, error) {
+	type plain Wallet
+	return json.Marshal(struct {
+		plain
+		Balance Money `json:"balance"`
+		Held    Money `json:"held"`
+	}{plain(w), NewMoney(w.Balance, w.Currency), NewMoney(w.Held, w.Currency)})
 }
@@ -0,0 +1,63 @@
+package rates
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrRateUnavailable is returned when a provider has no rate for a currency pair
+// ErrRateUnavailable sağlayıcının bir para birimi çifti için kuru olmadığında döner
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// Provider interface defines where exchange rates come from
+// Provider arayüzü döviz kurlarının nereden geldiğini tanımlar
+//
+// Why do we use this?
+// The static table works offline; a live market feed can be swapped in later.
+// Statik tablo çevrimdışı çalışır; ileride canlı bir piyasa kaynağı takılabilir.
+type Provider interface {
+	// MidRate returns how many units of `to` one unit of `from` buys, before any spread
+	// MidRate bir birim `from` ile kaç birim `to` alındığını döndürür (marj hariç)
+	MidRate(from, to string) (*big.Rat, error)
+}
+
+// Convert turns an amount in minor units of one currency into minor units of another.
+// The result is rounded down so the platform never pays out more than the rate allows.
+//
+// Convert bir para biriminin alt birimlerindeki tutarı diğerinin alt birimlerine çevirir.
+// Sonuç aşağı yuvarlanır, böylece platform kurun izin verdiğinden fazlasını ödemez.
+func Convert(amount int64, rate *big.Rat, fromMinorUnits, toMinorUnits int) int64 {
+	value := new(big.Rat).SetInt64(amount)
+	value.Mul(value, rate)
+	value.Mul(value, new(big.Rat).SetInt(pow10(toMinorUnits)))
+	value.Quo(value, new(big.Rat).SetInt(pow10(fromMinorUnits)))
+
+	return new(big.Int).Quo(value.Num(), value.Denom()).Int64()
+}
+
+// ApplySpread lowers a mid rate by the given basis points (50 = 0.5%)
+// ApplySpread orta kuru verilen baz puan kadar düşürür (50 = %0.5)
+func ApplySpread(mid *big.Rat, spreadBps int64) *big.Rat {
+	factor := big.NewRat(10000-spreadBps, 10000)
+	return new(big.Rat).Mul(mid, factor)
+}
+
+// Format renders a rate with fixed precision for storage and display
+// Format kuru saklama ve gösterim için sabit hassasiyetle yazar
+func Format(rate *big.Rat) string {
+	return rate.FloatString(8)
+}
+
+// Parse reads a rate stored by Format
+// Parse, Format ile saklanmış bir kuru okur
+func Parse(rate string) (*big.Rat, error) {
+	value, ok := new(big.Rat).SetString(rate)
+	if !ok || value.Sign() <= 0 {
+		return nil, errors.New("invalid exchange rate")
+	}
+	return value, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,82 @@
+package rates
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// defaultTable is used when no rates file is configured (base USD)
+// defaultTable kur dosyası ayarlanmadığında kullanılır (baz USD)
+var defaultTable = rateTable{
+	Base: "USD",
+	Rates: map[string]string{
+		"USD": "1",
+		"TRY": "32.50",
+		"EUR": "0.92",
+		"GBP": "0.79",
+		"CHF": "0.88",
+		"JPY": "150",
+		"KWD": "0.307",
+	},
+}
+
+// rateTable is the JSON layout of a rates file:
+// {"base": "USD", "rates": {"TRY": "32.50", "EUR": "0.92"}}
+//
+// rateTable kur dosyasının JSON yapısıdır
+type rateTable struct {
+	Base  string            `json:"base"`
+	Rates map[string]string `json:"rates"`
+}
+
+// StaticProvider serves rates from a fixed table, so conversion works offline
+// StaticProvider kurları sabit bir tablodan sunar, böylece çevrim çevrimdışı çalışır
+type StaticProvider struct {
+	rates map[string]*big.Rat
+}
+
+// NewStaticProvider loads rates from a JSON file, or the built-in table if path is empty
+// NewStaticProvider kurları JSON dosyasından, path boşsa gömülü tablodan yükler
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	table := defaultTable
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		table = rateTable{}
+		if err := json.Unmarshal(data, &table); err != nil {
+			return nil, err
+		}
+	}
+
+	rates := make(map[string]*big.Rat, len(table.Rates)+1)
+	for code, value := range table.Rates {
+		rate, err := Parse(value)
+		if err != nil {
+			return nil, errors.New("invalid rate for " + code)
+		}
+		rates[strings.ToUpper(code)] = rate
+	}
+	rates[strings.ToUpper(table.Base)] = big.NewRat(1, 1)
+
+	return &StaticProvider{rates: rates}, nil
+}
+
+// MidRate computes a cross rate through the table's base currency
+// MidRate çapraz kuru tablonun baz para birimi üzerinden hesaplar
+func (p *StaticProvider) MidRate(from, to string) (*big.Rat, error) {
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return nil, ErrRateUnavailable
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return nil, ErrRateUnavailable
+	}
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
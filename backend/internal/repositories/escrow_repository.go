@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrEscrowChanged is returned when an escrow left the status an action expects,
+// e.g. it was released while a dispute was being opened
+//
+// ErrEscrowChanged bir emanet işlemin beklediği durumdan çıkmışsa döner,
+// örn. itiraz açılırken serbest bırakıldıysa
+var ErrEscrowChanged = errors.New("escrow is no longer in a state that allows this")
+
+// EscrowRepository handles DB operations for escrow payments
+// EscrowRepository emanet ödemeleri için DB işlemlerini yönetir
+type EscrowRepository struct {
+	db database.DB
+}
+
+func NewEscrowRepository(db database.DB) *EscrowRepository {
+	return &EscrowRepository{db: db}
+}
+
+// Create saves a new escrow
+// Create yeni bir emanet kaydeder
+func (r *EscrowRepository) Create(escrow *models.Escrow) error {
+	return r.db.GetDB().Create(escrow).Error
+}
+
+// FindByID retrieves a single escrow
+// FindByID tek bir emaneti getirir
+func (r *EscrowRepository) FindByID(id uint) (*models.Escrow, error) {
+	var escrow models.Escrow
+	if err := r.db.GetDB().First(&escrow, id).Error; err != nil {
+		return nil, err
+	}
+	return &escrow, nil
+}
+
+// FindByUser retrieves the escrows the user bought or sold in, newest first
+// FindByUser kullanıcının alıcı veya satıcı olduğu emanetleri yeniden eskiye getirir
+func (r *EscrowRepository) FindByUser(userID uint) ([]models.Escrow, error) {
+	var escrows []models.Escrow
+	err := r.db.GetDB().Where("buyer_id = ? OR seller_id = ?", userID, userID).
+		Order("created_at DESC").Find(&escrows).Error
+	return escrows, err
+}
+
+// FindByStatus retrieves escrows with the given status, oldest first (for the admin queue)
+// FindByStatus verilen durumdaki emanetleri eskiden yeniye getirir (admin kuyruğu için)
+func (r *EscrowRepository) FindByStatus(status string) ([]models.Escrow, error) {
+	var escrows []models.Escrow
+	err := r.db.GetDB().Where("status = ?", status).Order("created_at").Find(&escrows).Error
+	return escrows, err
+}
+
+// FindDue retrieves funded escrows whose release time has passed; ones that failed to release come last
+// FindDue serbest bırakma zamanı geçmiş funded emanetleri getirir; serbest bırakılamayanlar en sona gelir
+func (r *EscrowRepository) FindDue(now time.Time, limit int) ([]models.Escrow, error) {
+	var escrows []models.Escrow
+	err := r.db.GetDB().Where("status = ? AND release_at <= ?", models.EscrowStatusFunded, now.UTC()).
+		Order("release_failures").Order("release_at").Limit(limit).Find(&escrows).Error
+	return escrows, err
+}
+
+// RecordReleaseFailure counts a failed automatic release of a funded escrow and keeps its reason
+// RecordReleaseFailure funded bir emanetin başarısız otomatik serbest bırakmasını sayar ve nedenini tutar
+func (r *EscrowRepository) RecordReleaseFailure(escrow *models.Escrow, reason string) error {
+	result := r.db.GetDB().Model(&models.Escrow{}).
+		Where("id = ? AND status = ?", escrow.ID, models.EscrowStatusFunded).
+		Updates(map[string]interface{}{
+			"release_failures": gorm.Expr("release_failures + 1"),
+			"release_error":    reason,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEscrowChanged
+	}
+
+	escrow.ReleaseFailures++
+	escrow.ReleaseError = reason
+	return nil
+}
+
+// Dispute moves a funded escrow to disputed.
+// The status condition makes sure a closed escrow cannot be disputed.
+//
+// Dispute funded bir emaneti disputed'a taşır.
+// Durum koşulu kapanmış bir emanete itiraz edilememesini sağlar.
+func (r *EscrowRepository) Dispute(escrow *models.Escrow, userID uint, reason string) error {
+	result := r.db.GetDB().Model(&models.Escrow{}).
+		Where("id = ? AND status = ?", escrow.ID, models.EscrowStatusFunded).
+		Updates(map[string]interface{}{
+			"status":         models.EscrowStatusDisputed,
+			"disputed_by":    userID,
+			"dispute_reason": reason,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEscrowChanged
+	}
+
+	escrow.Status = models.EscrowStatusDisputed
+	escrow.DisputedBy = &userID
+	escrow.DisputeReason = reason
+	return nil
+}
+
+// Close moves an open escrow to released or refunded, from one of the allowed statuses.
+// The status condition makes sure the money of an escrow moves out only once.
+//
+// Close açık bir emaneti izin verilen durumlardan birinden released veya refunded'a taşır.
+// Durum koşulu bir emanetin parasının yalnızca bir kez çıkmasını sağlar.
+func (r *EscrowRepository) Close(escrow *models.Escrow, from []string, status, resolution string, resolvedBy *uint, note string) error {
+	now := time.Now().UTC()
+	result := r.db.GetDB().Model(&models.Escrow{}).
+		Where("id = ? AND status IN ?", escrow.ID, from).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"resolution":  resolution,
+			"resolved_by": resolvedBy,
+			"note":        note,
+			"closed_at":   now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEscrowChanged
+	}
+
+	escrow.Status = status
+	escrow.Resolution = resolution
+	escrow.ResolvedBy = resolvedBy
+	escrow.Note = note
+	escrow.ClosedAt = &now
+	return nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+)
+
+// ErrQuoteUnavailable is returned for unknown, expired, foreign or already used quotes
+// ErrQuoteUnavailable bilinmeyen, süresi dolmuş, başkasına ait veya kullanılmış teklifler için döner
+var ErrQuoteUnavailable = errors.New("quote not found, expired or already used")
+
+// FxQuoteRepository handles DB operations for exchange rate quotes
+// FxQuoteRepository döviz kuru teklifleri için DB işlemlerini yönetir
+type FxQuoteRepository struct {
+	db database.DB
+}
+
+func NewFxQuoteRepository(db database.DB) *FxQuoteRepository {
+	return &FxQuoteRepository{db: db}
+}
+
+// Create saves a new quote
+// Create yeni bir teklif kaydeder
+func (r *FxQuoteRepository) Create(quote *models.FxQuote) error {
+	return r.db.GetDB().Create(quote).Error
+}
+
+// Consume marks a quote as used in a single conditional UPDATE, so it can be used only once
+// Consume teklifi tek bir koşullu UPDATE ile kullanıldı olarak işaretler, böylece sadece bir kez kullanılabilir
+func (r *FxQuoteRepository) Consume(quoteID, userID uint) (*models.FxQuote, error) {
+	now := time.Now().UTC()
+	result := r.db.GetDB().Model(&models.FxQuote{}).
+		Where("id = ? AND user_id = ? AND used_at IS NULL AND expires_at > ?", quoteID, userID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrQuoteUnavailable
+	}
+
+	var quote models.FxQuote
+	if err := r.db.GetDB().First(&quote, quoteID).Error; err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+)
+
+// GroupRepository handles DB operations for expense groups, their expenses and settlements
+// GroupRepository harcama grupları, harcamaları ve hesaplaşmaları için DB işlemlerini yönetir
+type GroupRepository struct {
+	db database.DB
+}
+
+func NewGroupRepository(db database.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+// Create saves a new group
+// Create yeni bir grup kaydeder
+func (r *GroupRepository) Create(group *models.Group) error {
+	return r.db.GetDB().Create(group).Error
+}
+
+// FindByID retrieves a single group
+// FindByID tek bir grubu getirir
+func (r *GroupRepository) FindByID(id uint) (*models.Group, error) {
+	var group models.Group
+	if err := r.db.GetDB().First(&group, id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// FindByMember retrieves the groups the user is an active member of, oldest first
+// FindByMember kullanıcının aktif üyesi olduğu grupları eskiden yeniye getirir
+func (r *GroupRepository) FindByMember(userID uint) ([]models.Group, error) {
+	var groups []models.Group
+	err := r.db.GetDB().
+		Where("id IN (?)", r.db.GetDB().Model(&models.GroupMember{}).Select("group_id").
+			Where("user_id = ? AND status = ?", userID, models.GroupMemberActive)).
+		Order("created_at").Find(&groups).Error
+	return groups, err
+}
+
+// SaveMember creates a membership or overwrites an existing one (re-adding a member reuses the row)
+// SaveMember bir üyelik oluşturur veya mevcut olanın üzerine yazar (yeniden eklenen üye satırı kullanır)
+func (r *GroupRepository) SaveMember(member *models.GroupMember) error {
+	return r.db.GetDB().Save(member).Error
+}
+
+// FindMember retrieves the user's membership of a group, whatever its status
+// FindMember kullanıcının bir gruptaki üyeliğini durumundan bağımsız getirir
+func (r *GroupRepository) FindMember(groupID, userID uint) (*models.GroupMember, error) {
+	var member models.GroupMember
+	err := r.db.GetDB().Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindMembers retrieves the active members of a group, oldest first
+// FindMembers bir grubun aktif üyelerini eskiden yeniye getirir
+func (r *GroupRepository) FindMembers(groupID uint) ([]models.GroupMember, error) {
+	var members []models.GroupMember
+	err := r.db.GetDB().Where("group_id = ? AND status = ?", groupID, models.GroupMemberActive).
+		Order("created_at").Find(&members).Error
+	return members, err
+}
+
+// CreateExpense saves an expense together with its shares
+// CreateExpense bir harcamayı paylarıyla birlikte kaydeder
+func (r *GroupRepository) CreateExpense(expense *models.Expense) error {
+	return r.db.GetDB().Create(expense).Error
+}
+
+// FindExpense retrieves an expense of a group with its shares
+// FindExpense bir grubun harcamasını paylarıyla birlikte getirir
+func (r *GroupRepository) FindExpense(groupID, expenseID uint) (*models.Expense, error) {
+	var expense models.Expense
+	err := r.db.GetDB().Preload("Shares").Where("group_id = ?", groupID).First(&expense, expenseID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+// FindExpenses retrieves the expenses of a group with their shares, newest first
+// FindExpenses bir grubun harcamalarını paylarıyla birlikte yeniden eskiye getirir
+func (r *GroupRepository) FindExpenses(groupID uint) ([]models.Expense, error) {
+	var expenses []models.Expense
+	err := r.db.GetDB().Preload("Shares").Where("group_id = ?", groupID).
+		Order("created_at DESC, id DESC").Find(&expenses).Error
+	return expenses, err
+}
+
+// DeleteExpense soft-deletes an expense; its shares stop counting towards the balances with it
+// DeleteExpense bir harcamayı yumuşak siler; payları da onunla birlikte bakiyelere sayılmaz olur
+func (r *GroupRepository) DeleteExpense(expense *models.Expense) error {
+	return r.db.GetDB().Delete(expense).Error
+}
+
+// CreateSettlement records a settle-up transfer between two members
+// CreateSettlement iki üye arasındaki bir hesaplaşma transferini kaydeder
+func (r *GroupRepository) CreateSettlement(settlement *models.Settlement) error {
+	return r.db.GetDB().Create(settlement).Error
+}
+
+// FindSettlements retrieves the settlements of a group, newest first
+// FindSettlements bir grubun hesaplaşmalarını yeniden eskiye getirir
+func (r *GroupRepository) FindSettlements(groupID uint) ([]models.Settlement, error) {
+	var settlements []models.Settlement
+	err := r.db.GetDB().Where("group_id = ?", groupID).Order("created_at DESC, id DESC").Find(&settlements).Error
+	return settlements, err
+}
+
+// userSum is one row of a per-user SUM query
+// userSum kullanıcı başına SUM sorgusunun bir satırıdır
+type userSum struct {
+	UserID uint
+	Total  int64
+}
+
+// Balances computes every user's net position in a group in minor units: what they paid
+// for expenses and sent in settlements, minus their shares and what they received in
+// settlements. Positive means the group owes the user; the positions add up to zero.
+//
+// Balances her kullanıcının gruptaki net durumunu alt birim cinsinden hesaplar: harcamalar
+// için ödedikleri ve hesaplaşmalarda gönderdikleri, eksi payları ve hesaplaşmalarda
+// aldıkları. Pozitif, grubun kullanıcıya borçlu olduğu anlamına gelir; durumların toplamı sıfırdır.
+func (r *GroupRepository) Balances(groupID uint) (map[uint]int64, error) {
+	db := r.db.GetDB()
+	balances := make(map[uint]int64)
+
+	add := func(rows []userSum, sign int64) {
+		for _, row := range rows {
+			balances[row.UserID] += sign * row.Total
+		}
+	}
+
+	var paid, owed, sent, received []userSum
+	if err := db.Model(&models.Expense{}).Select("paid_by AS user_id, SUM(amount) AS total").
+		Where("group_id = ?", groupID).Group("paid_by").Scan(&paid).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Table("expense_shares").Select("expense_shares.user_id AS user_id, SUM(expense_shares.amount) AS total").
+		Joins("JOIN expenses ON expenses.id = expense_shares.expense_id").
+		Where("expenses.group_id = ? AND expenses.deleted_at IS NULL", groupID).
+		Group("expense_shares.user_id").Scan(&owed).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.Settlement{}).Select("from_user_id AS user_id, SUM(amount) AS total").
+		Where("group_id = ?", groupID).Group("from_user_id").Scan(&sent).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.Settlement{}).Select("to_user_id AS user_id, SUM(amount) AS total").
+		Where("group_id = ?", groupID).Group("to_user_id").Scan(&received).Error; err != nil {
+		return nil, err
+	}
+
+	add(paid, 1)
+	add(owed, -1)
+	add(sent, 1)
+	add(received, -1)
+	return balances, nil
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+)
+
+// ErrHoldNotActive is returned when a hold was already captured, voided or expired
+// ErrHoldNotActive provizyon zaten tahsil, iptal edilmiş veya süresi dolmuşsa döner
+var ErrHoldNotActive = errors.New("hold is no longer active")
+
+// HoldRepository handles DB operations for authorization holds
+// HoldRepository provizyonlar için DB işlemlerini yönetir
+type HoldRepository struct {
+	db database.DB
+}
+
+func NewHoldRepository(db database.DB) *HoldRepository {
+	return &HoldRepository{db: db}
+}
+
+// Create saves a new hold
+// Create yeni bir provizyon kaydeder
+func (r *HoldRepository) Create(hold *models.Hold) error {
+	return r.db.GetDB().Create(hold).Error
+}
+
+// FindByID retrieves a single hold
+// FindByID tek bir provizyonu getirir
+func (r *HoldRepository) FindByID(id uint) (*models.Hold, error) {
+	var hold models.Hold
+	if err := r.db.GetDB().First(&hold, id).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// FindByUser retrieves holds the user placed or may capture, newest first
+// FindByUser kullanıcının verdiği veya tahsil edebileceği provizyonları döndürür (yeniden eskiye)
+func (r *HoldRepository) FindByUser(userID uint) ([]models.Hold, error) {
+	var holds []models.Hold
+	err := r.db.GetDB().Where("user_id = ? OR payee_id = ?", userID, userID).
+		Order("created_at DESC").Find(&holds).Error
+	return holds, err
+}
+
+// FindExpired retrieves authorized holds whose expiry has passed
+// FindExpired süresi geçmiş ve hâlâ açık olan provizyonları getirir
+func (r *HoldRepository) FindExpired(now time.Time, limit int) ([]models.Hold, error) {
+	var holds []models.Hold
+	err := r.db.GetDB().Where("status = ? AND expires_at <= ?", models.HoldStatusAuthorized, now.UTC()).
+		Order("expires_at").Limit(limit).Find(&holds).Error
+	return holds, err
+}
+
+// Close moves an authorized hold to its final status.
+// The status condition makes sure a hold is captured, voided or expired only once.
+//
+// Close açık bir provizyonu son durumuna taşır.
+// Durum koşulu provizyonun yalnızca bir kez tahsil, iptal edilmesini veya süresinin dolmasını sağlar.
+func (r *HoldRepository) Close(hold *models.Hold, status string, capturedAmount int64) error {
+	result := r.db.GetDB().Model(&models.Hold{}).
+		Where("id = ? AND status = ?", hold.ID, models.HoldStatusAuthorized).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"captured_amount": capturedAmount,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrHoldNotActive
+	}
+
+	hold.Status = status
+	hold.CapturedAmount = capturedAmount
+	return nil
+}
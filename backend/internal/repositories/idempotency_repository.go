@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+)
+
+// IdempotencyRepository handles DB operations for idempotency keys
+// IdempotencyRepository idempotency anahtarları için DB işlemlerini yönetir
+type IdempotencyRepository struct {
+	db database.DB
+}
+
+func NewIdempotencyRepository(db database.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Create stores a new key; fails if the user already used it
+// Create yeni bir anahtar kaydeder; kullanıcı anahtarı daha önce kullandıysa hata verir
+func (r *IdempotencyRepository) Create(key *models.IdempotencyKey) error {
+	return r.db.GetDB().Create(key).Error
+}
+
+// FindByUserAndKey retrieves a stored key for the user
+// FindByUserAndKey kullanıcıya ait kayıtlı anahtarı getirir
+func (r *IdempotencyRepository) FindByUserAndKey(userID uint, key string) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	if err := r.db.GetDB().Where("user_id = ? AND key = ?", userID, key).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Update saves the response of a finished request
+// Update tamamlanan isteğin cevabını kaydeder
+func (r *IdempotencyRepository) Update(key *models.IdempotencyKey) error {
+	return r.db.GetDB().Save(key).Error
+}
+
+// Delete permanently removes a key so the request can be retried
+// Delete anahtarı kalıcı olarak siler, böylece istek tekrar denenebilir
+func (r *IdempotencyRepository) Delete(key *models.IdempotencyKey) error {
+	return r.db.GetDB().Unscoped().Delete(key).Error
+}
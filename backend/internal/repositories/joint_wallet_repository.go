@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+)
+
+// ErrWithdrawalNotPending is returned when a joint withdrawal was already executed or rejected
+// ErrWithdrawalNotPending ortak çekim zaten gerçekleştirilmiş veya reddedilmişse döner
+var ErrWithdrawalNotPending = errors.New("withdrawal is no longer pending")
+
+// JointWalletRepository handles DB operations for joint wallets, their members and withdrawal approvals
+// JointWalletRepository ortak cüzdanlar, üyeleri ve çekim onayları için DB işlemlerini yönetir
+type JointWalletRepository struct {
+	db database.DB
+}
+
+func NewJointWalletRepository(db database.DB) *JointWalletRepository {
+	return &JointWalletRepository{db: db}
+}
+
+// Create saves a new joint wallet
+// Create yeni bir ortak cüzdan kaydeder
+func (r *JointWalletRepository) Create(joint *models.JointWallet) error {
+	return r.db.GetDB().Create(joint).Error
+}
+
+// FindByID retrieves a single joint wallet
+// FindByID tek bir ortak cüzdanı getirir
+func (r *JointWalletRepository) FindByID(id uint) (*models.JointWallet, error) {
+	var joint models.JointWallet
+	if err := r.db.GetDB().First(&joint, id).Error; err != nil {
+		return nil, err
+	}
+	return &joint, nil
+}
+
+// FindByMember retrieves the joint wallets the user is an active or invited member of, oldest first
+// FindByMember kullanıcının aktif veya davetli üyesi olduğu ortak cüzdanları eskiden yeniye getirir
+func (r *JointWalletRepository) FindByMember(userID uint) ([]models.JointWallet, error) {
+	var joints []models.JointWallet
+	err := r.db.GetDB().
+		Where("id IN (?)", r.db.GetDB().Model(&models.JointMember{}).Select("joint_wallet_id").
+			Where("user_id = ? AND status IN ?", userID, []string{models.JointMemberActive, models.JointMemberInvited})).
+		Order("created_at").Find(&joints).Error
+	return joints, err
+}
+
+// UpdatePolicy stores the approval threshold and the number of approvals required
+// UpdatePolicy onay eşiğini ve gereken onay sayısını kaydeder
+func (r *JointWalletRepository) UpdatePolicy(joint *models.JointWallet) error {
+	return r.db.GetDB().Model(joint).Updates(map[string]interface{}{
+		"approval_threshold": joint.ApprovalThreshold,
+		"approvals_required": joint.ApprovalsRequired,
+	}).Error
+}
+
+// SaveMember creates a membership or overwrites an existing one (re-invitations reuse the row)
+// SaveMember bir üyelik oluşturur veya mevcut olanın üzerine yazar (yeniden davetler satırı kullanır)
+func (r *JointWalletRepository) SaveMember(member *models.JointMember) error {
+	return r.db.GetDB().Save(member).Error
+}
+
+// FindMember retrieves the user's membership of a joint wallet, whatever its status
+// FindMember kullanıcının bir ortak cüzdandaki üyeliğini durumundan bağımsız getirir
+func (r *JointWalletRepository) FindMember(jointID, userID uint) (*models.JointMember, error) {
+	var member models.JointMember
+	err := r.db.GetDB().Where("joint_wallet_id = ? AND user_id = ?", jointID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindMembers retrieves the active and invited members of a joint wallet, oldest first
+// FindMembers bir ortak cüzdanın aktif ve davetli üyelerini eskiden yeniye getirir
+func (r *JointWalletRepository) FindMembers(jointID uint) ([]models.JointMember, error) {
+	var members []models.JointMember
+	err := r.db.GetDB().
+		Where("joint_wallet_id = ? AND status IN ?", jointID, []string{models.JointMemberActive, models.JointMemberInvited}).
+		Order("created_at").Find(&members).Error
+	return members, err
+}
+
+// CountActive counts the active members of a joint wallet holding one of the roles
+// CountActive bir ortak cüzdanın verilen rollerden birine sahip aktif üyelerini sayar
+func (r *JointWalletRepository) CountActive(jointID uint, roles ...string) (int64, error) {
+	var count int64
+	err := r.db.GetDB().Model(&models.JointMember{}).
+		Where("joint_wallet_id = ? AND status = ? AND role IN ?", jointID, models.JointMemberActive, roles).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateWithdrawal saves a new pending withdrawal
+// CreateWithdrawal yeni bir bekleyen çekim kaydeder
+func (r *JointWalletRepository) CreateWithdrawal(withdrawal *models.JointWithdrawal) error {
+	return r.db.GetDB().Create(withdrawal).Error
+}
+
+// FindWithdrawal retrieves a withdrawal with its approvals
+// FindWithdrawal bir çekimi onaylarıyla birlikte getirir
+func (r *JointWalletRepository) FindWithdrawal(id uint) (*models.JointWithdrawal, error) {
+	var withdrawal models.JointWithdrawal
+	if err := r.db.GetDB().Preload("Approvals").First(&withdrawal, id).Error; err != nil {
+		return nil, err
+	}
+	return &withdrawal, nil
+}
+
+// FindWithdrawals retrieves the withdrawals of a joint wallet with their approvals, pending ones first, then newest first
+// FindWithdrawals bir ortak cüzdanın çekimlerini onaylarıyla getirir, önce bekleyenler, sonra yeniden eskiye
+func (r *JointWalletRepository) FindWithdrawals(jointID uint) ([]models.JointWithdrawal, error) {
+	var withdrawals []models.JointWithdrawal
+	err := r.db.GetDB().Preload("Approvals").Where("joint_wallet_id = ?", jointID).
+		Order("status <> '" + models.JointWithdrawalPending + "', created_at DESC").Find(&withdrawals).Error
+	return withdrawals, err
+}
+
+// AddApproval records a member's approval of a withdrawal
+// AddApproval bir üyenin çekim onayını kaydeder
+func (r *JointWalletRepository) AddApproval(withdrawal *models.JointWithdrawal, userID uint) error {
+	approval := models.JointApproval{WithdrawalID: withdrawal.ID, UserID: userID}
+	if err := r.db.GetDB().Create(&approval).Error; err != nil {
+		return err
+	}
+	withdrawal.Approvals = append(withdrawal.Approvals, approval)
+	return nil
+}
+
+// CloseWithdrawal moves a pending withdrawal to its final status.
+// The status condition makes sure a withdrawal is executed or rejected only once.
+//
+// CloseWithdrawal bekleyen bir çekimi son durumuna taşır.
+// Durum koşulu çekimin yalnızca bir kez gerçekleştirilmesini veya reddedilmesini sağlar.
+func (r *JointWalletRepository) CloseWithdrawal(withdrawal *models.JointWithdrawal, status string, transactionID, rejectedBy *uint) error {
+	now := time.Now().UTC()
+	result := r.db.GetDB().Model(&models.JointWithdrawal{}).
+		Where("id = ? AND status = ?", withdrawal.ID, models.JointWithdrawalPending).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"transaction_id": transactionID,
+			"rejected_by":    rejectedBy,
+			"decided_at":     now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWithdrawalNotPending
+	}
+
+	withdrawal.Status = status
+	withdrawal.TransactionID = transactionID
+	withdrawal.RejectedBy = rejectedBy
+	withdrawal.DecidedAt = &now
+	return nil
+}
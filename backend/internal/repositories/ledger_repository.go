@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+)
+
+// LedgerRepository handles DB operations for ledger accounts, entries and postings
+// LedgerRepository defter hesapları, yevmiye kayıtları ve hareketleri için DB işlemlerini yönetir
+type LedgerRepository struct {
+	db database.DB
+}
+
+func NewLedgerRepository(db database.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// FindOrCreateAccount returns the account with the given code, creating it if missing.
+// The boolean result reports whether the account was created by this call.
+//
+// FindOrCreateAccount verilen koda sahip hesabı döndürür, yoksa oluşturur.
+// Dönen boolean hesabın bu çağrıda oluşturulup oluşturulmadığını belirtir.
+func (r *LedgerRepository) FindOrCreateAccount(code, accountType, currency string, walletID *uint) (*models.LedgerAccount, bool, error) {
+	account := models.LedgerAccount{Code: code, Type: accountType, Currency: currency, WalletID: walletID}
+	result := r.db.GetDB().Where("code = ?", code).FirstOrCreate(&account)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	return &account, result.RowsAffected > 0, nil
+}
+
+// FindAccountByWallet returns the ledger account of a wallet without creating it
+// FindAccountByWallet bir cüzdanın defter hesabını oluşturmadan döndürür
+func (r *LedgerRepository) FindAccountByWallet(walletID uint) (*models.LedgerAccount, error) {
+	var account models.LedgerAccount
+	if err := r.db.GetDB().Where("wallet_id = ?", walletID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CreateEntry saves a journal entry together with its postings
+// CreateEntry yevmiye kaydını hareketleriyle birlikte kaydeder
+func (r *LedgerRepository) CreateEntry(entry *models.JournalEntry) error {
+	return r.db.GetDB().Create(entry).Error
+}
+
+// AccountBalance sums all postings of an account
+// AccountBalance bir hesabın tüm hareketlerini toplar
+func (r *LedgerRepository) AccountBalance(accountID uint) (int64, error) {
+	var balance int64
+	err := r.db.GetDB().Model(&models.Posting{}).
+		Where("account_id = ?", accountID).
+		Select("COALESCE(SUM(amount), 0)").Scan(&balance).Error
+	return balance, err
+}
+
+// TotalBalances sums every posting in the ledger per currency; balanced books return zero for each
+// TotalBalances defterdeki tüm hareketleri para birimine göre toplar; dengeli defterde hepsi sıfırdır
+func (r *LedgerRepository) TotalBalances() (map[string]int64, error) {
+	var rows []struct {
+		Currency string
+		Total    int64
+	}
+	err := r.db.GetDB().Model(&models.Posting{}).
+		Select("ledger_accounts.currency AS currency, COALESCE(SUM(postings.amount), 0) AS total").
+		Joins("JOIN ledger_accounts ON ledger_accounts.id = postings.account_id").
+		Group("ledger_accounts.currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		totals[row.Currency] = row.Total
+	}
+	return totals, nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// LimitRepository handles DB operations for per-user limit overrides
+// LimitRepository kullanıcıya özel limit override'ları için DB işlemlerini yönetir
+type LimitRepository struct {
+	db database.DB
+}
+
+func NewLimitRepository(db database.DB) *LimitRepository {
+	return &LimitRepository{db: db}
+}
+
+// FindOverride retrieves the override of one operation for a user
+// FindOverride bir kullanıcının bir işlem için override'ını getirir
+func (r *LimitRepository) FindOverride(userID uint, operation string) (*models.LimitOverride, error) {
+	var override models.LimitOverride
+	err := r.db.GetDB().Where("user_id = ? AND operation = ?", userID, operation).
+		First(&override).Error
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// Save creates or replaces the override of (user, operation)
+// Save (kullanıcı, işlem) override'ını oluşturur veya değiştirir
+func (r *LimitRepository) Save(override *models.LimitOverride) error {
+	return r.db.GetDB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "operation"}},
+		DoUpdates: clause.AssignmentColumns([]string{"per_transaction", "daily", "monthly", "set_by", "updated_at"}),
+	}).Create(override).Error
+}
+
+// Delete removes the override so the defaults apply again.
+// Rows are deleted for good, so the unique (user, operation) pair can be set again.
+//
+// Delete override'ı kaldırır, böylece tekrar varsayılanlar geçerli olur.
+// Satırlar kalıcı silinir, böylece benzersiz (kullanıcı, işlem) çifti tekrar atanabilir.
+func (r *LimitRepository) Delete(userID uint, operation string) error {
+	return r.db.GetDB().Unscoped().
+		Where("user_id = ? AND operation = ?", userID, operation).
+		Delete(&models.LimitOverride{}).Error
+}
@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrLinkNotPayable is returned when a payment link was completed, cancelled or has expired
+// ErrLinkNotPayable ödeme bağlantısı tamamlanmış, iptal edilmiş veya süresi dolmuşsa döner
+var ErrLinkNotPayable = errors.New("payment link can no longer be paid")
+
+// MerchantRepository handles DB operations for merchants, their payment links and link payments
+// MerchantRepository işletmeler, ödeme bağlantıları ve bağlantı ödemeleri için DB işlemlerini yönetir
+type MerchantRepository struct {
+	db database.DB
+}
+
+func NewMerchantRepository(db database.DB) *MerchantRepository {
+	return &MerchantRepository{db: db}
+}
+
+// Save creates a merchant or updates its profile; the QR token is only set by AssignQRToken
+// Save bir işletme oluşturur veya profilini günceller; QR belirteci yalnızca AssignQRToken ile atanır
+func (r *MerchantRepository) Save(merchant *models.Merchant) error {
+	return r.db.GetDB().Omit("qr_token").Save(merchant).Error
+}
+
+// FindByID retrieves a single merchant
+// FindByID tek bir işletmeyi getirir
+func (r *MerchantRepository) FindByID(id uint) (*models.Merchant, error) {
+	var merchant models.Merchant
+	if err := r.db.GetDB().First(&merchant, id).Error; err != nil {
+		return nil, err
+	}
+	return &merchant, nil
+}
+
+// FindByUser retrieves the merchant account of a user
+// FindByUser bir kullanıcının işletme hesabını getirir
+func (r *MerchantRepository) FindByUser(userID uint) (*models.Merchant, error) {
+	var merchant models.Merchant
+	if err := r.db.GetDB().Where("user_id = ?", userID).First(&merchant).Error; err != nil {
+		return nil, err
+	}
+	return &merchant, nil
+}
+
+// FindByQRToken retrieves the merchant whose static QR codes carry the token
+// FindByQRToken statik QR kodları belirteci taşıyan işletmeyi getirir
+func (r *MerchantRepository) FindByQRToken(token string) (*models.Merchant, error) {
+	var merchant models.Merchant
+	if err := r.db.GetDB().Where("qr_token = ?", token).First(&merchant).Error; err != nil {
+		return nil, err
+	}
+	return &merchant, nil
+}
+
+// AssignQRToken gives the merchant a QR token unless it has one, then loads the token that stands
+// AssignQRToken işletmenin QR belirteci yoksa ona bir tane verir, sonra geçerli olan belirteci yükler
+func (r *MerchantRepository) AssignQRToken(merchant *models.Merchant, token string) error {
+	db := r.db.GetDB()
+	if err := db.Model(&models.Merchant{}).Where("id = ? AND qr_token IS NULL", merchant.ID).Update("qr_token", token).Error; err != nil {
+		return err
+	}
+	return db.Select("qr_token").First(merchant, merchant.ID).Error
+}
+
+// CreateLink saves a new payment link
+// CreateLink yeni bir ödeme bağlantısı kaydeder
+func (r *MerchantRepository) CreateLink(link *models.PaymentLink) error {
+	return r.db.GetDB().Create(link).Error
+}
+
+// FindLink retrieves a payment link of a merchant
+// FindLink bir işletmenin ödeme bağlantısını getirir
+func (r *MerchantRepository) FindLink(merchantID, linkID uint) (*models.PaymentLink, error) {
+	var link models.PaymentLink
+	if err := r.db.GetDB().Where("merchant_id = ?", merchantID).First(&link, linkID).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindLinkByCode retrieves a payment link by its public code
+// FindLinkByCode bir ödeme bağlantısını herkese açık koduyla getirir
+func (r *MerchantRepository) FindLinkByCode(code string) (*models.PaymentLink, error) {
+	var link models.PaymentLink
+	if err := r.db.GetDB().Where("code = ?", code).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindLinks retrieves the payment links of a merchant, optionally with one status, newest first
+// FindLinks bir işletmenin ödeme bağlantılarını, isteğe bağlı tek bir durumla, yeniden eskiye getirir
+func (r *MerchantRepository) FindLinks(merchantID uint, status string) ([]models.PaymentLink, error) {
+	var links []models.PaymentLink
+	query := r.db.GetDB().Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&links).Error
+	return links, err
+}
+
+// RecordPayment counts a payment on an active, unexpired link and stores it. A single-use
+// link is completed in the same update, so it can never be paid twice.
+//
+// RecordPayment aktif ve süresi dolmamış bir bağlantıya bir ödeme sayar ve ödemeyi kaydeder.
+// Tek kullanımlık bağlantı aynı güncellemede tamamlanır, böylece asla iki kez ödenemez.
+func (r *MerchantRepository) RecordPayment(link *models.PaymentLink, payment *models.LinkPayment) error {
+	updates := map[string]interface{}{
+		"payment_count": gorm.Expr("payment_count + 1"),
+		"paid_total":    gorm.Expr("paid_total + ?", payment.Amount),
+	}
+	status := link.Status
+	if !link.MultiUse {
+		status = models.PaymentLinkCompleted
+		updates["status"] = status
+	}
+
+	result := r.db.GetDB().Model(&models.PaymentLink{}).
+		Where("id = ? AND status = ? AND (expires_at IS NULL OR expires_at > ?)", link.ID, models.PaymentLinkActive, time.Now().UTC()).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLinkNotPayable
+	}
+	if err := r.db.GetDB().Create(payment).Error; err != nil {
+		return err
+	}
+
+	link.Status = status
+	link.PaymentCount++
+	link.PaidTotal += payment.Amount
+	return nil
+}
+
+// Cancel stops an active link from taking more payments
+// Cancel aktif bir bağlantının daha fazla ödeme almasını durdurur
+func (r *MerchantRepository) Cancel(link *models.PaymentLink) error {
+	result := r.db.GetDB().Model(&models.PaymentLink{}).
+		Where("id = ? AND status = ?", link.ID, models.PaymentLinkActive).
+		Update("status", models.PaymentLinkCancelled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLinkNotPayable
+	}
+
+	link.Status = models.PaymentLinkCancelled
+	return nil
+}
+
+// ExpireDue marks every active link past its expiry as expired and returns how many
+// ExpireDue süresi geçmiş her aktif bağlantıyı expired olarak işaretler ve kaç tane olduğunu döndürür
+func (r *MerchantRepository) ExpireDue(now time.Time) (int64, error) {
+	result := r.db.GetDB().Model(&models.PaymentLink{}).
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", models.PaymentLinkActive, now.UTC()).
+		Update("status", models.PaymentLinkExpired)
+	return result.RowsAffected, result.Error
+}
+
+// FindPayments retrieves the payments of a link, newest first
+// FindPayments bir bağlantının ödemelerini yeniden eskiye getirir
+func (r *MerchantRepository) FindPayments(linkID uint) ([]models.LinkPayment, error) {
+	var payments []models.LinkPayment
+	err := r.db.GetDB().Where("link_id = ?", linkID).Order("created_at DESC, id DESC").Find(&payments).Error
+	return payments, err
+}
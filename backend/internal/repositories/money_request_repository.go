@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+)
+
+// ErrRequestNotPending is returned when a money request was already answered or expired
+// ErrRequestNotPending para isteği zaten cevaplanmış veya süresi dolmuşsa döner
+var ErrRequestNotPending = errors.New("money request is no longer pending")
+
+// MoneyRequestRepository handles DB operations for money requests
+// MoneyRequestRepository para istekleri için DB işlemlerini yönetir
+type MoneyRequestRepository struct {
+	db database.DB
+}
+
+func NewMoneyRequestRepository(db database.DB) *MoneyRequestRepository {
+	return &MoneyRequestRepository{db: db}
+}
+
+// Create saves a new money request
+// Create yeni bir para isteği kaydeder
+func (r *MoneyRequestRepository) Create(request *models.MoneyRequest) error {
+	return r.db.GetDB().Create(request).Error
+}
+
+// FindByID retrieves a single money request
+// FindByID tek bir para isteğini getirir
+func (r *MoneyRequestRepository) FindByID(id uint) (*models.MoneyRequest, error) {
+	var request models.MoneyRequest
+	if err := r.db.GetDB().First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindIncoming retrieves requests the user is asked to pay, newest first
+// FindIncoming kullanıcıdan ödemesi istenen istekleri döndürür (yeniden eskiye)
+func (r *MoneyRequestRepository) FindIncoming(userID uint) ([]models.MoneyRequest, error) {
+	var requests []models.MoneyRequest
+	err := r.db.GetDB().Where("payer_id = ?", userID).
+		Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+// FindOutgoing retrieves requests the user sent, newest first
+// FindOutgoing kullanıcının gönderdiği istekleri döndürür (yeniden eskiye)
+func (r *MoneyRequestRepository) FindOutgoing(userID uint) ([]models.MoneyRequest, error) {
+	var requests []models.MoneyRequest
+	err := r.db.GetDB().Where("requester_id = ?", userID).
+		Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+// Respond moves a pending, unexpired request to its final status.
+// The condition makes sure a request is paid or answered only once.
+//
+// Respond bekleyen ve süresi dolmamış isteği son durumuna taşır.
+// Koşul isteğin yalnızca bir kez ödenmesini veya cevaplanmasını sağlar.
+func (r *MoneyRequestRepository) Respond(request *models.MoneyRequest, status string, transactionID *uint) error {
+	now := time.Now().UTC()
+	result := r.db.GetDB().Model(&models.MoneyRequest{}).
+		Where("id = ? AND status = ? AND expires_at > ?", request.ID, models.MoneyRequestPending, now).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"responded_at":   now,
+			"transaction_id": transactionID,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRequestNotPending
+	}
+
+	request.Status = status
+	request.RespondedAt = &now
+	request.TransactionID = transactionID
+	return nil
+}
+
+// ExpireDue marks every pending request past its expiry as expired and returns how many
+// ExpireDue süresi geçmiş tüm bekleyen istekleri expired yapar ve kaç tane olduğunu döndürür
+func (r *MoneyRequestRepository) ExpireDue(now time.Time) (int64, error) {
+	now = now.UTC()
+	result := r.db.GetDB().Model(&models.MoneyRequest{}).
+		Where("status = ? AND expires_at <= ?", models.MoneyRequestPending, now).
+		Updates(map[string]interface{}{
+			"status":       models.MoneyRequestExpired,
+			"responded_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+)
+
+// ErrPotClosed is returned when money would move in or out of a closed pot
+// ErrPotClosed kapalı bir kumbaraya para girecek veya çıkacaksa döner
+var ErrPotClosed = errors.New("pot is closed")
+
+// PotRepository handles DB operations for savings pots
+// PotRepository birikim kumbaraları için DB işlemlerini yönetir
+type PotRepository struct {
+	db database.DB
+}
+
+func NewPotRepository(db database.DB) *PotRepository {
+	return &PotRepository{db: db}
+}
+
+// Create saves a new pot
+// Create yeni bir kumbara kaydeder
+func (r *PotRepository) Create(pot *models.Pot) error {
+	return r.db.GetDB().Create(pot).Error
+}
+
+// FindByID retrieves a single pot
+// FindByID tek bir kumbarayı getirir
+func (r *PotRepository) FindByID(id uint) (*models.Pot, error) {
+	var pot models.Pot
+	if err := r.db.GetDB().First(&pot, id).Error; err != nil {
+		return nil, err
+	}
+	return &pot, nil
+}
+
+// FindByUser retrieves the user's pots, open ones first, then oldest first
+// FindByUser kullanıcının kumbaralarını getirir, önce açık olanlar, sonra eskiden yeniye
+func (r *PotRepository) FindByUser(userID uint) ([]models.Pot, error) {
+	var pots []models.Pot
+	err := r.db.GetDB().Where("user_id = ?", userID).
+		Order("status = '" + models.PotStatusClosed + "', created_at").Find(&pots).Error
+	return pots, err
+}
+
+// TotalsByUser sums the balances of the user's open pots per currency
+// TotalsByUser kullanıcının açık kumbaralarının bakiyelerini para birimi bazında toplar
+func (r *PotRepository) TotalsByUser(userID uint) ([]CurrencyTotal, error) {
+	var totals []CurrencyTotal
+	err := r.db.GetDB().Model(&models.Pot{}).
+		Select("currency, COALESCE(SUM(balance), 0) AS total").
+		Where("user_id = ? AND status = ?", userID, models.PotStatusOpen).
+		Group("currency").Scan(&totals).Error
+	return totals, err
+}
+
+// UpdateBalance writes a new balance of an open pot only if its version is unchanged since it was read
+// UpdateBalance açık bir kumbaranın yeni bakiyesini sadece versiyonu okunduğundan beri değişmediyse yazar
+func (r *PotRepository) UpdateBalance(pot *models.Pot, balance int64) error {
+	if pot.Status != models.PotStatusOpen {
+		return ErrPotClosed
+	}
+
+	now := time.Now().UTC()
+	result := r.db.GetDB().Model(&models.Pot{}).
+		Where("id = ? AND version = ?", pot.ID, pot.Version).
+		Updates(map[string]interface{}{
+			"balance":    balance,
+			"version":    pot.Version + 1,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	pot.Balance = balance
+	pot.Version++
+	pot.UpdatedAt = now
+	return nil
+}
+
+// Close marks an open pot closed; its balance must already be swept back
+// Close açık bir kumbarayı kapatır; bakiyesi önceden geri aktarılmış olmalıdır
+func (r *PotRepository) Close(pot *models.Pot) error {
+	now := time.Now().UTC()
+	result := r.db.GetDB().Model(&models.Pot{}).
+		Where("id = ? AND version = ? AND status = ?", pot.ID, pot.Version, models.PotStatusOpen).
+		Updates(map[string]interface{}{
+			"status":     models.PotStatusClosed,
+			"closed_at":  now,
+			"version":    pot.Version + 1,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	pot.Status = models.PotStatusClosed
+	pot.ClosedAt = &now
+	pot.Version++
+	pot.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+)
+
+// ErrScheduleChanged is returned when a schedule was modified since it was read
+// ErrScheduleChanged zamanlama okunduktan sonra değiştirildiğinde döner
+var ErrScheduleChanged = errors.New("schedule was modified concurrently")
+
+// ScheduleRepository handles DB operations for scheduled transfers
+// ScheduleRepository zamanlanmış transferler için DB işlemlerini yönetir
+type ScheduleRepository struct {
+	db database.DB
+}
+
+func NewScheduleRepository(db database.DB) *ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+// Create saves a new schedule
+// Create yeni bir zamanlama kaydeder
+func (r *ScheduleRepository) Create(schedule *models.ScheduledTransfer) error {
+	return r.db.GetDB().Create(schedule).Error
+}
+
+// FindByID retrieves a single schedule
+// FindByID tek bir zamanlamayı getirir
+func (r *ScheduleRepository) FindByID(id uint) (*models.ScheduledTransfer, error) {
+	var schedule models.ScheduledTransfer
+	if err := r.db.GetDB().First(&schedule, id).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// FindByUser retrieves the user's schedules, next run first
+// FindByUser kullanıcının zamanlamalarını en yakın çalıştırma önce olacak şekilde getirir
+func (r *ScheduleRepository) FindByUser(userID uint) ([]models.ScheduledTransfer, error) {
+	var schedules []models.ScheduledTransfer
+	err := r.db.GetDB().Where("user_id = ?", userID).
+		Order("next_run_at").Find(&schedules).Error
+	return schedules, err
+}
+
+// FindDue retrieves active schedules whose next run is not in the future
+// FindDue bir sonraki çalıştırması gelmiş aktif zamanlamaları getirir
+func (r *ScheduleRepository) FindDue(now time.Time, limit int) ([]models.ScheduledTransfer, error) {
+	var schedules []models.ScheduledTransfer
+	err := r.db.GetDB().Where("status = ? AND next_run_at <= ?", models.ScheduleStatusActive, now.UTC()).
+		Order("next_run_at").Limit(limit).Find(&schedules).Error
+	return schedules, err
+}
+
+// Update saves every field of the schedule only if its version is unchanged since it was read
+// Update zamanlamanın tüm alanlarını sadece versiyonu okunduğundan beri değişmediyse kaydeder
+func (r *ScheduleRepository) Update(schedule *models.ScheduledTransfer) error {
+	version := schedule.Version
+	schedule.Version++
+
+	result := r.db.GetDB().Model(schedule).
+		Where("version = ?", version).
+		Select("*").Omit("created_at").
+		Updates(schedule)
+	if result.Error != nil {
+		schedule.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		schedule.Version = version
+		return ErrScheduleChanged
+	}
+	return nil
+}
@@ -1,10 +1,19 @@
 package repositories
 
 import (
+	"errors"
+	"time"
+
 	"mini-pay-backend/internal/database"
 	"mini-pay-backend/internal/models"
+
+	"gorm.io/gorm"
 )
 
+// ErrReversalExceedsAmount is returned when a reversal would undo more than the original amount
+// ErrReversalExceedsAmount geri alma orijinal tutardan fazlasını geri alacaksa döner
+var ErrReversalExceedsAmount = errors.New("reversal exceeds the remaining amount of the transaction")
+
 // TransactionRepository handles DB operations for transactions
 // TransactionRepository, transaction veritabanı işlemlerini yönetir
 type TransactionRepository struct {
@@ -15,17 +24,260 @@ func NewTransactionRepository(db database.DB) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
-// Create saves a new transaction record
-// Create yeni bir transaction kaydı oluşturur
+// Create saves a new transaction record chained to the previous entry of the same wallet.
+// Callers write history inside the wallet's unit of work, and the wallet version
+// serializes writers, so two entries never link to the same predecessor.
+//
+// Create yeni bir transaction kaydını aynı cüzdanın önceki kaydına zincirleyerek oluşturur.
+// Geçmiş cüzdanın unit of work'ü içinde yazılır ve cüzdan versiyonu yazıcıları sıraya koyar,
+// böylece iki kayıt asla aynı öncekine bağlanmaz.
 func (r *TransactionRepository) Create(tx *models.Transaction) error {
+	prevHash, err := r.LastHash(WalletChain{UserID: tx.UserID, JointID: tx.JointID, Currency: tx.Currency})
+	if err != nil {
+		return err
+	}
+
+	// Microsecond precision survives every database round trip, so the hash stays stable
+	// Mikrosaniye hassasiyeti her veritabanı gidiş dönüşünde korunur, böylece hash sabit kalır
+	if tx.CreatedAt.IsZero() {
+		tx.CreatedAt = time.Now().UTC()
+	}
+	tx.CreatedAt = tx.CreatedAt.Truncate(time.Microsecond)
+	tx.PrevHash = prevHash
+	tx.Hash = tx.ComputeHash()
+
 	return r.db.GetDB().Create(tx).Error
 }
 
-// FindByUser retrieves all transactions for a user, newest first
-// FindByUser bir kullanıcının tüm işlemlerini döndürür (yeniden eskiye)
-func (r *TransactionRepository) FindByUser(userID uint) ([]models.Transaction, error) {
+// LastHash returns the hash at the end of a wallet's chain ("" when it has no entries).
+// Deleted rows are included so a soft delete cannot hide a link.
+//
+// LastHash bir cüzdan zincirinin sonundaki hash değerini döndürür (kayıt yoksa "").
+// Silinmiş satırlar dahildir, böylece soft delete bir halkayı gizleyemez.
+func (r *TransactionRepository) LastHash(chain WalletChain) (string, error) {
+	var hashes []string
+	err := r.db.GetDB().Unscoped().Model(&models.Transaction{}).
+		Where("user_id = ? AND joint_id = ? AND currency = ?", chain.UserID, chain.JointID, chain.Currency).
+		Order("id DESC").Limit(1).Pluck("hash", &hashes).Error
+	if err != nil || len(hashes) == 0 {
+		return "", err
+	}
+	return hashes[0], nil
+}
+
+// WalletChain identifies the history of one wallet; JointID is 0 for personal wallets
+// WalletChain bir cüzdanın geçmişini tanımlar; kişisel cüzdanlarda JointID 0'dır
+type WalletChain struct {
+	UserID   uint   `json:"user_id"`
+	JointID  uint   `json:"joint_id,omitempty"`
+	Currency string `json:"currency"`
+}
+
+// ChainOf returns the chain of a wallet
+// ChainOf bir cüzdanın zincirini döndürür
+func ChainOf(wallet *models.Wallet) WalletChain {
+	return WalletChain{UserID: wallet.UserID, JointID: wallet.JointID, Currency: wallet.Currency}
+}
+
+// FindChains lists every (user, joint wallet, currency) triple that has history
+// FindChains geçmişi olan tüm (kullanıcı, ortak cüzdan, para birimi) üçlülerini listeler
+func (r *TransactionRepository) FindChains() ([]WalletChain, error) {
+	var chains []WalletChain
+	err := r.db.GetDB().Unscoped().Model(&models.Transaction{}).
+		Distinct("user_id", "joint_id", "currency").Order("user_id, joint_id, currency").
+		Scan(&chains).Error
+	return chains, err
+}
+
+// FindChain retrieves a wallet's entries in chain order, deleted rows included
+// FindChain bir cüzdanın kayıtlarını zincir sırasıyla getirir, silinmiş satırlar dahil
+func (r *TransactionRepository) FindChain(chain WalletChain) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.GetDB().Unscoped().
+		Where("user_id = ? AND joint_id = ? AND currency = ?", chain.UserID, chain.JointID, chain.Currency).
+		Order("id").Find(&transactions).Error
+	return transactions, err
+}
+
+// SetHash stores the chain fields of an existing row without touching anything else
+// SetHash mevcut bir satırın zincir alanlarını başka hiçbir şeye dokunmadan kaydeder
+func (r *TransactionRepository) SetHash(tx *models.Transaction) error {
+	return r.db.GetDB().Unscoped().Model(tx).UpdateColumns(map[string]interface{}{
+		"prev_hash": tx.PrevHash,
+		"hash":      tx.Hash,
+	}).Error
+}
+
+// HistoryCursor marks the last row of a page; the next page starts right after it
+// HistoryCursor bir sayfanın son satırını işaretler; sonraki sayfa hemen ondan sonra başlar
+type HistoryCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// HistoryFilter narrows a user's history; zero values mean "no filter".
+// JointID is the exception: it picks a joint wallet's history, read with user 0.
+//
+// HistoryFilter kullanıcının geçmişini daraltır; sıfır değerler "filtre yok" demektir.
+// JointID istisnadır: 0 kullanıcısıyla okunan bir ortak cüzdan geçmişini seçer.
+type HistoryFilter struct {
+	JointID        uint
+	Types          []string
+	Currency       string
+	MinAmount      *int64
+	MaxAmount      *int64
+	From           *time.Time
+	To             *time.Time
+	CounterpartyID *uint
+}
+
+// FindPage retrieves up to limit transactions of a user, newest first, after the cursor.
+// Paging by (created_at, id) instead of an offset keeps pages stable while new rows are
+// written: they sort before the cursor and never shift rows between pages.
+//
+// FindPage kullanıcının cursor'dan sonraki en fazla limit işlemini yeniden eskiye getirir.
+// Offset yerine (created_at, id) ile sayfalama, yeni satırlar yazılırken sayfaları sabit tutar:
+// yeni satırlar cursor'dan önce sıralanır ve satırları sayfalar arasında kaydırmaz.
+func (r *TransactionRepository) FindPage(userID uint, filter HistoryFilter, after *HistoryCursor, limit int) ([]models.Transaction, error) {
+	query := r.db.GetDB().Where("user_id = ? AND joint_id = ?", userID, filter.JointID)
+
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if filter.Currency != "" {
+		query = query.Where("currency = ?", filter.Currency)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", *filter.MaxAmount)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+	if filter.CounterpartyID != nil {
+		query = query.Where("target_user_id = ?", *filter.CounterpartyID)
+	}
+	if after != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", after.CreatedAt.UTC(), after.CreatedAt.UTC(), after.ID)
+	}
+
+	var transactions []models.Transaction
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&transactions).Error
+	return transactions, err
+}
+
+// FindByWallet retrieves every transaction of a wallet in the order they were written
+// FindByWallet bir cüzdandaki tüm işlemleri yazıldıkları sırayla getirir
+func (r *TransactionRepository) FindByWallet(chain WalletChain) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.GetDB().Where("user_id = ? AND joint_id = ? AND currency = ?", chain.UserID, chain.JointID, chain.Currency).
+		Order("id").Find(&transactions).Error
+	return transactions, err
+}
+
+// BalanceBefore returns the BalanceAfter of the last wallet entry created before t
+// (0 when the wallet had no entries yet)
+//
+// BalanceBefore cüzdanın t anından önce oluşturulmuş son kaydının BalanceAfter değerini
+// döndürür (cüzdanın henüz kaydı yoksa 0)
+func (r *TransactionRepository) BalanceBefore(userID uint, currency string, t time.Time) (int64, error) {
+	var balances []int64
+	err := r.db.GetDB().Model(&models.Transaction{}).
+		Where("user_id = ? AND currency = ? AND created_at < ?", userID, currency, t.UTC()).
+		Order("id DESC").Limit(1).Pluck("balance_after", &balances).Error
+	if err != nil || len(balances) == 0 {
+		return 0, err
+	}
+	return balances[0], nil
+}
+
+// EachInRange calls fn with batches of a wallet's entries created in [from, to), oldest first.
+// Only one batch is held in memory at a time, so very long histories can be streamed.
+//
+// EachInRange cüzdanın [from, to) aralığında oluşturulmuş kayıtlarını eskiden yeniye gruplar
+// halinde fn'e verir. Bellekte aynı anda tek grup tutulur, böylece çok uzun geçmişler akıtılabilir.
+func (r *TransactionRepository) EachInRange(userID uint, currency string, from, to time.Time, batchSize int, fn func([]models.Transaction) error) error {
+	var batch []models.Transaction
+	return r.db.GetDB().
+		Where("user_id = ? AND currency = ? AND created_at >= ? AND created_at < ?", userID, currency, from.UTC(), to.UTC()).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
+// CurrencyTotal is the sum of amounts in one currency
+// CurrencyTotal bir para birimindeki tutarların toplamıdır
+type CurrencyTotal struct {
+	Currency string
+	Total    int64
+}
+
+// SumSince adds up the amounts of the given types a user moved since t, per currency.
+// Rows the user initiated from a joint wallet count as theirs.
+//
+// SumSince kullanıcının t anından beri taşıdığı verilen türlerdeki tutarları para birimi başına toplar.
+// Kullanıcının ortak cüzdandan başlattığı satırlar onun sayılır.
+func (r *TransactionRepository) SumSince(userID uint, types []string, t time.Time) ([]CurrencyTotal, error) {
+	var totals []CurrencyTotal
+	err := r.db.GetDB().Model(&models.Transaction{}).
+		Select("currency, COALESCE(SUM(amount), 0) AS total").
+		Where("(user_id = ? OR initiated_by = ?) AND type IN ? AND created_at >= ?", userID, userID, types, t.UTC()).
+		Group("currency").Scan(&totals).Error
+	return totals, err
+}
+
+// SumInitiatedSince adds up the amounts of the given types a member initiated from a joint wallet since t
+// SumInitiatedSince bir üyenin t anından beri ortak cüzdandan başlattığı verilen türlerdeki tutarları toplar
+func (r *TransactionRepository) SumInitiatedSince(jointID, userID uint, types []string, t time.Time) (int64, error) {
+	var total int64
+	err := r.db.GetDB().Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("joint_id = ? AND initiated_by = ? AND type IN ? AND created_at >= ?", jointID, userID, types, t.UTC()).
+		Scan(&total).Error
+	return total, err
+}
+
+// FindByID retrieves a single transaction
+// FindByID tek bir işlemi getirir
+func (r *TransactionRepository) FindByID(id uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := r.db.GetDB().First(&transaction, id).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// FindByCorrelation retrieves every row written by one money movement, in write order
+// FindByCorrelation tek bir para hareketinin yazdığı tüm satırları yazılma sırasıyla getirir
+func (r *TransactionRepository) FindByCorrelation(correlationID string) ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	err := r.db.GetDB().Where("user_id = ?", userID).
-		Order("created_at DESC").Find(&transactions).Error
+	err := r.db.GetDB().Where("correlation_id = ?", correlationID).
+		Order("id").Find(&transactions).Error
 	return transactions, err
 }
+
+// AddReversedAmount marks part of a transaction as reversed.
+// The condition in the UPDATE makes it impossible to reverse more than the original amount,
+// even when two reversals race.
+//
+// AddReversedAmount işlemin bir kısmını geri alınmış olarak işaretler.
+// UPDATE içindeki koşul, iki geri alma yarışsa bile orijinal tutardan fazlasının geri alınmasını engeller.
+func (r *TransactionRepository) AddReversedAmount(transaction *models.Transaction, amount int64) error {
+	result := r.db.GetDB().Model(&models.Transaction{}).
+		Where("id = ? AND reversed_amount + ? <= amount", transaction.ID, amount).
+		Update("reversed_amount", gorm.Expr("reversed_amount + ?", amount))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReversalExceedsAmount
+	}
+
+	transaction.ReversedAmount += amount
+	return nil
+}
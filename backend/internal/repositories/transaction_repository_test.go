@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"mini-pay-backend/internal/config"
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/models"
+)
+
+// newTestDB opens a migrated database in a temporary directory
+// newTestDB geçici bir dizinde migrasyonları çalıştırılmış bir veritabanı açar
+func newTestDB(t *testing.T) *database.GormDB {
+	t.Helper()
+	db, err := database.NewGormDB(&config.AppConfig{
+		DBDriver: "sqlite",
+		DBName:   filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.GetDB().DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// TestFindPageWalksEqualTimestamps pages through a history where several rows share a
+// created_at; every row must come exactly once, newest first, whatever the page size.
+//
+// TestFindPageWalksEqualTimestamps birkaç satırın aynı created_at değerini paylaştığı bir
+// geçmişte sayfa sayfa ilerler; sayfa boyutu ne olursa olsun her satır yeniden eskiye tam bir kez gelmelidir.
+func TestFindPageWalksEqualTimestamps(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewTransactionRepository(db)
+
+	// Rows 1-3 and 4-6 share a timestamp; row 7 is alone. Another user's row sits in between.
+	// 1-3 ve 4-6 numaralı satırlar bir zamanı paylaşır; 7 tektir. Arada başka bir kullanıcının satırı vardır.
+	base := time.Date(2026, time.March, 1, 9, 30, 0, 123456789, time.UTC)
+	times := []time.Time{base, base, base, base.Add(time.Second), base.Add(time.Second), base.Add(time.Second), base.Add(time.Minute)}
+	var want []uint
+	for i, at := range times {
+		row := &models.Transaction{UserID: 1, Type: models.TransactionTypeDeposit, Amount: int64(i + 1), Currency: "TRY"}
+		row.CreatedAt = at
+		if err := db.GetDB().Create(row).Error; err != nil {
+			t.Fatalf("create row: %v", err)
+		}
+		want = append([]uint{row.ID}, want...)
+
+		if i == 3 {
+			other := &models.Transaction{UserID: 2, Type: models.TransactionTypeDeposit, Amount: 1, Currency: "TRY"}
+			other.CreatedAt = at
+			if err := db.GetDB().Create(other).Error; err != nil {
+				t.Fatalf("create other row: %v", err)
+			}
+		}
+	}
+
+	for _, limit := range []int{1, 2, 3, 4, 7, 10} {
+		var got []uint
+		var cursor *HistoryCursor
+		for pages := 0; ; pages++ {
+			if pages > len(times) {
+				t.Fatalf("limit %d: paging does not end", limit)
+			}
+			page, err := repo.FindPage(1, HistoryFilter{}, cursor, limit)
+			if err != nil {
+				t.Fatalf("limit %d: find page: %v", limit, err)
+			}
+			for _, row := range page {
+				got = append(got, row.ID)
+			}
+			if len(page) < limit {
+				break
+			}
+			last := page[len(page)-1]
+			cursor = &HistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("limit %d: rows = %v, want %v", limit, got, want)
+		}
+	}
+}
+
+// TestSumSinceAcrossZones writes rows with times in a zone ahead of UTC and sums them from a
+// UTC window start; a row is counted by the instant it was written, not by its wall clock.
+//
+// TestSumSinceAcrossZones UTC'nin ilerisindeki bir dilimde zamanlı satırlar yazar ve bunları bir
+// UTC pencere başlangıcından toplar; bir satır duvar saatine göre değil yazıldığı ana göre sayılır.
+func TestSumSinceAcrossZones(t *testing.T) {
+	repo := NewTransactionRepository(newTestDB(t))
+
+	istanbul := time.FixedZone("+03", 3*60*60)
+	start := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	rows := []struct {
+		at     time.Time
+		amount int64
+	}{
+		// 01:30 in Istanbul is still the previous day in UTC
+		// İstanbul'da 01:30 UTC'de hâlâ önceki gündür
+		{at: time.Date(2026, time.March, 1, 1, 30, 0, 0, istanbul), amount: 100},
+		{at: time.Date(2026, time.March, 1, 3, 30, 0, 0, istanbul), amount: 20},
+		{at: time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC), amount: 3},
+	}
+	for _, row := range rows {
+		tx := &models.Transaction{UserID: 1, Type: models.TransactionTypeDeposit, Amount: row.amount, Currency: "TRY"}
+		tx.CreatedAt = row.at
+		if err := repo.Create(tx); err != nil {
+			t.Fatalf("create row: %v", err)
+		}
+	}
+
+	totals, err := repo.SumSince(1, []string{models.TransactionTypeDeposit}, start)
+	if err != nil {
+		t.Fatalf("sum since: %v", err)
+	}
+	if len(totals) != 1 || totals[0].Total != 23 {
+		t.Errorf("totals = %+v, want 23 TRY", totals)
+	}
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import "mini-pay-backend/internal/database"
+
+// Repositories groups repositories bound to the same connection or transaction
+// Repositories aynı bağlantıya veya transaction'a bağlı repository'leri gruplar
+type Repositories struct {
+	Users        *UserRepository
+	Wallets      *WalletRepository
+	Transactions *TransactionRepository
+	Ledger       *LedgerRepository
+	FxQuotes     *FxQuoteRepository
+	Holds        *HoldRepository
+	Requests     *MoneyRequestRepository
+	Limits       *LimitRepository
+	Pots         *PotRepository
+	Joint        *JointWalletRepository
+	Groups       *GroupRepository
+	Escrows      *EscrowRepository
+	Merchants    *MerchantRepository
+	Schedules    *ScheduleRepository
+}
+
+// NewRepositories builds every repository on top of the given DB
+// NewRepositories tüm repository'leri verilen DB üzerinde oluşturur
+func NewRepositories(db database.DB) *Repositories {
+	return &Repositories{
+		Users:        NewUserRepository(db),
+		Wallets:      NewWalletRepository(db),
+		Transactions: NewTransactionRepository(db),
+		Ledger:       NewLedgerRepository(db),
+		FxQuotes:     NewFxQuoteRepository(db),
+		Holds:        NewHoldRepository(db),
+		Requests:     NewMoneyRequestRepository(db),
+		Limits:       NewLimitRepository(db),
+		Pots:         NewPotRepository(db),
+		Joint:        NewJointWalletRepository(db),
+		Groups:       NewGroupRepository(db),
+		Escrows:      NewEscrowRepository(db),
+		Merchants:    NewMerchantRepository(db),
+		Schedules:    NewScheduleRepository(db),
+	}
+}
+
+// UnitOfWork runs a group of repository calls as one atomic DB transaction
+// UnitOfWork bir grup repository çağrısını tek bir atomik DB transaction'ı olarak çalıştırır
+type UnitOfWork struct {
+	db database.DB
+}
+
+func NewUnitOfWork(db database.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Do calls fn with repositories bound to a new transaction.
+// The transaction commits if fn returns nil and rolls back otherwise.
+//
+// Do, fn'i yeni bir transaction'a bağlı repository'ler ile çağırır.
+// fn nil dönerse transaction commit edilir, aksi halde geri alınır.
+func (u *UnitOfWork) Do(fn func(repos *Repositories) error) error {
+	return u.db.Transaction(func(tx database.DB) error {
+		return fn(NewRepositories(tx))
+	})
+}
@@ -33,3 +33,62 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	}
 	return &user, nil
 }
+
+// Find user by ID
+// Kullanıcıyı ID ile bul
+func (r *UserRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.GetDB().First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Find user by handle (stored without "@")
+// Kullanıcıyı handle ile bul ("@" olmadan saklanır)
+func (r *UserRepository) FindByHandle(handle string) (*models.User, error) {
+	var user models.User
+	if err := r.db.GetDB().Where("handle = ?", handle).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Find user by verified phone number
+// Kullanıcıyı doğrulanmış telefon numarası ile bul
+func (r *UserRepository) FindByPhone(phone string) (*models.User, error) {
+	var user models.User
+	if err := r.db.GetDB().Where("phone = ?", phone).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Find user by the token their QR codes carry
+// Kullanıcıyı QR kodlarının taşıdığı belirteç ile bul
+func (r *UserRepository) FindByQRToken(token string) (*models.User, error) {
+	var user models.User
+	if err := r.db.GetDB().Where("qr_token = ?", token).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Give the user a QR token unless they already have one, then load the token that stands,
+// so two codes generated at once never end up with different tokens
+//
+// Kullanıcının QR belirteci yoksa ona bir tane ver, sonra geçerli olan belirteci yükle;
+// böylece aynı anda üretilen iki kod asla farklı belirteçlerle kalmaz
+func (r *UserRepository) AssignQRToken(user *models.User, token string) error {
+	db := r.db.GetDB()
+	if err := db.Model(&models.User{}).Where("id = ? AND qr_token IS NULL", user.ID).Update("qr_token", token).Error; err != nil {
+		return err
+	}
+	return db.Select("qr_token").First(user, user.ID).Error
+}
+
+// Update only the given columns of a user, so unrelated fields are never overwritten
+// Kullanıcının sadece verilen kolonlarını güncelle, ilgisiz alanların üzerine yazılmaz
+func (r *UserRepository) Update(user *models.User, columns ...string) error {
+	return r.db.GetDB().Model(user).Select(columns).Updates(user).Error
+}
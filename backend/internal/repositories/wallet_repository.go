@@ -1,10 +1,21 @@
 package repositories
 
 import (
+	"errors"
+	"time"
+
 	"mini-pay-backend/internal/database"
 	"mini-pay-backend/internal/models"
 )
 
+// ErrVersionConflict is returned when a wallet was changed by someone else since it was read
+// ErrVersionConflict cüzdan okunduktan sonra başka biri tarafından değiştirildiğinde döner
+var ErrVersionConflict = errors.New("wallet was modified concurrently")
+
+// ErrWalletFrozen is returned when the balance of a frozen wallet would change
+// ErrWalletFrozen dondurulmuş bir cüzdanın bakiyesi değişecekse döner
+var ErrWalletFrozen = errors.New("wallet is frozen")
+
 // WalletRepository handles DB queries related to wallet table
 // WalletRepository, cüzdan ile ilgili DB sorgularını yönetir
 type WalletRepository struct {
@@ -17,20 +28,152 @@ func NewWalletRepository(db database.DB) *WalletRepository {
 	return &WalletRepository{db: db}
 }
 
-// FindByUserID retrieves wallet belonging to the specified user
-// FindByUserID belirtilen kullanıcıya ait cüzdanı getirir
-func (r *WalletRepository) FindByUserID(userID uint) (*models.Wallet, error) {
+// FindByUserAndCurrency retrieves the user's wallet in the given currency
+// FindByUserAndCurrency kullanıcının verilen para birimindeki cüzdanını getirir
+func (r *WalletRepository) FindByUserAndCurrency(userID uint, currency string) (*models.Wallet, error) {
+	var wallet models.Wallet
+	if err := r.db.GetDB().Where("user_id = ? AND joint_id = 0 AND currency = ?", userID, currency).First(&wallet).Error; err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// FindByJoint retrieves the wallet holding a joint wallet's balance
+// FindByJoint bir ortak cüzdanın bakiyesini tutan cüzdanı getirir
+func (r *WalletRepository) FindByJoint(jointID uint) (*models.Wallet, error) {
+	var wallet models.Wallet
+	if err := r.db.GetDB().Where("user_id = 0 AND joint_id = ?", jointID).First(&wallet).Error; err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// FindOrCreate returns the user's wallet in the given currency, opening it if missing
+// FindOrCreate kullanıcının verilen para birimindeki cüzdanını döndürür, yoksa açar
+func (r *WalletRepository) FindOrCreate(userID uint, currency string) (*models.Wallet, error) {
+	wallet := models.Wallet{UserID: userID, Currency: currency}
+	if err := r.db.GetDB().Where("user_id = ? AND joint_id = 0 AND currency = ?", userID, currency).FirstOrCreate(&wallet).Error; err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// FindAllByUserID retrieves every wallet of the user ordered by currency
+// FindAllByUserID kullanıcının tüm cüzdanlarını para birimine göre sıralı getirir
+func (r *WalletRepository) FindAllByUserID(userID uint) ([]models.Wallet, error) {
+	var wallets []models.Wallet
+	err := r.db.GetDB().Where("user_id = ? AND joint_id = 0", userID).Order("currency").Find(&wallets).Error
+	return wallets, err
+}
+
+// UpdateBalance writes a new balance only if the wallet version is unchanged since it was read.
+// On success the wallet struct is updated in place; otherwise ErrVersionConflict is returned.
+// Frozen wallets are rejected with ErrWalletFrozen.
+//
+// UpdateBalance yeni bakiyeyi sadece cüzdan versiyonu okunduğundan beri değişmediyse yazar.
+// Başarılı olursa struct yerinde güncellenir; aksi halde ErrVersionConflict döner.
+// Dondurulmuş cüzdanlar ErrWalletFrozen ile reddedilir.
+func (r *WalletRepository) UpdateBalance(wallet *models.Wallet, balance int64) error {
+	if wallet.Frozen {
+		return ErrWalletFrozen
+	}
+
+	now := time.Now().UTC()
+	result := r.db.GetDB().Model(&models.Wallet{}).
+		Where("id = ? AND version = ?", wallet.ID, wallet.Version).
+		Updates(map[string]interface{}{
+			"balance":    balance,
+			"version":    wallet.Version + 1,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	wallet.Balance = balance
+	wallet.Version++
+	wallet.UpdatedAt = now
+	return nil
+}
+
+// UpdateHeld changes the reserved amount under the same version guard as UpdateBalance.
+// A frozen wallet cannot reserve more, but it can still let go of a hold: the money
+// only becomes available again and stays in the frozen wallet.
+//
+// UpdateHeld ayrılmış tutarı UpdateBalance ile aynı versiyon korumasıyla değiştirir.
+// Dondurulmuş cüzdan daha fazla ayıramaz ama bir provizyonu yine de bırakabilir: para
+// sadece tekrar kullanılabilir olur ve dondurulmuş cüzdanda kalır.
+func (r *WalletRepository) UpdateHeld(wallet *models.Wallet, held int64) error {
+	if wallet.Frozen && held > wallet.Held {
+		return ErrWalletFrozen
+	}
+
+	now := time.Now().UTC()
+	result := r.db.GetDB().Model(&models.Wallet{}).
+		Where("id = ? AND version = ?", wallet.ID, wallet.Version).
+		Updates(map[string]interface{}{
+			"held":       held,
+			"version":    wallet.Version + 1,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	wallet.Held = held
+	wallet.Version++
+	wallet.UpdatedAt = now
+	return nil
+}
+
+// SetFrozen freezes or unfreezes a wallet. The version is bumped, so a money movement
+// that read the wallet before the freeze conflicts and re-reads it.
+//
+// SetFrozen cüzdanı dondurur veya çözer. Versiyon artırılır, böylece cüzdanı dondurmadan
+// önce okumuş bir para hareketi çakışır ve cüzdanı yeniden okur.
+func (r *WalletRepository) SetFrozen(wallet *models.Wallet, frozen bool, reason string) error {
+	result := r.db.GetDB().Model(&models.Wallet{}).
+		Where("id = ? AND version = ?", wallet.ID, wallet.Version).
+		Updates(map[string]interface{}{
+			"frozen":        frozen,
+			"frozen_reason": reason,
+			"version":       wallet.Version + 1,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	wallet.Frozen = frozen
+	wallet.FrozenReason = reason
+	wallet.Version++
+	return nil
+}
+
+// FindByID retrieves a single wallet
+// FindByID tek bir cüzdanı getirir
+func (r *WalletRepository) FindByID(id uint) (*models.Wallet, error) {
 	var wallet models.Wallet
-	if err := r.db.GetDB().Where("user_id = ?", userID).First(&wallet).Error; err != nil {
+	if err := r.db.GetDB().First(&wallet, id).Error; err != nil {
 		return nil, err
 	}
 	return &wallet, nil
 }
 
-// Update saves wallet changes into the database
-// Update cüzdandaki değişiklikleri veritabanına kaydeder
-func (r *WalletRepository) Update(wallet *models.Wallet) error {
-	return r.db.GetDB().Save(wallet).Error
+// FindAllIDs returns the id of every wallet in ascending order
+// FindAllIDs tüm cüzdanların id'lerini artan sırada döndürür
+func (r *WalletRepository) FindAllIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.GetDB().Model(&models.Wallet{}).Order("id").Pluck("id", &ids).Error
+	return ids, err
 }
 
 // Create creates a new wallet record
@@ -1,29 +1,95 @@
 package routes
 
 import (
+	"mini-pay-backend/internal/clock"
+	"mini-pay-backend/internal/config"
 	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/fees"
 	"mini-pay-backend/internal/handlers"
 	"mini-pay-backend/internal/logger"
 	"mini-pay-backend/internal/middleware"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/rates"
 	"mini-pay-backend/internal/repositories"
 	"mini-pay-backend/internal/services"
+	"mini-pay-backend/internal/sms"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func RegisterRoutes(app *fiber.App, db database.DB, log logger.Logger) {
+func RegisterRoutes(app *fiber.App, cfg *config.AppConfig, db database.DB, log logger.Logger) error {
 
 	// Build repository
 	// Repository oluştur
 	userRepo := repositories.NewUserRepository(db)
 	walletRepo := repositories.NewWalletRepository(db)
 	transactionRepo := repositories.NewTransactionRepository(db)
+	ledgerRepo := repositories.NewLedgerRepository(db)
+	idempotencyRepo := repositories.NewIdempotencyRepository(db)
+	fxQuoteRepo := repositories.NewFxQuoteRepository(db)
+	holdRepo := repositories.NewHoldRepository(db)
+	scheduleRepo := repositories.NewScheduleRepository(db)
+	moneyRequestRepo := repositories.NewMoneyRequestRepository(db)
+	limitRepo := repositories.NewLimitRepository(db)
+	potRepo := repositories.NewPotRepository(db)
+	jointRepo := repositories.NewJointWalletRepository(db)
+	groupRepo := repositories.NewGroupRepository(db)
+	escrowRepo := repositories.NewEscrowRepository(db)
+	merchantRepo := repositories.NewMerchantRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+
+	// Build exchange rate provider (static table, works offline)
+	// Döviz kuru sağlayıcısını oluştur (statik tablo, çevrimdışı çalışır)
+	rateProvider, err := rates.NewStaticProvider(cfg.RatesFile)
+	if err != nil {
+		return err
+	}
+
+	// Load the fee schedule (built-in table unless FEES_FILE is set)
+	// Ücret tablosunu yükle (FEES_FILE ayarlı değilse gömülü tablo)
+	feeSchedule, err := fees.LoadSchedule(cfg.FeesFile)
+	if err != nil {
+		return err
+	}
 
 	// Build service
 	// Service oluştur
-	authService := services.NewAuthService(userRepo, walletRepo, log)
-	transactionService := services.NewTransactionService(transactionRepo, log)
-	walletService := services.NewWalletService(walletRepo, transactionService, log)
+	authService := services.NewAuthService(userRepo, walletRepo, cfg.DefaultCurrency, log)
+	transactionService := services.NewTransactionService(transactionRepo, userRepo, log)
+	ledgerService := services.NewLedgerService(ledgerRepo, log)
+	limitService := services.NewLimitService(limitRepo, transactionRepo, userRepo, rateProvider, map[string]models.Limits{
+		models.LimitOperationWithdraw: {PerTransaction: cfg.LimitWithdrawPerTransaction, Daily: cfg.LimitWithdrawDaily, Monthly: cfg.LimitWithdrawMonthly},
+		models.LimitOperationTransfer: {PerTransaction: cfg.LimitTransferPerTransaction, Daily: cfg.LimitTransferDaily, Monthly: cfg.LimitTransferMonthly},
+	}, cfg.DefaultCurrency, log)
+	feeService := services.NewFeeService(feeSchedule, ledgerService, transactionService, cfg.DefaultCurrency, log)
+	walletService := services.NewWalletService(uow, walletRepo, transactionService, ledgerService, limitService, feeService, cfg.DefaultCurrency, log)
+	fxService := services.NewFxService(uow, fxQuoteRepo, rateProvider, ledgerService, transactionService, cfg.FxSpreadBps, cfg.FxQuoteTTL, log)
+	holdService := services.NewHoldService(uow, holdRepo, ledgerService, transactionService, limitService, cfg.DefaultCurrency, cfg.HoldTTL, log)
+	scheduleService := services.NewScheduleService(uow, scheduleRepo, userRepo, walletService, clock.System{}, cfg.DefaultCurrency, cfg.ScheduleRetryDelay, cfg.ScheduleMaxAttempts, log)
+	moneyRequestService := services.NewMoneyRequestService(uow, moneyRequestRepo, userRepo, walletService, cfg.DefaultCurrency, cfg.MoneyRequestTTL, log)
+	reversalService := services.NewReversalService(uow, ledgerService, transactionService, cfg.ReversalWindow, log)
+	reconciliationService := services.NewReconciliationService(uow, walletRepo, log)
+	chainService := services.NewChainService(transactionRepo, log)
+	profileService := services.NewProfileService(uow, userRepo, sms.NewLogSender(log), log)
+	payeeService := services.NewPayeeService(userRepo, log)
+	potService := services.NewPotService(uow, potRepo, ledgerService, transactionService, cfg.DefaultCurrency, log)
+	jointService := services.NewJointWalletService(uow, jointRepo, walletRepo, ledgerService, transactionService, feeService, limitService, cfg.DefaultCurrency, log)
+	escrowService := services.NewEscrowService(uow, escrowRepo, ledgerService, transactionService, limitService, feeService, cfg.DefaultCurrency, cfg.EscrowReleaseAfter, log)
+	merchantService := services.NewMerchantService(uow, merchantRepo, walletService, cfg.DefaultCurrency, log)
+	qrService := services.NewQRService(merchantRepo, userRepo, merchantService, walletService, cfg.DefaultCurrency, cfg.QRCountryCode, cfg.QRMerchantCity, log)
+	groupService := services.NewGroupService(uow, groupRepo, walletService, cfg.DefaultCurrency, log)
+	statementService := services.NewStatementService(walletRepo, transactionRepo, cfg.DefaultCurrency, log)
+
+	// Background jobs: release expired holds, requests and payment links and due escrows, run due scheduled transfers
+	// Arka plan işleri: süresi dolan provizyon, istek ve ödeme bağlantılarını kapat, zamanı gelen emanetleri serbest bırak ve transferleri çalıştır
+	go holdService.RunExpiry(cfg.HoldSweepInterval)
+	go moneyRequestService.RunExpiry(cfg.HoldSweepInterval)
+	go merchantService.RunExpiry(cfg.HoldSweepInterval)
+	go escrowService.RunAutoRelease(cfg.HoldSweepInterval)
+	go scheduleService.RunScheduler(cfg.SchedulerInterval)
+	if cfg.ReconcileInterval > 0 {
+		go reconciliationService.RunJob(cfg.ReconcileInterval, cfg.ReconcileFreeze, cfg.ReconcileReportFile)
+	}
 
 	// Register routes
 	// Route’ları bağla
@@ -41,12 +107,123 @@ func RegisterRoutes(app *fiber.App, db database.DB, log logger.Logger) {
 		})
 	})
 
+	// Profile: how other users can find you (handle, verified phone)
+	// Profil: diğer kullanıcıların sizi nasıl bulacağı (handle, doğrulanmış telefon)
+	profile := app.Group("/me", middleware.AuthMiddleware())
+	profile.Put("/handle", handlers.SetHandle(profileService))
+	profile.Post("/phone", handlers.StartPhoneVerification(profileService))
+	profile.Post("/phone/verify", handlers.VerifyPhone(profileService))
+
+	// Hosted checkout: anyone with the link can see what they are about to pay
+	// Barındırılan ödeme sayfası: bağlantıya sahip herkes ne ödeyeceğini görebilir
+	app.Get("/pay/:code", handlers.GetCheckout(merchantService))
+
 	auth := app.Group("/wallet", middleware.AuthMiddleware())
-	auth.Get("/balance", handlers.GetBalance(walletService))
-	auth.Post("/deposit", handlers.Deposit(walletService))
-	auth.Post("/withdraw", handlers.Withdraw(walletService))
-	auth.Post("/transfer", handlers.Transfer(walletService, db.GetDB()))
+	auth.Get("/balance", handlers.GetBalance(walletService, potService))
+	auth.Post("/wallets", handlers.OpenWallet(walletService))
+
+	// Money movements accept an Idempotency-Key header so retries are safe
+	// Para hareketleri Idempotency-Key header'ı kabul eder, tekrar denemeler güvenlidir
+	idempotent := middleware.IdempotencyMiddleware(idempotencyRepo, cfg.IdempotencyClaimTTL, log)
+	auth.Post("/deposit", idempotent, handlers.Deposit(walletService))
+	auth.Post("/withdraw", idempotent, handlers.Withdraw(walletService))
+	auth.Post("/transfer", idempotent, handlers.Transfer(walletService, payeeService))
+	auth.Get("/payees/lookup", handlers.LookupPayee(payeeService))
+	auth.Get("/transactions/:id", handlers.GetTransaction(transactionService))
+	auth.Post("/transactions/:id/reverse", idempotent, handlers.ReverseTransaction(reversalService))
+	auth.Post("/fx/quotes", handlers.CreateQuote(fxService))
+	auth.Post("/convert", idempotent, handlers.Convert(fxService))
+	auth.Get("/holds", handlers.GetHolds(holdService))
+	auth.Post("/holds", idempotent, handlers.AuthorizeHold(holdService))
+	auth.Post("/holds/:id/capture", idempotent, handlers.CaptureHold(holdService))
+	auth.Post("/holds/:id/void", idempotent, handlers.VoidHold(holdService))
+
+	auth.Get("/schedules", handlers.ListSchedules(scheduleService))
+	auth.Post("/schedules", idempotent, handlers.CreateSchedule(scheduleService))
+	auth.Get("/schedules/:id", handlers.GetSchedule(scheduleService))
+	auth.Put("/schedules/:id", handlers.UpdateSchedule(scheduleService))
+	auth.Delete("/schedules/:id", handlers.CancelSchedule(scheduleService))
+
+	auth.Get("/requests", handlers.ListMoneyRequests(moneyRequestService))
+	auth.Post("/requests", idempotent, handlers.CreateMoneyRequest(moneyRequestService))
+	auth.Post("/requests/:id/accept", idempotent, handlers.AcceptMoneyRequest(moneyRequestService))
+	auth.Post("/requests/:id/decline", handlers.DeclineMoneyRequest(moneyRequestService))
+	auth.Post("/requests/:id/cancel", handlers.CancelMoneyRequest(moneyRequestService))
+
+	auth.Get("/pots", handlers.ListPots(potService))
+	auth.Post("/pots", handlers.CreatePot(potService))
+	auth.Get("/pots/:id", handlers.GetPot(potService))
+	auth.Post("/pots/:id/move-in", idempotent, handlers.MoveIntoPot(potService))
+	auth.Post("/pots/:id/move-out", idempotent, handlers.MoveOutOfPot(potService))
+	auth.Post("/pots/:id/close", idempotent, handlers.ClosePot(potService))
+
+	auth.Get("/joint", handlers.ListJointWallets(jointService))
+	auth.Post("/joint", handlers.CreateJointWallet(jointService))
+	auth.Get("/joint/:id", handlers.GetJointWallet(jointService))
+	auth.Put("/joint/:id/policy", handlers.SetJointPolicy(jointService))
+	auth.Get("/joint/:id/history", handlers.GetJointWalletHistory(jointService))
+	auth.Post("/joint/:id/members", handlers.InviteJointMember(jointService, payeeService))
+	auth.Put("/joint/:id/members/:user_id", handlers.UpdateJointMember(jointService))
+	auth.Delete("/joint/:id/members/:user_id", handlers.RemoveJointMember(jointService))
+	auth.Post("/joint/:id/accept", handlers.AcceptJointInvitation(jointService))
+	auth.Post("/joint/:id/decline", handlers.DeclineJointInvitation(jointService))
+	auth.Post("/joint/:id/contribute", idempotent, handlers.ContributeToJointWallet(jointService))
+	auth.Post("/joint/:id/withdraw", idempotent, handlers.WithdrawFromJointWallet(jointService))
+	auth.Post("/joint/:id/transfer", idempotent, handlers.TransferFromJointWallet(jointService, payeeService))
+	auth.Get("/joint/:id/withdrawals", handlers.ListJointWithdrawals(jointService))
+	auth.Post("/joint/:id/withdrawals/:withdrawal_id/approve", idempotent, handlers.ApproveJointWithdrawal(jointService))
+	auth.Post("/joint/:id/withdrawals/:withdrawal_id/reject", handlers.RejectJointWithdrawal(jointService))
+
+	auth.Get("/groups", handlers.ListGroups(groupService))
+	auth.Post("/groups", handlers.CreateGroup(groupService))
+	auth.Get("/groups/:id", handlers.GetGroup(groupService))
+	auth.Post("/groups/:id/members", handlers.AddGroupMember(groupService, payeeService))
+	auth.Delete("/groups/:id/members/:user_id", handlers.RemoveGroupMember(groupService))
+	auth.Get("/groups/:id/expenses", handlers.ListGroupExpenses(groupService))
+	auth.Post("/groups/:id/expenses", handlers.AddGroupExpense(groupService))
+	auth.Delete("/groups/:id/expenses/:expense_id", handlers.DeleteGroupExpense(groupService))
+	auth.Get("/groups/:id/settlements", handlers.ListGroupSettlements(groupService))
+	auth.Post("/groups/:id/settle-up", idempotent, handlers.SettleUpGroup(groupService))
+
+	auth.Get("/escrows", handlers.ListEscrows(escrowService))
+	auth.Post("/escrows", idempotent, handlers.CreateEscrow(escrowService, payeeService))
+	auth.Get("/escrows/:id", handlers.GetEscrow(escrowService))
+	auth.Post("/escrows/:id/confirm", idempotent, handlers.ConfirmEscrow(escrowService))
+	auth.Post("/escrows/:id/cancel", idempotent, handlers.CancelEscrow(escrowService))
+	auth.Post("/escrows/:id/dispute", handlers.DisputeEscrow(escrowService))
+
+	auth.Get("/merchant", handlers.GetMerchant(merchantService))
+	auth.Post("/merchant", handlers.RegisterMerchant(merchantService))
+	auth.Put("/merchant", handlers.UpdateMerchant(merchantService))
+	auth.Get("/merchant/links", handlers.ListPaymentLinks(merchantService))
+	auth.Post("/merchant/links", handlers.CreatePaymentLink(merchantService))
+	auth.Get("/merchant/links/:id", handlers.GetPaymentLink(merchantService))
+	auth.Post("/merchant/links/:id/cancel", handlers.CancelPaymentLink(merchantService))
+	auth.Post("/pay/:code", idempotent, handlers.PayPaymentLink(merchantService))
+
+	auth.Get("/qr", handlers.GetQRCode(qrService))
+	auth.Post("/qr", idempotent, handlers.CreateQRCheckout(qrService))
+	auth.Post("/qr/scan", handlers.ScanQRCode(qrService))
+	auth.Post("/qr/pay", idempotent, handlers.PayQRCode(qrService))
+
 	auth.Get("/history", handlers.GetTransactionHistory(transactionService))
+	auth.Get("/statements", handlers.GetStatement(statementService))
+	auth.Get("/limits", handlers.GetLimits(limitService))
+	auth.Get("/fees", handlers.GetFeeQuote(feeService))
+
+	// Admin routes (flagged users only)
+	// Admin route'ları (sadece işaretli kullanıcılar)
+	admin := app.Group("/admin", middleware.AuthMiddleware(), middleware.AdminMiddleware(userRepo))
+	admin.Post("/transactions/:id/reverse", idempotent, handlers.AdminReverseTransaction(reversalService))
+	admin.Get("/transactions/verify", handlers.VerifyTransactionChain(chainService))
+	admin.Post("/reconcile", handlers.RunReconciliation(reconciliationService))
+	admin.Post("/wallets/:id/unfreeze", handlers.UnfreezeWallet(reconciliationService))
+	admin.Get("/users/:id/limits", handlers.AdminGetLimits(limitService))
+	admin.Put("/users/:id/limits/:operation", handlers.AdminSetLimits(limitService))
+	admin.Delete("/users/:id/limits/:operation", handlers.AdminClearLimits(limitService))
+	admin.Get("/escrows", handlers.AdminListEscrows(escrowService))
+	admin.Post("/escrows/:id/release", idempotent, handlers.AdminReleaseEscrow(escrowService))
+	admin.Post("/escrows/:id/refund", idempotent, handlers.AdminRefundEscrow(escrowService))
 
 	// Test endpoint
 	app.Get("/", func(c *fiber.Ctx) error {
@@ -55,4 +232,6 @@ func RegisterRoutes(app *fiber.App, db database.DB, log logger.Logger) {
 			"status":  "ok",
 		})
 	})
+
+	return nil
 }
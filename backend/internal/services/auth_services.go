@@ -11,19 +11,21 @@ import (
 )
 
 type AuthService struct {
-	userRepo   *repositories.UserRepository
-	walletRepo *repositories.WalletRepository
-	log        logger.Logger
+	userRepo        *repositories.UserRepository
+	walletRepo      *repositories.WalletRepository
+	defaultCurrency string
+	log             logger.Logger
 }
 
-// Updated constructor to inject walletRepo too
-// Constructor güncellendi → walletRepo enjekte ediliyor
+// Updated constructor to inject walletRepo and the currency of the first wallet
+// Constructor güncellendi → walletRepo ve ilk cüzdanın para birimi enjekte ediliyor
 func NewAuthService(
 	userRepo *repositories.UserRepository,
 	walletRepo *repositories.WalletRepository,
+	defaultCurrency string,
 	log logger.Logger,
 ) *AuthService {
-	return &AuthService{userRepo: userRepo, walletRepo: walletRepo, log: log}
+	return &AuthService{userRepo: userRepo, walletRepo: walletRepo, defaultCurrency: defaultCurrency, log: log}
 }
 
 // Register handles user creation + wallet creation
@@ -46,11 +48,12 @@ func (s *AuthService) Register(email, password string) error {
 		return err
 	}
 
-	// Create wallet with balance = 0
-	// Cüzdan oluşturulur, başlangıç bakiyesi = 0
+	// Create wallet in the default currency with balance = 0
+	// Varsayılan para biriminde cüzdan oluşturulur, başlangıç bakiyesi = 0
 	wallet := models.Wallet{
-		UserID:  user.ID,
-		Balance: 0,
+		UserID:   user.ID,
+		Currency: s.defaultCurrency,
+		Balance:  0,
 	}
 
 	if err := s.walletRepo.Create(&wallet); err != nil {
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+)
+
+// TestVerifyFindsTampering edits a wallet's history behind the service's back and checks
+// that Verify names the wallet, including when the hashes were blanked before the edit.
+//
+// TestVerifyFindsTampering bir cüzdanın geçmişini servisin arkasından düzenler ve Verify'ın
+// cüzdanı bulduğunu, hash'ler düzenlemeden önce silinmiş olsa bile, kontrol eder.
+func TestVerifyFindsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper string
+		want   string
+	}{
+		{
+			name:   "edited amount",
+			tamper: "UPDATE transactions SET amount = amount + 100 WHERE user_id = ?",
+			want:   ChainHashMismatch,
+		},
+		{
+			name:   "blanked hashes and edited amount",
+			tamper: "UPDATE transactions SET hash = '', prev_hash = '', amount = amount + 100 WHERE user_id = ?",
+			want:   ChainUnhashed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			victim := env.newUser(t, "victim@example.com")
+			other := env.newUser(t, "other@example.com")
+			if err := env.walletService.Deposit(victim, "TRY", mustDecimal(t, "50.00")); err != nil {
+				t.Fatalf("deposit: %v", err)
+			}
+			if _, err := env.walletService.Transfer(victim, other, "TRY", mustDecimal(t, "20.00")); err != nil {
+				t.Fatalf("transfer: %v", err)
+			}
+
+			if err := env.db.GetDB().Exec(tt.tamper, victim).Error; err != nil {
+				t.Fatalf("tamper: %v", err)
+			}
+
+			report, err := NewChainService(env.transactionRepo, nopLogger{}).Verify()
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if len(report.Breaks) != 1 {
+				t.Fatalf("breaks = %+v, want one for the victim's wallet", report.Breaks)
+			}
+			if got := report.Breaks[0]; got.UserID != victim || got.Reason != tt.want {
+				t.Errorf("break = %+v, want user %d with reason %s", got, victim, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,527 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mini-pay-backend/internal/fees"
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// Escrow text limits keep descriptions and notes short enough for lists and statements
+// Emanet metin sınırları açıklama ve notları listeler ve hesap özetleri için kısa tutar
+const (
+	maxEscrowDescLength = 140
+	maxEscrowNoteLength = 280
+)
+
+// ErrEscrowParty is returned when the user's side of the escrow does not allow the action
+// ErrEscrowParty kullanıcının emanetteki tarafı işleme izin vermediğinde döner
+var ErrEscrowParty = errors.New("only the other party of this escrow can do this")
+
+// escrowOpen are the statuses an escrow can be closed from
+// escrowOpen bir emanetin kapatılabileceği durumlardır
+var escrowOpen = []string{models.EscrowStatusFunded, models.EscrowStatusDisputed}
+
+// EscrowResult is a new escrow with the transfer fee the buyer paid for it
+// EscrowResult alıcının ödediği transfer ücretiyle birlikte yeni bir emanettir
+type EscrowResult struct {
+	Escrow *models.Escrow `json:"escrow"`
+	Fee    *Fee           `json:"fee"`
+}
+
+// EscrowService runs marketplace payments between users. The buyer's money moves into
+// an escrow account when the escrow is funded and leaves it exactly once: to the seller
+// on confirmation, auto-release or an admin decision, or back to the buyer on
+// cancellation or an admin decision. A dispute stops the auto-release until an admin
+// resolves it.
+//
+// EscrowService kullanıcılar arası pazar yeri ödemelerini yürütür. Alıcının parası emanet
+// fonlandığında bir emanet hesabına geçer ve oradan tam bir kez çıkar: onay, otomatik
+// serbest bırakma veya admin kararıyla satıcıya, ya da iptal veya admin kararıyla alıcıya
+// geri. Bir itiraz, admin çözene kadar otomatik serbest bırakmayı durdurur.
+type EscrowService struct {
+	uow                *repositories.UnitOfWork
+	escrowRepo         *repositories.EscrowRepository
+	ledgerService      *LedgerService
+	transactionService *TransactionService
+	limitService       *LimitService
+	feeService         *FeeService
+	defaultCurrency    string
+	releaseAfter       time.Duration
+	log                logger.Logger
+}
+
+// Constructor for EscrowService; releaseAfter is used when the buyer gives no release time
+// EscrowService için constructor; alıcı serbest bırakma süresi vermezse releaseAfter kullanılır
+func NewEscrowService(
+	uow *repositories.UnitOfWork,
+	escrowRepo *repositories.EscrowRepository,
+	ledgerService *LedgerService,
+	transactionService *TransactionService,
+	limitService *LimitService,
+	feeService *FeeService,
+	defaultCurrency string,
+	releaseAfter time.Duration,
+	log logger.Logger,
+) *EscrowService {
+	return &EscrowService{
+		uow:                uow,
+		escrowRepo:         escrowRepo,
+		ledgerService:      ledgerService,
+		transactionService: transactionService,
+		limitService:       limitService,
+		feeService:         feeService,
+		defaultCurrency:    defaultCurrency,
+		releaseAfter:       releaseAfter,
+		log:                log,
+	}
+}
+
+// Create moves the buyer's money into a new escrow for the seller.
+// It is priced and limited like a transfer: the buyer pays the transfer fee on top
+// and the amount counts toward their transfer limits.
+//
+// Create alıcının parasını satıcı için yeni bir emanete taşır.
+// Bir transfer gibi fiyatlanır ve sınırlanır: alıcı transfer ücretini ayrıca öder
+// ve tutar transfer limitlerine sayılır.
+func (s *EscrowService) Create(buyerID, sellerID uint, currency string, value models.Decimal, description string, releaseAfter time.Duration) (*EscrowResult, error) {
+
+	if buyerID == sellerID {
+		return nil, errors.New("cannot open an escrow with self")
+	}
+	if value.IsZero() {
+		return nil, errors.New("invalid escrow amount")
+	}
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil, errors.New("escrow description is required")
+	}
+	if len(description) > maxEscrowDescLength {
+		return nil, fmt.Errorf("escrow description must be at most %d characters", maxEscrowDescLength)
+	}
+	if releaseAfter <= 0 {
+		releaseAfter = s.releaseAfter
+	}
+
+	currency, amount, err := resolveAmount(currency, s.defaultCurrency, value)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *EscrowResult
+	err = retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			ledger := s.ledgerService.WithTx(repos)
+			history := s.transactionService.WithTx(repos)
+
+			if _, err := repos.Users.FindByID(sellerID); err != nil {
+				return errors.New("seller not found")
+			}
+
+			buyerWallet, err := repos.Wallets.FindByUserAndCurrency(buyerID, currency)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("you have no %s wallet to pay from", currency)
+			}
+			if err != nil {
+				return err
+			}
+			sellerWallet, err := repos.Wallets.FindOrCreate(sellerID, currency)
+			if err != nil {
+				return err
+			}
+
+			fee, err := s.feeService.Quote(fees.OperationTransfer, currency, amount)
+			if err != nil {
+				return err
+			}
+			total, err := models.NewMoney(amount, currency).Add(fee.Amount)
+			if err != nil {
+				return err
+			}
+			if buyerWallet.Available() < total.Minor {
+				return ErrInsufficientFunds
+			}
+			if err := s.limitService.Check(repos, buyerID, models.LimitOperationTransfer, currency, amount); err != nil {
+				return err
+			}
+
+			escrow := &models.Escrow{
+				BuyerID:     buyerID,
+				SellerID:    sellerID,
+				Currency:    currency,
+				Amount:      amount,
+				Description: description,
+				Status:      models.EscrowStatusFunded,
+				ReleaseAt:   time.Now().Add(releaseAfter),
+			}
+			if err := repos.Escrows.Create(escrow); err != nil {
+				return err
+			}
+
+			buyerAccount, err := ledger.WalletAccount(buyerWallet)
+			if err != nil {
+				return err
+			}
+			escrowAccount, err := ledger.EscrowAccount(escrow)
+			if err != nil {
+				return err
+			}
+
+			if err := repos.Wallets.UpdateBalance(buyerWallet, buyerWallet.Balance-amount); err != nil {
+				return err
+			}
+
+			// POST LEDGER ENTRY: buyer down, escrow up
+			// Defter kaydı: alıcı azalır, emanet artar
+			if err := ledger.Post(models.TransactionTypeEscrowFunded, fmt.Sprintf("escrow:%d funded by user:%d", escrow.ID, buyerID),
+				LedgerLine{Account: buyerAccount, Amount: -amount},
+				LedgerLine{Account: escrowAccount, Amount: amount},
+			); err != nil {
+				return err
+			}
+
+			correlationID := models.NewCorrelationID()
+			if err := history.RecordEntry(&models.Transaction{
+				UserID:        buyerID,
+				Type:          models.TransactionTypeEscrowFunded,
+				Amount:        amount,
+				Currency:      currency,
+				TargetUserID:  &sellerID,
+				BalanceAfter:  buyerWallet.Balance,
+				EscrowID:      &escrow.ID,
+				CorrelationID: correlationID,
+				Reason:        description,
+			}); err != nil {
+				return err
+			}
+
+			// The seller sees the sale right away; their balance moves on release
+			// Satıcı satışı hemen görür; bakiyesi serbest bırakmada değişir
+			if err := history.RecordEntry(&models.Transaction{
+				UserID:        sellerID,
+				Type:          models.TransactionTypeEscrowPending,
+				Amount:        amount,
+				Currency:      currency,
+				TargetUserID:  &buyerID,
+				BalanceAfter:  sellerWallet.Balance,
+				EscrowID:      &escrow.ID,
+				CorrelationID: correlationID,
+				Reason:        description,
+			}); err != nil {
+				return err
+			}
+
+			if err := s.feeService.Charge(repos, buyerWallet, fee, correlationID, nil); err != nil {
+				return err
+			}
+
+			result = &EscrowResult{Escrow: escrow, Fee: fee}
+			return nil
+		})
+	})
+	if err != nil {
+		s.log.Error("Escrow funding failed", map[string]interface{}{
+			"buyer_id":  buyerID,
+			"seller_id": sellerID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Escrow funded", map[string]interface{}{
+		"escrow_id": result.Escrow.ID,
+		"buyer_id":  buyerID,
+		"seller_id": sellerID,
+		"amount":    amount,
+		"currency":  currency,
+	})
+
+	return result, nil
+}
+
+// List returns the escrows the user bought or sold in
+// List kullanıcının alıcı veya satıcı olduğu emanetleri döndürür
+func (s *EscrowService) List(userID uint) ([]models.Escrow, error) {
+	return s.escrowRepo.FindByUser(userID)
+}
+
+// Get returns one escrow of the user; other users are told it does not exist
+// Get kullanıcının bir emanetini döndürür; diğer kullanıcılara emanet yokmuş gibi cevap verilir
+func (s *EscrowService) Get(userID, escrowID uint) (*models.Escrow, error) {
+	escrow, err := s.escrowRepo.FindByID(escrowID)
+	if err != nil {
+		return nil, err
+	}
+	if escrow.BuyerID != userID && escrow.SellerID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return escrow, nil
+}
+
+// Confirm lets the buyer accept the goods and release the money to the seller.
+// A disputed escrow can only be settled by an admin.
+//
+// Confirm alıcının malı kabul edip parayı satıcıya serbest bırakmasını sağlar.
+// İtirazlı bir emaneti yalnızca bir admin sonuçlandırabilir.
+func (s *EscrowService) Confirm(buyerID, escrowID uint) (*models.Escrow, error) {
+	return s.settle(escrowID, "Escrow released", func(escrow *models.Escrow) error {
+		if escrow.BuyerID != buyerID {
+			return ErrEscrowParty
+		}
+		return nil
+	}, func(repos *repositories.Repositories, escrow *models.Escrow) error {
+		return s.payout(repos, escrow, []string{models.EscrowStatusFunded}, models.EscrowStatusReleased, models.EscrowResolutionConfirmed, &buyerID, "")
+	})
+}
+
+// Cancel lets the seller call off the sale and refund the buyer, also while disputed
+// Cancel satıcının satışı iptal edip alıcıya iade yapmasını sağlar, itirazlıyken de
+func (s *EscrowService) Cancel(sellerID, escrowID uint, note string) (*models.Escrow, error) {
+	note = strings.TrimSpace(note)
+	if len(note) > maxEscrowNoteLength {
+		return nil, fmt.Errorf("note must be at most %d characters", maxEscrowNoteLength)
+	}
+
+	return s.settle(escrowID, "Escrow cancelled", func(escrow *models.Escrow) error {
+		if escrow.SellerID != sellerID {
+			return ErrEscrowParty
+		}
+		return nil
+	}, func(repos *repositories.Repositories, escrow *models.Escrow) error {
+		return s.payout(repos, escrow, escrowOpen, models.EscrowStatusRefunded, models.EscrowResolutionCancelled, &sellerID, note)
+	})
+}
+
+// Dispute lets either party stop the automatic release until an admin decides
+// Dispute iki taraftan birinin, bir admin karar verene kadar otomatik serbest bırakmayı durdurmasını sağlar
+func (s *EscrowService) Dispute(userID, escrowID uint, reason string) (*models.Escrow, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("dispute reason is required")
+	}
+	if len(reason) > maxEscrowNoteLength {
+		return nil, fmt.Errorf("dispute reason must be at most %d characters", maxEscrowNoteLength)
+	}
+
+	escrow, err := s.Get(userID, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	if escrow.Status != models.EscrowStatusFunded {
+		return nil, repositories.ErrEscrowChanged
+	}
+	if err := s.escrowRepo.Dispute(escrow, userID, reason); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Escrow disputed", map[string]interface{}{
+		"escrow_id": escrow.ID,
+		"user_id":   userID,
+	})
+
+	return escrow, nil
+}
+
+// AdminList returns the escrows with a status, disputed ones by default
+// AdminList bir durumdaki emanetleri döndürür, varsayılan olarak itirazlı olanları
+func (s *EscrowService) AdminList(status string) ([]models.Escrow, error) {
+	if status == "" {
+		status = models.EscrowStatusDisputed
+	}
+	switch status {
+	case models.EscrowStatusFunded, models.EscrowStatusDisputed, models.EscrowStatusReleased, models.EscrowStatusRefunded:
+	default:
+		return nil, errors.New("status must be funded, disputed, released or refunded")
+	}
+	return s.escrowRepo.FindByStatus(status)
+}
+
+// AdminResolve settles an open escrow by decision of an admin, releasing it to the
+// seller or refunding the buyer; it works on funded and disputed escrows alike.
+//
+// AdminResolve açık bir emaneti admin kararıyla sonuçlandırır; satıcıya serbest bırakır
+// veya alıcıya iade eder. Funded ve disputed emanetlerde aynı şekilde çalışır.
+func (s *EscrowService) AdminResolve(adminID, escrowID uint, release bool, note string) (*models.Escrow, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, errors.New("a note explaining the decision is required")
+	}
+	if len(note) > maxEscrowNoteLength {
+		return nil, fmt.Errorf("note must be at most %d characters", maxEscrowNoteLength)
+	}
+
+	status, message := models.EscrowStatusRefunded, "Escrow refunded by admin"
+	if release {
+		status, message = models.EscrowStatusReleased, "Escrow released by admin"
+	}
+
+	return s.settle(escrowID, message, func(*models.Escrow) error {
+		return nil
+	}, func(repos *repositories.Repositories, escrow *models.Escrow) error {
+		return s.payout(repos, escrow, escrowOpen, status, models.EscrowResolutionAdmin, &adminID, note)
+	})
+}
+
+// ReleaseDue releases funded escrows whose release time has passed and returns how many it released
+// ReleaseDue serbest bırakma zamanı geçmiş funded emanetleri serbest bırakır ve kaç tane olduğunu döndürür
+func (s *EscrowService) ReleaseDue() (int, error) {
+	escrows, err := s.escrowRepo.FindDue(time.Now(), expiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for i := range escrows {
+		escrow := &escrows[i]
+		err := retryOnConflict(s.log, func() error {
+			return s.uow.Do(func(repos *repositories.Repositories) error {
+				return s.payout(repos, escrow, []string{models.EscrowStatusFunded}, models.EscrowStatusReleased, models.EscrowResolutionAutoReleased, nil, "")
+			})
+		})
+
+		// An escrow confirmed, cancelled or disputed in the meantime is simply skipped
+		// Bu arada onaylanan, iptal edilen veya itiraz edilen emanet atlanır
+		if errors.Is(err, repositories.ErrEscrowChanged) {
+			continue
+		}
+		// Record why and move on, so one stuck escrow does not keep the rest from being released
+		// Nedenini kaydet ve devam et, böylece takılmış bir emanet diğerlerinin serbest bırakılmasını engellemez
+		if err != nil {
+			s.log.Error("Escrow auto-release failed", map[string]interface{}{
+				"escrow_id": escrow.ID,
+				"error":     err.Error(),
+			})
+			if err := s.escrowRepo.RecordReleaseFailure(escrow, err.Error()); err != nil && !errors.Is(err, repositories.ErrEscrowChanged) {
+				s.log.Error("Escrow release failure could not be recorded", map[string]interface{}{
+					"escrow_id": escrow.ID,
+					"error":     err.Error(),
+				})
+			}
+			continue
+		}
+		released++
+	}
+
+	if released > 0 {
+		s.log.Info("Due escrows released", map[string]interface{}{
+			"count": released,
+		})
+	}
+
+	return released, nil
+}
+
+// RunAutoRelease calls ReleaseDue on every tick; it blocks, so start it in a goroutine
+// RunAutoRelease her tikte ReleaseDue çağırır; bloklar, bu yüzden goroutine içinde başlatılmalıdır
+func (s *EscrowService) RunAutoRelease(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := s.ReleaseDue(); err != nil {
+			s.log.Error("Escrow auto-release run failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// settle loads an escrow, lets allow check the caller's side and runs the payout in one unit of work
+// settle bir emaneti yükler, allow ile çağıranın tarafını kontrol eder ve ödemeyi tek bir unit of work'te yapar
+func (s *EscrowService) settle(escrowID uint, message string, allow func(*models.Escrow) error, pay func(*repositories.Repositories, *models.Escrow) error) (*models.Escrow, error) {
+
+	var escrow *models.Escrow
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			var err error
+			escrow, err = repos.Escrows.FindByID(escrowID)
+			if err != nil {
+				return err
+			}
+			if err := allow(escrow); err != nil {
+				return err
+			}
+			if !escrow.IsOpen() {
+				return repositories.ErrEscrowChanged
+			}
+			return pay(repos, escrow)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info(message, map[string]interface{}{
+		"escrow_id":  escrow.ID,
+		"status":     escrow.Status,
+		"resolution": escrow.Resolution,
+	})
+
+	return escrow, nil
+}
+
+// payout closes the escrow with the given status and pays its money out of the escrow
+// account: to the seller when released, back to the buyer when refunded
+//
+// payout emaneti verilen durumla kapatır ve parasını emanet hesabından öder:
+// serbest bırakılınca satıcıya, iade edilince alıcıya
+func (s *EscrowService) payout(repos *repositories.Repositories, escrow *models.Escrow, from []string, status, resolution string, resolvedBy *uint, note string) error {
+	ledger := s.ledgerService.WithTx(repos)
+
+	if err := repos.Escrows.Close(escrow, from, status, resolution, resolvedBy, note); err != nil {
+		return err
+	}
+
+	recipientID, otherID, txType := escrow.SellerID, escrow.BuyerID, models.TransactionTypeEscrowReleased
+	if status == models.EscrowStatusRefunded {
+		recipientID, otherID, txType = escrow.BuyerID, escrow.SellerID, models.TransactionTypeEscrowRefunded
+	}
+
+	wallet, err := repos.Wallets.FindOrCreate(recipientID, escrow.Currency)
+	if err != nil {
+		return err
+	}
+	walletAccount, err := ledger.WalletAccount(wallet)
+	if err != nil {
+		return err
+	}
+	escrowAccount, err := ledger.EscrowAccount(escrow)
+	if err != nil {
+		return err
+	}
+
+	credited, err := models.NewMoney(wallet.Balance, escrow.Currency).Add(models.NewMoney(escrow.Amount, escrow.Currency))
+	if err != nil {
+		return err
+	}
+	if err := repos.Wallets.UpdateBalance(wallet, credited.Minor); err != nil {
+		return err
+	}
+
+	// POST LEDGER ENTRY: escrow down, recipient up
+	// Defter kaydı: emanet azalır, alıcı taraf artar
+	if err := ledger.Post(txType, fmt.Sprintf("escrow:%d %s to user:%d", escrow.ID, status, recipientID),
+		LedgerLine{Account: escrowAccount, Amount: -escrow.Amount},
+		LedgerLine{Account: walletAccount, Amount: escrow.Amount},
+	); err != nil {
+		return err
+	}
+
+	return s.transactionService.WithTx(repos).RecordEntry(&models.Transaction{
+		UserID:        recipientID,
+		Type:          txType,
+		Amount:        escrow.Amount,
+		Currency:      escrow.Currency,
+		TargetUserID:  &otherID,
+		BalanceAfter:  wallet.Balance,
+		EscrowID:      &escrow.ID,
+		CorrelationID: models.NewCorrelationID(),
+		Reason:        resolution,
+	})
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"mini-pay-backend/internal/fees"
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+)
+
+// Fee is what an operation costs on top of its amount, in Currency
+// Fee bir işlemin tutarına ek olarak maliyetidir, Currency cinsinden
+type Fee struct {
+	Operation string       `json:"operation"`
+	Amount    models.Money `json:"amount"`
+	Currency  string       `json:"currency"`
+}
+
+// FeeService prices operations from the fee schedule and books fees to the house revenue account
+// FeeService işlemleri ücret tablosundan fiyatlar ve ücretleri platform gelir hesabına kaydeder
+type FeeService struct {
+	schedule           *fees.Schedule
+	ledgerService      *LedgerService
+	transactionService *TransactionService
+	defaultCurrency    string
+	log                logger.Logger
+}
+
+func NewFeeService(
+	schedule *fees.Schedule,
+	ledgerService *LedgerService,
+	transactionService *TransactionService,
+	defaultCurrency string,
+	log logger.Logger,
+) *FeeService {
+	return &FeeService{
+		schedule:           schedule,
+		ledgerService:      ledgerService,
+		transactionService: transactionService,
+		defaultCurrency:    defaultCurrency,
+		log:                log,
+	}
+}
+
+// Preview prices an operation a client is about to make
+// Preview istemcinin yapmak üzere olduğu bir işlemi fiyatlar
+func (s *FeeService) Preview(operation, currency string, value models.Decimal) (*Fee, error) {
+	currency, amount, err := resolveAmount(currency, s.defaultCurrency, value)
+	if err != nil {
+		return nil, err
+	}
+	return s.Quote(operation, currency, amount)
+}
+
+// Quote prices an operation before it runs, so the caller can check the funds for amount + fee
+// Quote bir işlemi çalışmadan önce fiyatlar, böylece çağıran tutar + ücret için bakiyeyi kontrol edebilir
+func (s *FeeService) Quote(operation, currency string, amount int64) (*Fee, error) {
+	if operation != fees.OperationWithdraw && operation != fees.OperationTransfer {
+		return nil, errors.New("operation must be withdraw or transfer")
+	}
+	if amount <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+
+	currency, err := resolveCurrency(currency, s.defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fee{
+		Operation: operation,
+		Amount:    models.NewMoney(s.schedule.Fee(operation, currency, amount), currency),
+		Currency:  currency,
+	}, nil
+}
+
+// Charge takes the fee from the wallet inside the caller's unit of work, so the fee
+// commits or rolls back together with the operation it belongs to. The fee row shares
+// the operation's correlation ID and, on a joint wallet, the member who initiated it.
+// A zero fee books nothing.
+//
+// Charge ücreti çağıranın unit of work'ü içinde cüzdandan alır, böylece ücret ait olduğu
+// işlemle birlikte commit edilir ya da geri alınır. Ücret satırı işlemin correlation
+// ID'sini ve ortak cüzdanda işlemi başlatan üyeyi paylaşır. Sıfır ücret için hiçbir kayıt yapılmaz.
+func (s *FeeService) Charge(repos *repositories.Repositories, wallet *models.Wallet, fee *Fee, correlationID string, initiatedBy *uint) error {
+	if fee.Amount.Minor == 0 {
+		return nil
+	}
+
+	ledger := s.ledgerService.WithTx(repos)
+
+	walletAccount, err := ledger.WalletAccount(wallet)
+	if err != nil {
+		return err
+	}
+	revenueAccount, err := ledger.SystemAccount(models.SystemAccountFees, wallet.Currency)
+	if err != nil {
+		return err
+	}
+
+	if err := repos.Wallets.UpdateBalance(wallet, wallet.Balance-fee.Amount.Minor); err != nil {
+		return err
+	}
+
+	// POST LEDGER ENTRY: wallet down, house revenue up
+	// Defter kaydı: cüzdan azalır, platform geliri artar
+	if err := ledger.Post(models.TransactionTypeFee, fmt.Sprintf("%s fee user:%d", fee.Operation, wallet.UserID),
+		LedgerLine{Account: walletAccount, Amount: -fee.Amount.Minor},
+		LedgerLine{Account: revenueAccount, Amount: fee.Amount.Minor},
+	); err != nil {
+		return err
+	}
+
+	return s.transactionService.WithTx(repos).RecordEntry(&models.Transaction{
+		UserID:        wallet.UserID,
+		JointID:       wallet.JointID,
+		InitiatedBy:   initiatedBy,
+		Type:          models.TransactionTypeFee,
+		Amount:        fee.Amount.Minor,
+		Currency:      wallet.Currency,
+		BalanceAfter:  wallet.Balance,
+		CorrelationID: correlationID,
+		Reason:        fee.Operation + " fee",
+	})
+}
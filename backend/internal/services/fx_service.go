@@ -0,0 +1,288 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/rates"
+	"mini-pay-backend/internal/repositories"
+)
+
+// Conversion describes money exchanged at a locked quote
+// Conversion sabitlenmiş bir teklifle çevrilen parayı tanımlar
+type Conversion struct {
+	QuoteID      uint   `json:"quote_id"`
+	FromCurrency string `json:"from_currency"`
+	ToCurrency   string `json:"to_currency"`
+	FromAmount   int64  `json:"from_amount"`
+	ToAmount     int64  `json:"to_amount"`
+	Rate         string `json:"rate"`
+
+	// Fee is only set for cross-currency transfers; own-wallet conversions earn the spread
+	// Fee sadece farklı para birimli transferlerde dolar; kendi cüzdanlar arası çevrim marj kazandırır
+	Fee *Fee `json:"fee,omitempty"`
+}
+
+// MarshalJSON writes both amounts as decimal strings in their own currency
+// MarshalJSON iki tutarı da kendi para biriminde ondalık metin olarak yazar
+func (c Conversion) MarshalJSON() ([]byte, error) {
+	type plain Conversion
+	return json.Marshal(struct {
+		plain
+		FromAmount models.Money `json:"from_amount"`
+		ToAmount   models.Money `json:"to_amount"`
+	}{plain(c), models.NewMoney(c.FromAmount, c.FromCurrency), models.NewMoney(c.ToAmount, c.ToCurrency)})
+}
+
+// FxService quotes exchange rates and converts between a user's own wallets
+// FxService döviz kuru teklif eder ve kullanıcının kendi cüzdanları arasında çevrim yapar
+type FxService struct {
+	uow                *repositories.UnitOfWork
+	quoteRepo          *repositories.FxQuoteRepository
+	provider           rates.Provider
+	ledgerService      *LedgerService
+	transactionService *TransactionService
+	spreadBps          int64
+	quoteTTL           time.Duration
+	log                logger.Logger
+}
+
+// Constructor for FxService
+// FxService için constructor
+func NewFxService(
+	uow *repositories.UnitOfWork,
+	quoteRepo *repositories.FxQuoteRepository,
+	provider rates.Provider,
+	ledgerService *LedgerService,
+	transactionService *TransactionService,
+	spreadBps int64,
+	quoteTTL time.Duration,
+	log logger.Logger,
+) *FxService {
+	return &FxService{
+		uow:                uow,
+		quoteRepo:          quoteRepo,
+		provider:           provider,
+		ledgerService:      ledgerService,
+		transactionService: transactionService,
+		spreadBps:          spreadBps,
+		quoteTTL:           quoteTTL,
+		log:                log,
+	}
+}
+
+// CreateQuote locks the current rate (after spread) for the user until the quote expires
+// CreateQuote mevcut kuru (marj sonrası) teklifin süresi dolana kadar kullanıcı için sabitler
+func (s *FxService) CreateQuote(userID uint, from, to string) (*models.FxQuote, error) {
+	fromCurrency, err := models.LookupCurrency(from)
+	if err != nil {
+		return nil, err
+	}
+	toCurrency, err := models.LookupCurrency(to)
+	if err != nil {
+		return nil, err
+	}
+	if fromCurrency.Code == toCurrency.Code {
+		return nil, errors.New("quote currencies must differ")
+	}
+
+	mid, err := s.provider.MidRate(fromCurrency.Code, toCurrency.Code)
+	if err != nil {
+		s.log.Error("Exchange rate lookup failed", map[string]interface{}{
+			"from": fromCurrency.Code,
+			"to":   toCurrency.Code,
+		})
+		return nil, err
+	}
+
+	quote := &models.FxQuote{
+		UserID:       userID,
+		FromCurrency: fromCurrency.Code,
+		ToCurrency:   toCurrency.Code,
+		MidRate:      rates.Format(mid),
+		Rate:         rates.Format(rates.ApplySpread(mid, s.spreadBps)),
+		SpreadBps:    s.spreadBps,
+		ExpiresAt:    time.Now().Add(s.quoteTTL),
+	}
+	if err := s.quoteRepo.Create(quote); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("FX quote created", map[string]interface{}{
+		"user_id":  userID,
+		"quote_id": quote.ID,
+		"rate":     quote.Rate,
+	})
+
+	return quote, nil
+}
+
+// Convert exchanges money between two of the user's own wallets at a locked quote
+// Convert sabitlenmiş teklifle kullanıcının kendi iki cüzdanı arasında para çevirir
+func (s *FxService) Convert(userID, quoteID uint, amount models.Decimal) (*Conversion, error) {
+
+	if amount.IsZero() {
+		return nil, errors.New("invalid conversion amount")
+	}
+
+	var conversion *Conversion
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			ledger := s.ledgerService.WithTx(repos)
+			history := s.transactionService.WithTx(repos)
+
+			var err error
+			conversion, err = consumeQuote(repos, userID, quoteID, amount)
+			if err != nil {
+				return err
+			}
+
+			fromWallet, err := repos.Wallets.FindByUserAndCurrency(userID, conversion.FromCurrency)
+			if err != nil {
+				return err
+			}
+			if fromWallet.Available() < conversion.FromAmount {
+				return ErrInsufficientFunds
+			}
+			toWallet, err := repos.Wallets.FindOrCreate(userID, conversion.ToCurrency)
+			if err != nil {
+				return err
+			}
+
+			if err := postConversion(ledger, fmt.Sprintf("conversion user:%d quote:%d", userID, quoteID), fromWallet, toWallet, conversion); err != nil {
+				return err
+			}
+
+			if err := repos.Wallets.UpdateBalance(fromWallet, fromWallet.Balance-conversion.FromAmount); err != nil {
+				return err
+			}
+			credited, err := models.NewMoney(toWallet.Balance, conversion.ToCurrency).Add(models.NewMoney(conversion.ToAmount, conversion.ToCurrency))
+			if err != nil {
+				return err
+			}
+			if err := repos.Wallets.UpdateBalance(toWallet, credited.Minor); err != nil {
+				return err
+			}
+
+			correlationID := models.NewCorrelationID()
+			if err := history.RecordEntry(&models.Transaction{
+				UserID:        userID,
+				Type:          models.TransactionTypeConversionOut,
+				Amount:        conversion.FromAmount,
+				Currency:      conversion.FromCurrency,
+				Rate:          conversion.Rate,
+				BalanceAfter:  fromWallet.Balance,
+				CorrelationID: correlationID,
+			}); err != nil {
+				return err
+			}
+
+			return history.RecordEntry(&models.Transaction{
+				UserID:        userID,
+				Type:          models.TransactionTypeConversionIn,
+				Amount:        conversion.ToAmount,
+				Currency:      conversion.ToCurrency,
+				Rate:          conversion.Rate,
+				BalanceAfter:  toWallet.Balance,
+				CorrelationID: correlationID,
+			})
+		})
+	})
+	if err != nil {
+		s.log.Error("Conversion failed", map[string]interface{}{
+			"user_id":  userID,
+			"quote_id": quoteID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Conversion successful", map[string]interface{}{
+		"user_id":     userID,
+		"from_amount": conversion.FromAmount,
+		"to_amount":   conversion.ToAmount,
+		"rate":        conversion.Rate,
+	})
+
+	return conversion, nil
+}
+
+// consumeQuote uses up the quote inside the unit of work and converts the amount at its rate.
+// The amount is read in the quote's source currency.
+//
+// consumeQuote teklifi unit of work içinde kullanır ve tutarı teklif kuruyla çevirir.
+// Tutar teklifin kaynak para biriminde okunur.
+func consumeQuote(repos *repositories.Repositories, userID, quoteID uint, value models.Decimal) (*Conversion, error) {
+	quote, err := repos.FxQuotes.Consume(quoteID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := rates.Parse(quote.Rate)
+	if err != nil {
+		return nil, err
+	}
+	fromCurrency, err := models.LookupCurrency(quote.FromCurrency)
+	if err != nil {
+		return nil, err
+	}
+	toCurrency, err := models.LookupCurrency(quote.ToCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := value.Minor(fromCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := rates.Convert(amount, rate, fromCurrency.MinorUnits, toCurrency.MinorUnits)
+	if converted <= 0 {
+		return nil, errors.New("amount is too small to convert")
+	}
+
+	return &Conversion{
+		QuoteID:      quote.ID,
+		FromCurrency: quote.FromCurrency,
+		ToCurrency:   quote.ToCurrency,
+		FromAmount:   amount,
+		ToAmount:     converted,
+		Rate:         quote.Rate,
+	}, nil
+}
+
+// postConversion writes the ledger entry for money moving between currencies.
+// The FX system account takes the source currency and gives out the target currency,
+// so the entry balances in each currency separately.
+//
+// postConversion para birimleri arası para hareketinin defter kaydını yazar.
+// FX sistem hesabı kaynak para birimini alır ve hedef para birimini verir,
+// böylece kayıt her para biriminde ayrı ayrı dengelenir.
+func postConversion(ledger *LedgerService, description string, fromWallet, toWallet *models.Wallet, conversion *Conversion) error {
+	fromAccount, err := ledger.WalletAccount(fromWallet)
+	if err != nil {
+		return err
+	}
+	toAccount, err := ledger.WalletAccount(toWallet)
+	if err != nil {
+		return err
+	}
+	fxFrom, err := ledger.SystemAccount(models.SystemAccountFx, conversion.FromCurrency)
+	if err != nil {
+		return err
+	}
+	fxTo, err := ledger.SystemAccount(models.SystemAccountFx, conversion.ToCurrency)
+	if err != nil {
+		return err
+	}
+
+	return ledger.Post(models.JournalEntryConversion, description,
+		LedgerLine{Account: fromAccount, Amount: -conversion.FromAmount},
+		LedgerLine{Account: fxFrom, Amount: conversion.FromAmount},
+		LedgerLine{Account: fxTo, Amount: -conversion.ToAmount},
+		LedgerLine{Account: toAccount, Amount: conversion.ToAmount},
+	)
+}
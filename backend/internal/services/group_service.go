@@ -0,0 +1,681 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// Group limits keep names readable and splits small enough to show on one screen
+// Grup sınırları isimleri okunur, bölüşümleri tek ekranda gösterilebilir tutar
+const (
+	maxGroupNameLength   = 64
+	maxExpenseDescLength = 140
+	maxGroupMembers      = 50
+	maxShareWeight       = 1000
+)
+
+// ErrNothingToSettle is returned when the user owes nobody in the group
+// ErrNothingToSettle kullanıcının grupta kimseye borcu olmadığında döner
+var ErrNothingToSettle = errors.New("you have nothing to settle in this group")
+
+// GroupInput carries the fields a user sets when creating a group
+// GroupInput kullanıcının grup oluştururken belirlediği alanları taşır
+type GroupInput struct {
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+}
+
+// ExpenseInput describes a bill and how to split it. PaidBy defaults to the caller and
+// an equal split without participants is shared by every member.
+//
+// ExpenseInput bir faturayı ve nasıl bölüneceğini tanımlar. PaidBy varsayılan olarak
+// çağıranı alır ve katılımcısız eşit bölüşüm tüm üyeler arasında paylaşılır.
+type ExpenseInput struct {
+	Description  string             `json:"description"`
+	Amount       models.Decimal     `json:"amount"`
+	PaidBy       uint               `json:"paid_by"`
+	Split        string             `json:"split"`
+	Participants []ParticipantInput `json:"participants"`
+}
+
+// ParticipantInput is one participant of a split: shares for the shares split, amount for the exact split
+// ParticipantInput bir bölüşümün tek katılımcısıdır: shares bölüşümü için pay, exact bölüşümü için tutar
+type ParticipantInput struct {
+	UserID uint           `json:"user_id"`
+	Shares int            `json:"shares"`
+	Amount models.Decimal `json:"amount"`
+}
+
+// GroupBalance is a member's net position: positive is owed to them, negative they owe
+// GroupBalance bir üyenin net durumudur: pozitif ona borçlu olunan, negatif onun borcudur
+type GroupBalance struct {
+	UserID  uint         `json:"user_id"`
+	Balance models.Money `json:"balance"`
+}
+
+// SettleTransfer is one transfer of a settle-up plan
+// SettleTransfer bir hesaplaşma planının tek bir transferidir
+type SettleTransfer struct {
+	FromUserID uint         `json:"from_user_id"`
+	ToUserID   uint         `json:"to_user_id"`
+	Amount     models.Money `json:"amount"`
+}
+
+// GroupView is a group with its members, their balances and the plan that settles them
+// GroupView bir grubun üyeleri, bakiyeleri ve onları kapatan planla birlikte halidir
+type GroupView struct {
+	Group    *models.Group        `json:"group"`
+	Members  []models.GroupMember `json:"members"`
+	Balances []GroupBalance       `json:"balances"`
+	SettleUp []SettleTransfer     `json:"settle_up"`
+}
+
+// SettleUpResult lists the settlements the caller paid and the transfer fees charged for them
+// SettleUpResult çağıranın ödediği hesaplaşmaları ve onlar için alınan transfer ücretlerini listeler
+type SettleUpResult struct {
+	Settlements []models.Settlement `json:"settlements"`
+	Fees        []*Fee              `json:"fees,omitempty"`
+}
+
+// GroupService keeps shared expenses of a group of users. Expenses only move the members'
+// balances; money moves when a member settles up, through ordinary wallet transfers.
+//
+// GroupService bir kullanıcı grubunun ortak harcamalarını tutar. Harcamalar yalnızca üyelerin
+// bakiyelerini değiştirir; para, bir üye hesaplaştığında sıradan cüzdan transferleriyle taşınır.
+type GroupService struct {
+	uow             *repositories.UnitOfWork
+	groupRepo       *repositories.GroupRepository
+	walletService   *WalletService
+	defaultCurrency string
+	log             logger.Logger
+}
+
+func NewGroupService(
+	uow *repositories.UnitOfWork,
+	groupRepo *repositories.GroupRepository,
+	walletService *WalletService,
+	defaultCurrency string,
+	log logger.Logger,
+) *GroupService {
+	return &GroupService{
+		uow:             uow,
+		groupRepo:       groupRepo,
+		walletService:   walletService,
+		defaultCurrency: defaultCurrency,
+		log:             log,
+	}
+}
+
+// Create opens a group with the user as its first member
+// Create kullanıcıyı ilk üyesi yaparak bir grup açar
+func (s *GroupService) Create(userID uint, input GroupInput) (*GroupView, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("group name is required")
+	}
+	if len(name) > maxGroupNameLength {
+		return nil, fmt.Errorf("group name must be at most %d characters", maxGroupNameLength)
+	}
+
+	currency, err := resolveCurrency(input.Currency, s.defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &models.Group{Name: name, Currency: currency, CreatedBy: userID}
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Groups.Create(group); err != nil {
+			return err
+		}
+		return repos.Groups.SaveMember(&models.GroupMember{
+			GroupID: group.ID,
+			UserID:  userID,
+			Status:  models.GroupMemberActive,
+			AddedBy: userID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Group created", map[string]interface{}{
+		"group_id": group.ID,
+		"user_id":  userID,
+		"currency": currency,
+	})
+
+	return s.view(s.groupRepo, group)
+}
+
+// List returns the groups the user is a member of
+// List kullanıcının üyesi olduğu grupları döndürür
+func (s *GroupService) List(userID uint) ([]models.Group, error) {
+	return s.groupRepo.FindByMember(userID)
+}
+
+// Get returns a group with its members, balances and settle-up plan
+// Get bir grubu üyeleri, bakiyeleri ve hesaplaşma planıyla döndürür
+func (s *GroupService) Get(userID, groupID uint) (*GroupView, error) {
+	group, err := s.access(s.groupRepo, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(s.groupRepo, group)
+}
+
+// AddMember adds a user to the group; any member can add people
+// AddMember bir kullanıcıyı gruba ekler; her üye kişi ekleyebilir
+func (s *GroupService) AddMember(userID, groupID, newUserID uint) (*models.GroupMember, error) {
+	var added *models.GroupMember
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		group, err := s.access(repos.Groups, userID, groupID)
+		if err != nil {
+			return err
+		}
+		if _, err := repos.Users.FindByID(newUserID); err != nil {
+			return errors.New("user to add not found")
+		}
+
+		members, err := repos.Groups.FindMembers(group.ID)
+		if err != nil {
+			return err
+		}
+		if len(members) >= maxGroupMembers {
+			return fmt.Errorf("a group can have at most %d members", maxGroupMembers)
+		}
+
+		// A member who left can be added again on the same row
+		// Ayrılmış bir üye aynı satır üzerinden yeniden eklenebilir
+		added, err = repos.Groups.FindMember(group.ID, newUserID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			added = &models.GroupMember{GroupID: group.ID, UserID: newUserID}
+		case err != nil:
+			return err
+		case added.Status == models.GroupMemberActive:
+			return errors.New("user is already a member")
+		}
+
+		added.Status = models.GroupMemberActive
+		added.AddedBy = userID
+		return repos.Groups.SaveMember(added)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Group member added", map[string]interface{}{
+		"group_id": groupID,
+		"user_id":  userID,
+		"added_id": added.UserID,
+	})
+
+	return added, nil
+}
+
+// RemoveMember lets a member leave or the group's creator remove someone.
+// Only settled members can go, so nobody walks away from a debt or a claim.
+//
+// RemoveMember bir üyenin ayrılmasını veya grubu oluşturanın birini çıkarmasını sağlar.
+// Yalnızca hesaplaşmış üyeler ayrılabilir, böylece kimse bir borçtan veya alacaktan kaçmaz.
+func (s *GroupService) RemoveMember(userID, groupID, memberID uint) error {
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		group, err := s.access(repos.Groups, userID, groupID)
+		if err != nil {
+			return err
+		}
+		if memberID != userID && group.CreatedBy != userID {
+			return errors.New("only the group's creator can remove other members")
+		}
+
+		target, err := repos.Groups.FindMember(group.ID, memberID)
+		if err != nil {
+			return err
+		}
+		if target.Status != models.GroupMemberActive {
+			return gorm.ErrRecordNotFound
+		}
+
+		balances, err := repos.Groups.Balances(group.ID)
+		if err != nil {
+			return err
+		}
+		if balances[memberID] != 0 {
+			return fmt.Errorf("member must settle up first (balance %s)", models.NewMoney(balances[memberID], group.Currency))
+		}
+
+		target.Status = models.GroupMemberLeft
+		return repos.Groups.SaveMember(target)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Group member removed", map[string]interface{}{
+		"group_id":  groupID,
+		"user_id":   userID,
+		"member_id": memberID,
+	})
+
+	return nil
+}
+
+// AddExpense records a bill paid by one member and splits it among the participants
+// AddExpense bir üyenin ödediği faturayı kaydeder ve katılımcılar arasında böler
+func (s *GroupService) AddExpense(userID, groupID uint, input ExpenseInput) (*models.Expense, error) {
+	description := strings.TrimSpace(input.Description)
+	if description == "" {
+		return nil, errors.New("expense description is required")
+	}
+	if len(description) > maxExpenseDescLength {
+		return nil, fmt.Errorf("expense description must be at most %d characters", maxExpenseDescLength)
+	}
+	if input.Amount.IsZero() {
+		return nil, errors.New("invalid expense amount")
+	}
+
+	var expense *models.Expense
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		group, err := s.access(repos.Groups, userID, groupID)
+		if err != nil {
+			return err
+		}
+		_, amount, err := resolveAmount(group.Currency, s.defaultCurrency, input.Amount)
+		if err != nil {
+			return err
+		}
+
+		members, err := repos.Groups.FindMembers(group.ID)
+		if err != nil {
+			return err
+		}
+		active := make(map[uint]bool, len(members))
+		for _, member := range members {
+			active[member.UserID] = true
+		}
+
+		paidBy := input.PaidBy
+		if paidBy == 0 {
+			paidBy = userID
+		}
+		if !active[paidBy] {
+			return errors.New("payer is not a member of the group")
+		}
+
+		participants := input.Participants
+		if len(participants) == 0 && (input.Split == "" || input.Split == models.SplitEqual) {
+			for _, member := range members {
+				participants = append(participants, ParticipantInput{UserID: member.UserID})
+			}
+		}
+
+		shares, split, err := splitExpense(group.Currency, amount, input.Split, participants, active)
+		if err != nil {
+			return err
+		}
+
+		expense = &models.Expense{
+			GroupID:     group.ID,
+			Description: description,
+			PaidBy:      paidBy,
+			CreatedBy:   userID,
+			Amount:      amount,
+			Currency:    group.Currency,
+			Split:       split,
+			Shares:      shares,
+		}
+		return repos.Groups.CreateExpense(expense)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Group expense added", map[string]interface{}{
+		"group_id":   groupID,
+		"expense_id": expense.ID,
+		"paid_by":    expense.PaidBy,
+		"amount":     expense.Amount,
+		"split":      expense.Split,
+	})
+
+	return expense, nil
+}
+
+// Expenses returns the expenses of the group, newest first
+// Expenses grubun harcamalarını yeniden eskiye döndürür
+func (s *GroupService) Expenses(userID, groupID uint) ([]models.Expense, error) {
+	group, err := s.access(s.groupRepo, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupRepo.FindExpenses(group.ID)
+}
+
+// DeleteExpense removes an expense; only the member who entered it or paid it may
+// DeleteExpense bir harcamayı siler; sadece onu giren veya ödeyen üye silebilir
+func (s *GroupService) DeleteExpense(userID, groupID, expenseID uint) error {
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		group, err := s.access(repos.Groups, userID, groupID)
+		if err != nil {
+			return err
+		}
+		expense, err := repos.Groups.FindExpense(group.ID, expenseID)
+		if err != nil {
+			return err
+		}
+		if expense.CreatedBy != userID && expense.PaidBy != userID {
+			return errors.New("only the member who entered or paid an expense can delete it")
+		}
+		return repos.Groups.DeleteExpense(expense)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Group expense deleted", map[string]interface{}{
+		"group_id":   groupID,
+		"expense_id": expenseID,
+		"user_id":    userID,
+	})
+
+	return nil
+}
+
+// Settlements returns the settle-up transfers made in the group, newest first
+// Settlements grupta yapılan hesaplaşma transferlerini yeniden eskiye döndürür
+func (s *GroupService) Settlements(userID, groupID uint) ([]models.Settlement, error) {
+	group, err := s.access(s.groupRepo, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupRepo.FindSettlements(group.ID)
+}
+
+// SettleUp pays every transfer of the group's settle-up plan that the user is the sender of.
+// The transfers and their settlement rows commit together; a failing transfer (funds,
+// limits) rolls them all back.
+//
+// SettleUp grubun hesaplaşma planında gönderici kullanıcı olan her transferi öder.
+// Transferler ve hesaplaşma satırları birlikte commit edilir; başarısız bir transfer
+// (bakiye, limitler) hepsini geri alır.
+func (s *GroupService) SettleUp(userID, groupID uint) (*SettleUpResult, error) {
+	var result *SettleUpResult
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			result = &SettleUpResult{}
+
+			group, err := s.access(repos.Groups, userID, groupID)
+			if err != nil {
+				return err
+			}
+			balances, err := repos.Groups.Balances(group.ID)
+			if err != nil {
+				return err
+			}
+
+			var own []SettleTransfer
+			for _, transfer := range settlePlan(balances, group.Currency) {
+				if transfer.FromUserID == userID {
+					own = append(own, transfer)
+				}
+			}
+			if len(own) == 0 {
+				return ErrNothingToSettle
+			}
+			if _, err := repos.Wallets.FindByUserAndCurrency(userID, group.Currency); err != nil {
+				return fmt.Errorf("you have no %s wallet to settle from", group.Currency)
+			}
+
+			for _, transfer := range own {
+				sent, fee, err := s.walletService.transfer(repos, userID, transfer.ToUserID, group.Currency, transfer.Amount.Minor, models.TransferPurposeSettlement)
+				if err != nil {
+					return err
+				}
+
+				settlement := models.Settlement{
+					GroupID:       group.ID,
+					FromUserID:    userID,
+					ToUserID:      transfer.ToUserID,
+					Amount:        transfer.Amount.Minor,
+					Currency:      group.Currency,
+					TransactionID: sent.ID,
+				}
+				if err := repos.Groups.CreateSettlement(&settlement); err != nil {
+					return err
+				}
+				result.Settlements = append(result.Settlements, settlement)
+				if fee != nil && fee.Amount.Minor > 0 {
+					result.Fees = append(result.Fees, fee)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Group settled up", map[string]interface{}{
+		"group_id":    groupID,
+		"user_id":     userID,
+		"settlements": len(result.Settlements),
+	})
+
+	return result, nil
+}
+
+// access loads the group if the user is an active member; anyone else gets not found
+// access kullanıcı aktif üyeyse grubu yükler; diğer herkes bulunamadı alır
+func (s *GroupService) access(groupRepo *repositories.GroupRepository, userID, groupID uint) (*models.Group, error) {
+	member, err := groupRepo.FindMember(groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Status != models.GroupMemberActive {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return groupRepo.FindByID(groupID)
+}
+
+// view loads the members and balances of a group and plans how to settle them
+// view bir grubun üyelerini ve bakiyelerini yükler ve nasıl kapatılacağını planlar
+func (s *GroupService) view(groupRepo *repositories.GroupRepository, group *models.Group) (*GroupView, error) {
+	members, err := groupRepo.FindMembers(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := groupRepo.Balances(group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &GroupView{
+		Group:    group,
+		Members:  members,
+		Balances: make([]GroupBalance, 0, len(members)),
+		SettleUp: settlePlan(balances, group.Currency),
+	}
+	for _, member := range members {
+		view.Balances = append(view.Balances, GroupBalance{
+			UserID:  member.UserID,
+			Balance: models.NewMoney(balances[member.UserID], group.Currency),
+		})
+	}
+	return view, nil
+}
+
+// splitExpense turns the participants of a split into expense shares that add up to amount exactly
+// splitExpense bir bölüşümün katılımcılarını toplamı tam olarak amount olan harcama paylarına çevirir
+func splitExpense(currency string, amount int64, split string, participants []ParticipantInput, active map[uint]bool) ([]models.ExpenseShare, string, error) {
+	if split == "" {
+		split = models.SplitEqual
+	}
+	if split != models.SplitEqual && split != models.SplitShares && split != models.SplitExact {
+		return nil, "", errors.New("split must be equal, shares or exact")
+	}
+	if len(participants) == 0 {
+		return nil, "", errors.New("an expense needs at least one participant")
+	}
+
+	seen := make(map[uint]bool, len(participants))
+	for _, participant := range participants {
+		if !active[participant.UserID] {
+			return nil, "", fmt.Errorf("user %d is not a member of the group", participant.UserID)
+		}
+		if seen[participant.UserID] {
+			return nil, "", fmt.Errorf("user %d is listed more than once", participant.UserID)
+		}
+		seen[participant.UserID] = true
+	}
+
+	shares := make([]models.ExpenseShare, len(participants))
+	if split == models.SplitExact {
+		total := models.NewMoney(0, currency)
+		for i, participant := range participants {
+			_, owed, err := resolveAmount(currency, currency, participant.Amount)
+			if err != nil {
+				return nil, "", err
+			}
+			if owed <= 0 {
+				return nil, "", fmt.Errorf("user %d needs an amount in an exact split", participant.UserID)
+			}
+			if total, err = total.Add(models.NewMoney(owed, currency)); err != nil {
+				return nil, "", err
+			}
+			shares[i] = models.ExpenseShare{UserID: participant.UserID, Amount: owed, Currency: currency}
+		}
+		if total.Minor != amount {
+			return nil, "", fmt.Errorf("exact amounts add up to %s, not %s", total, models.NewMoney(amount, currency))
+		}
+		return shares, split, nil
+	}
+
+	weights := make([]int, len(participants))
+	for i, participant := range participants {
+		weights[i] = 1
+		if split == models.SplitShares {
+			if participant.Shares <= 0 || participant.Shares > maxShareWeight {
+				return nil, "", fmt.Errorf("user %d needs between 1 and %d shares", participant.UserID, maxShareWeight)
+			}
+			weights[i] = participant.Shares
+		}
+		shares[i] = models.ExpenseShare{UserID: participant.UserID, Weight: weights[i], Currency: currency}
+	}
+
+	for i, owed := range allocate(amount, weights, shares) {
+		shares[i].Amount = owed
+	}
+	return shares, split, nil
+}
+
+// allocate divides amount in proportion to weights with the largest remainder method.
+// Every participant gets the floor of their exact share; the minor units left over go one
+// each to the largest remainders, ties to the lowest user ID, so the same split always
+// rounds the same way and the parts add up to amount.
+//
+// allocate tutarı en büyük kalan yöntemiyle ağırlıklar oranında böler. Her katılımcı
+// kesin payının tabanını alır; artan alt birimler en büyük kalanlara birer birer, eşitlikte
+// en küçük kullanıcı ID'sine gider; böylece aynı bölüşüm hep aynı yuvarlanır ve parçaların
+// toplamı tutara eşit olur.
+func allocate(amount int64, weights []int, shares []models.ExpenseShare) []int64 {
+	var total uint64
+	for _, weight := range weights {
+		total += uint64(weight)
+	}
+
+	parts := make([]int64, len(weights))
+	remainders := make([]uint64, len(weights))
+	left := amount
+	for i, weight := range weights {
+		// amount*weight can overflow 64 bits, so the product is taken in 128 bits;
+		// weight <= total keeps the quotient within amount
+		// amount*weight 64 biti aşabilir, bu yüzden çarpım 128 bitte alınır;
+		// weight <= total olduğundan bölüm amount'u aşmaz
+		hi, lo := bits.Mul64(uint64(amount), uint64(weight))
+		quotient, remainder := bits.Div64(hi, lo, total)
+		parts[i] = int64(quotient)
+		remainders[i] = remainder
+		left -= parts[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		if remainders[order[a]] != remainders[order[b]] {
+			return remainders[order[a]] > remainders[order[b]]
+		}
+		return shares[order[a]].UserID < shares[order[b]].UserID
+	})
+	for _, i := range order[:left] {
+		parts[i]++
+	}
+	return parts
+}
+
+// settlePlan pairs the members who owe with the members who are owed. Each step the
+// largest debt pays the largest claim (ties to the lowest user ID), which settles at least
+// one of them, so n members with a balance need at most n-1 transfers.
+//
+// settlePlan borçlu üyeleri alacaklı üyelerle eşleştirir. Her adımda en büyük borç en büyük
+// alacağı öder (eşitlikte en küçük kullanıcı ID'si), bu da en az birini kapatır; böylece
+// bakiyesi olan n üye en fazla n-1 transfere ihtiyaç duyar.
+func settlePlan(balances map[uint]int64, currency string) []SettleTransfer {
+	type position struct {
+		userID uint
+		amount int64
+	}
+
+	var debtors, creditors []*position
+	for userID, balance := range balances {
+		switch {
+		case balance < 0:
+			debtors = append(debtors, &position{userID, -balance})
+		case balance > 0:
+			creditors = append(creditors, &position{userID, balance})
+		}
+	}
+
+	largest := func(positions []*position) {
+		sort.Slice(positions, func(a, b int) bool {
+			if positions[a].amount != positions[b].amount {
+				return positions[a].amount > positions[b].amount
+			}
+			return positions[a].userID < positions[b].userID
+		})
+	}
+
+	plan := []SettleTransfer{}
+	for len(debtors) > 0 && len(creditors) > 0 {
+		largest(debtors)
+		largest(creditors)
+		debtor, creditor := debtors[0], creditors[0]
+
+		amount := min(debtor.amount, creditor.amount)
+		plan = append(plan, SettleTransfer{
+			FromUserID: debtor.userID,
+			ToUserID:   creditor.userID,
+			Amount:     models.NewMoney(amount, currency),
+		})
+
+		debtor.amount -= amount
+		creditor.amount -= amount
+		if debtor.amount == 0 {
+			debtors = debtors[1:]
+		}
+		if creditor.amount == 0 {
+			creditors = creditors[1:]
+		}
+	}
+	return plan
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"mini-pay-backend/internal/models"
+)
+
+// TestSplitExpenseRounding checks that shares add up to the expense exactly and that the
+// minor units left over go to the largest remainders, ties to the lowest user ID, whatever
+// order the participants are listed in.
+//
+// TestSplitExpenseRounding payların toplamının harcamaya tam eşit olduğunu ve artan alt
+// birimlerin, katılımcılar hangi sırayla verilirse verilsin, en büyük kalanlara, eşitlikte
+// en küçük kullanıcı ID'sine gittiğini kontrol eder.
+func TestSplitExpenseRounding(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       int64
+		split        string
+		participants []ParticipantInput
+		want         []int64
+	}{
+		{
+			name:         "equal, one unit left to the lowest ID",
+			amount:       10000,
+			participants: []ParticipantInput{{UserID: 1}, {UserID: 2}, {UserID: 3}},
+			want:         []int64{3334, 3333, 3333},
+		},
+		{
+			name:         "equal, lowest ID listed last",
+			amount:       10000,
+			participants: []ParticipantInput{{UserID: 3}, {UserID: 2}, {UserID: 1}},
+			want:         []int64{3333, 3333, 3334},
+		},
+		{
+			name:         "equal, two units left",
+			amount:       10001,
+			participants: []ParticipantInput{{UserID: 2}, {UserID: 1}, {UserID: 3}},
+			want:         []int64{3334, 3334, 3333},
+		},
+		{
+			name:         "equal, less than one unit each",
+			amount:       2,
+			participants: []ParticipantInput{{UserID: 1}, {UserID: 2}, {UserID: 3}},
+			want:         []int64{1, 1, 0},
+		},
+		{
+			name:         "shares, unit to the largest remainder",
+			amount:       100000,
+			split:        models.SplitShares,
+			participants: []ParticipantInput{{UserID: 1, Shares: 1}, {UserID: 2, Shares: 2}},
+			want:         []int64{33333, 66667},
+		},
+		{
+			name:   "shares, largest amount without overflow",
+			amount: math.MaxInt64,
+			split:  models.SplitShares,
+			participants: []ParticipantInput{
+				{UserID: 1, Shares: 1}, {UserID: 2, Shares: maxShareWeight}, {UserID: 3, Shares: 3},
+			},
+			want: []int64{9186625534715912, 9186625534715912158, 27559876604147737},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active := map[uint]bool{1: true, 2: true, 3: true}
+			shares, _, err := splitExpense("TRY", tt.amount, tt.split, tt.participants, active)
+			if err != nil {
+				t.Fatalf("splitExpense: %v", err)
+			}
+
+			got := make([]int64, len(shares))
+			var total uint64
+			for i, share := range shares {
+				got[i] = share.Amount
+				total += uint64(share.Amount)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shares = %v, want %v", got, tt.want)
+			}
+			if total != uint64(tt.amount) {
+				t.Errorf("shares add up to %d, want %d", total, tt.amount)
+			}
+		})
+	}
+}
+
+// TestSplitExpenseRejects checks the splits that cannot be turned into shares
+// TestSplitExpenseRejects paylara çevrilemeyen bölüşümleri kontrol eder
+func TestSplitExpenseRejects(t *testing.T) {
+	tests := []struct {
+		name         string
+		split        string
+		participants []ParticipantInput
+	}{
+		{name: "unknown split", split: "percent", participants: []ParticipantInput{{UserID: 1}}},
+		{name: "no participants", split: models.SplitEqual},
+		{name: "not a member", split: models.SplitEqual, participants: []ParticipantInput{{UserID: 1}, {UserID: 9}}},
+		{name: "listed twice", split: models.SplitEqual, participants: []ParticipantInput{{UserID: 1}, {UserID: 1}}},
+		{name: "no shares", split: models.SplitShares, participants: []ParticipantInput{{UserID: 1, Shares: 0}}},
+		{name: "too many shares", split: models.SplitShares, participants: []ParticipantInput{{UserID: 1, Shares: maxShareWeight + 1}}},
+		{name: "exact amounts short", split: models.SplitExact, participants: []ParticipantInput{
+			{UserID: 1, Amount: mustDecimal(t, "60.00")}, {UserID: 2, Amount: mustDecimal(t, "39.99")},
+		}},
+		{name: "exact amount missing", split: models.SplitExact, participants: []ParticipantInput{
+			{UserID: 1, Amount: mustDecimal(t, "100.00")}, {UserID: 2},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active := map[uint]bool{1: true, 2: true}
+			if _, _, err := splitExpense("TRY", 10000, tt.split, tt.participants, active); err == nil {
+				t.Errorf("splitExpense succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// expiryBatchSize bounds how many holds one sweep releases
+// expiryBatchSize bir taramada serbest bırakılan provizyon sayısını sınırlar
+const expiryBatchSize = 100
+
+// HoldService runs two-phase payments: authorize now, capture or void later
+// HoldService iki aşamalı ödemeleri yürütür: şimdi provizyon al, sonra tahsil et veya iptal et
+type HoldService struct {
+	uow                *repositories.UnitOfWork
+	holdRepo           *repositories.HoldRepository
+	ledgerService      *LedgerService
+	transactionService *TransactionService
+	limitService       *LimitService
+	defaultCurrency    string
+	ttl                time.Duration
+	log                logger.Logger
+}
+
+// Constructor for HoldService; ttl is used when the caller gives no expiry
+// HoldService için constructor; çağıran süre vermezse ttl kullanılır
+func NewHoldService(
+	uow *repositories.UnitOfWork,
+	holdRepo *repositories.HoldRepository,
+	ledgerService *LedgerService,
+	transactionService *TransactionService,
+	limitService *LimitService,
+	defaultCurrency string,
+	ttl time.Duration,
+	log logger.Logger,
+) *HoldService {
+	return &HoldService{
+		uow:                uow,
+		holdRepo:           holdRepo,
+		ledgerService:      ledgerService,
+		transactionService: transactionService,
+		limitService:       limitService,
+		defaultCurrency:    defaultCurrency,
+		ttl:                ttl,
+		log:                log,
+	}
+}
+
+// Authorize reserves money in the payer's wallet for the payee.
+// The wallet balance stays the same; only the available balance goes down.
+//
+// Authorize ödeyenin cüzdanında alıcı için para ayırır.
+// Cüzdan bakiyesi değişmez; sadece kullanılabilir bakiye azalır.
+func (s *HoldService) Authorize(userID, payeeID uint, currency string, value models.Decimal, ttl time.Duration) (*models.Hold, error) {
+
+	if userID == payeeID {
+		return nil, errors.New("cannot authorize a payment to self")
+	}
+	if value.IsZero() {
+		return nil, errors.New("invalid hold amount")
+	}
+	if ttl <= 0 {
+		ttl = s.ttl
+	}
+
+	currency, amount, err := resolveAmount(currency, s.defaultCurrency, value)
+	if err != nil {
+		return nil, err
+	}
+
+	var hold *models.Hold
+	err = retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+
+			if _, err := repos.Users.FindByID(payeeID); err != nil {
+				return errors.New("payee not found")
+			}
+
+			wallet, err := repos.Wallets.FindByUserAndCurrency(userID, currency)
+			if err != nil {
+				return err
+			}
+			if wallet.Available() < amount {
+				return ErrInsufficientFunds
+			}
+
+			// A capture is a transfer to the payee, so the payer learns about a cap now rather than at capture
+			// Tahsil alıcıya yapılan bir transferdir, böylece ödeyen bir sınırı tahsilde değil şimdi öğrenir
+			if err := s.limitService.Check(repos, userID, models.LimitOperationTransfer, currency, amount); err != nil {
+				return err
+			}
+
+			if err := repos.Wallets.UpdateHeld(wallet, wallet.Held+amount); err != nil {
+				return err
+			}
+
+			hold = &models.Hold{
+				UserID:    userID,
+				PayeeID:   payeeID,
+				Currency:  currency,
+				Amount:    amount,
+				Status:    models.HoldStatusAuthorized,
+				ExpiresAt: time.Now().Add(ttl),
+			}
+			if err := repos.Holds.Create(hold); err != nil {
+				return err
+			}
+
+			return s.transactionService.WithTx(repos).RecordEntry(&models.Transaction{
+				UserID:       userID,
+				Type:         models.TransactionTypeHoldPlaced,
+				Amount:       amount,
+				Currency:     currency,
+				TargetUserID: &payeeID,
+				BalanceAfter: wallet.Balance,
+				HoldID:       &hold.ID,
+			})
+		})
+	})
+	if err != nil {
+		s.log.Error("Authorization failed", map[string]interface{}{
+			"user_id":  userID,
+			"payee_id": payeeID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Hold authorized", map[string]interface{}{
+		"hold_id":  hold.ID,
+		"user_id":  userID,
+		"amount":   amount,
+		"currency": currency,
+	})
+
+	return hold, nil
+}
+
+// Capture moves money of an authorized hold to the payee.
+// An amount of 0 captures the full hold; whatever is not captured goes back to the payer.
+// The amount is read in the hold's currency.
+//
+// Capture açık bir provizyonun parasını alıcıya aktarır.
+// 0 tutarı provizyonun tamamını tahsil eder; tahsil edilmeyen kısım ödeyene geri döner.
+// Tutar provizyonun para biriminde okunur.
+func (s *HoldService) Capture(payeeID, holdID uint, value models.Decimal) (*models.Hold, error) {
+
+	var hold *models.Hold
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			ledger := s.ledgerService.WithTx(repos)
+			history := s.transactionService.WithTx(repos)
+
+			var err error
+			hold, err = repos.Holds.FindByID(holdID)
+			if err != nil {
+				return err
+			}
+
+			// Only the payee may capture; others are told the hold does not exist
+			// Sadece alıcı tahsil edebilir; diğerlerine provizyon yokmuş gibi cevap verilir
+			if hold.PayeeID != payeeID {
+				return gorm.ErrRecordNotFound
+			}
+			if hold.Status != models.HoldStatusAuthorized {
+				return repositories.ErrHoldNotActive
+			}
+			if !time.Now().Before(hold.ExpiresAt) {
+				return errors.New("hold has expired")
+			}
+
+			currency, err := models.LookupCurrency(hold.Currency)
+			if err != nil {
+				return err
+			}
+			captured, err := value.Minor(currency)
+			if err != nil {
+				return err
+			}
+			if captured == 0 {
+				captured = hold.Amount
+			}
+			if captured > hold.Amount {
+				return errors.New("capture exceeds the authorized amount")
+			}
+
+			// Checked again here, because other transfers may have used up the payer's allowance since authorization
+			// Burada tekrar kontrol edilir, çünkü provizyondan beri başka transferler ödeyenin hakkını tüketmiş olabilir
+			if err := s.limitService.Check(repos, hold.UserID, models.LimitOperationTransfer, hold.Currency, captured); err != nil {
+				return err
+			}
+
+			if err := repos.Holds.Close(hold, models.HoldStatusCaptured, captured); err != nil {
+				return err
+			}
+
+			payerWallet, err := repos.Wallets.FindByUserAndCurrency(hold.UserID, hold.Currency)
+			if err != nil {
+				return err
+			}
+			payeeWallet, err := repos.Wallets.FindOrCreate(payeeID, hold.Currency)
+			if err != nil {
+				return err
+			}
+
+			payerAccount, err := ledger.WalletAccount(payerWallet)
+			if err != nil {
+				return err
+			}
+			payeeAccount, err := ledger.WalletAccount(payeeWallet)
+			if err != nil {
+				return err
+			}
+
+			// Release the whole reservation, then take the captured part
+			// Ayrılan tutarın tamamını serbest bırak, sonra tahsil edilen kısmı düş
+			if err := repos.Wallets.UpdateHeld(payerWallet, payerWallet.Held-hold.Amount); err != nil {
+				return err
+			}
+			if err := repos.Wallets.UpdateBalance(payerWallet, payerWallet.Balance-captured); err != nil {
+				return err
+			}
+			credited, err := models.NewMoney(payeeWallet.Balance, hold.Currency).Add(models.NewMoney(captured, hold.Currency))
+			if err != nil {
+				return err
+			}
+			if err := repos.Wallets.UpdateBalance(payeeWallet, credited.Minor); err != nil {
+				return err
+			}
+
+			// POST LEDGER ENTRY: payer down, payee up
+			// Defter kaydı: ödeyen azalır, alıcı artar
+			if err := ledger.Post(models.TransactionTypeHoldCaptured, fmt.Sprintf("capture of hold:%d", hold.ID),
+				LedgerLine{Account: payerAccount, Amount: -captured},
+				LedgerLine{Account: payeeAccount, Amount: captured},
+			); err != nil {
+				return err
+			}
+
+			correlationID := models.NewCorrelationID()
+			if err := history.RecordEntry(&models.Transaction{
+				UserID:        hold.UserID,
+				Type:          models.TransactionTypeHoldCaptured,
+				Amount:        captured,
+				Currency:      hold.Currency,
+				TargetUserID:  &payeeID,
+				BalanceAfter:  payerWallet.Balance,
+				HoldID:        &hold.ID,
+				CorrelationID: correlationID,
+			}); err != nil {
+				return err
+			}
+
+			if err := history.RecordEntry(&models.Transaction{
+				UserID:        payeeID,
+				Type:          models.TransactionTypeCaptureReceived,
+				Amount:        captured,
+				Currency:      hold.Currency,
+				TargetUserID:  &hold.UserID,
+				BalanceAfter:  payeeWallet.Balance,
+				HoldID:        &hold.ID,
+				CorrelationID: correlationID,
+			}); err != nil {
+				return err
+			}
+
+			if remainder := hold.Amount - captured; remainder > 0 {
+				return history.RecordEntry(&models.Transaction{
+					UserID:        hold.UserID,
+					Type:          models.TransactionTypeHoldReleased,
+					Amount:        remainder,
+					Currency:      hold.Currency,
+					BalanceAfter:  payerWallet.Balance,
+					HoldID:        &hold.ID,
+					Reason:        "partial capture",
+					CorrelationID: correlationID,
+				})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		s.log.Error("Capture failed", map[string]interface{}{
+			"hold_id":  holdID,
+			"payee_id": payeeID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Hold captured", map[string]interface{}{
+		"hold_id":  hold.ID,
+		"captured": hold.CapturedAmount,
+		"amount":   hold.Amount,
+	})
+
+	return hold, nil
+}
+
+// Void releases an authorized hold; both the payer and the payee may do it
+// Void açık bir provizyonu serbest bırakır; hem ödeyen hem alıcı yapabilir
+func (s *HoldService) Void(userID, holdID uint) (*models.Hold, error) {
+
+	var hold *models.Hold
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			var err error
+			hold, err = repos.Holds.FindByID(holdID)
+			if err != nil {
+				return err
+			}
+			if hold.UserID != userID && hold.PayeeID != userID {
+				return gorm.ErrRecordNotFound
+			}
+
+			return s.release(repos, hold, models.HoldStatusVoided, "voided")
+		})
+	})
+	if err != nil {
+		s.log.Error("Void failed", map[string]interface{}{
+			"hold_id": holdID,
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Hold voided", map[string]interface{}{
+		"hold_id": hold.ID,
+		"user_id": userID,
+	})
+
+	return hold, nil
+}
+
+// GetHolds returns the holds the user placed or may capture
+// GetHolds kullanıcının verdiği veya tahsil edebileceği provizyonları döndürür
+func (s *HoldService) GetHolds(userID uint) ([]models.Hold, error) {
+	return s.holdRepo.FindByUser(userID)
+}
+
+// ExpireDue releases every authorized hold past its expiry and returns how many were released
+// ExpireDue süresi geçmiş tüm açık provizyonları serbest bırakır ve kaç tane olduğunu döndürür
+func (s *HoldService) ExpireDue() (int, error) {
+	holds, err := s.holdRepo.FindExpired(time.Now(), expiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for i := range holds {
+		hold := &holds[i]
+		err := retryOnConflict(s.log, func() error {
+			return s.uow.Do(func(repos *repositories.Repositories) error {
+				return s.release(repos, hold, models.HoldStatusExpired, "expired")
+			})
+		})
+
+		// A hold captured or voided in the meantime is simply skipped
+		// Bu arada tahsil veya iptal edilen provizyon atlanır
+		if errors.Is(err, repositories.ErrHoldNotActive) {
+			continue
+		}
+		// One hold that cannot be released must not keep the rest from expiring
+		// Serbest bırakılamayan bir provizyon diğerlerinin süresinin dolmasını engellememeli
+		if err != nil {
+			s.log.Error("Hold expiry failed", map[string]interface{}{
+				"hold_id": hold.ID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		released++
+	}
+
+	if released > 0 {
+		s.log.Info("Expired holds released", map[string]interface{}{
+			"count": released,
+		})
+	}
+
+	return released, nil
+}
+
+// RunExpiry calls ExpireDue on every tick; it blocks, so start it in a goroutine
+// RunExpiry her tikte ExpireDue çağırır; bloklar, bu yüzden goroutine içinde başlatılmalıdır
+func (s *HoldService) RunExpiry(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := s.ExpireDue(); err != nil {
+			s.log.Error("Hold expiry run failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// release closes the hold with the given status and gives the reserved money back to the payer
+// release provizyonu verilen durumla kapatır ve ayrılan parayı ödeyene geri verir
+func (s *HoldService) release(repos *repositories.Repositories, hold *models.Hold, status, reason string) error {
+	if err := repos.Holds.Close(hold, status, 0); err != nil {
+		return err
+	}
+
+	wallet, err := repos.Wallets.FindByUserAndCurrency(hold.UserID, hold.Currency)
+	if err != nil {
+		return err
+	}
+	if err := repos.Wallets.UpdateHeld(wallet, wallet.Held-hold.Amount); err != nil {
+		return err
+	}
+
+	return s.transactionService.WithTx(repos).RecordEntry(&models.Transaction{
+		UserID:       hold.UserID,
+		Type:         models.TransactionTypeHoldReleased,
+		Amount:       hold.Amount,
+		Currency:     hold.Currency,
+		BalanceAfter: wallet.Balance,
+		HoldID:       &hold.ID,
+		Reason:       reason,
+	})
+}
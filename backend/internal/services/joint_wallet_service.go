@@ -0,0 +1,1185 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mini-pay-backend/internal/fees"
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// maxJointNameLength keeps joint wallet names short enough for lists and statements
+// maxJointNameLength ortak cüzdan isimlerini listeler ve hesap özetleri için kısa tutar
+const maxJointNameLength = 64
+
+// ErrJointRole is returned when the member's role does not allow the action
+// ErrJointRole üyenin rolü işleme izin vermediğinde döner
+var ErrJointRole = errors.New("your role in this joint wallet does not allow this")
+
+// jointSpendTypes are the history rows that use up a member's daily limit
+// jointSpendTypes bir üyenin günlük limitini tüketen geçmiş satırlarıdır
+var jointSpendTypes = []string{models.TransactionTypeWithdraw, models.TransactionTypeTransferSent}
+
+// JointWalletInput carries the fields a user sets when opening a joint wallet
+// JointWalletInput kullanıcının ortak cüzdan açarken belirlediği alanları taşır
+type JointWalletInput struct {
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+}
+
+// JointPolicyInput sets when withdrawals need approvals; a nil threshold turns approvals off
+// JointPolicyInput çekimlerin ne zaman onay gerektirdiğini belirler; nil eşik onayları kapatır
+type JointPolicyInput struct {
+	ApprovalThreshold *models.Decimal `json:"approval_threshold"`
+	ApprovalsRequired int             `json:"approvals_required"`
+}
+
+// JointMemberInput sets a member's role and daily limit; a nil limit means no cap
+// JointMemberInput bir üyenin rolünü ve günlük limitini belirler; nil limit sınır yok demektir
+type JointMemberInput struct {
+	Role       string          `json:"role"`
+	DailyLimit *models.Decimal `json:"daily_limit"`
+}
+
+// JointWalletView is a joint wallet as one member sees it. Balances and members are
+// only shown to active members, not to users who are still invited.
+//
+// JointWalletView bir ortak cüzdanın bir üyeye görünen halidir. Bakiyeler ve üyeler
+// sadece aktif üyelere gösterilir, hâlâ davetli olan kullanıcılara gösterilmez.
+type JointWalletView struct {
+	JointWallet *models.JointWallet  `json:"joint_wallet"`
+	Role        string               `json:"role"`
+	Status      string               `json:"status"`
+	Balance     *models.Money        `json:"balance,omitempty"`
+	Available   *models.Money        `json:"available,omitempty"`
+	Members     []models.JointMember `json:"members,omitempty"`
+}
+
+// JointWithdrawalResult is either an executed withdrawal with its fee or one waiting for approvals
+// JointWithdrawalResult ücretiyle gerçekleşmiş bir çekim ya da onay bekleyen bir çekimdir
+type JointWithdrawalResult struct {
+	Withdrawal *models.JointWithdrawal `json:"withdrawal,omitempty"`
+	Fee        *Fee                    `json:"fee,omitempty"`
+}
+
+// JointWalletService runs wallets shared by several users. Owners manage members and
+// the approval policy, spenders move money, viewers only look. Every row a joint wallet
+// writes carries the joint wallet and the member who initiated it.
+//
+// JointWalletService birden fazla kullanıcının paylaştığı cüzdanları yönetir. Sahipler
+// üyeleri ve onay politikasını yönetir, harcayıcılar para taşır, izleyiciler sadece bakar.
+// Ortak cüzdanın yazdığı her satır ortak cüzdanı ve işlemi başlatan üyeyi taşır.
+type JointWalletService struct {
+	uow                *repositories.UnitOfWork
+	jointRepo          *repositories.JointWalletRepository
+	walletRepo         *repositories.WalletRepository
+	ledgerService      *LedgerService
+	transactionService *TransactionService
+	feeService         *FeeService
+	limitService       *LimitService
+	defaultCurrency    string
+	log                logger.Logger
+}
+
+func NewJointWalletService(
+	uow *repositories.UnitOfWork,
+	jointRepo *repositories.JointWalletRepository,
+	walletRepo *repositories.WalletRepository,
+	ledgerService *LedgerService,
+	transactionService *TransactionService,
+	feeService *FeeService,
+	limitService *LimitService,
+	defaultCurrency string,
+	log logger.Logger,
+) *JointWalletService {
+	return &JointWalletService{
+		uow:                uow,
+		jointRepo:          jointRepo,
+		walletRepo:         walletRepo,
+		ledgerService:      ledgerService,
+		transactionService: transactionService,
+		feeService:         feeService,
+		limitService:       limitService,
+		defaultCurrency:    defaultCurrency,
+		log:                log,
+	}
+}
+
+// Create opens an empty joint wallet with the user as its first owner
+// Create kullanıcıyı ilk sahibi yaparak boş bir ortak cüzdan açar
+func (s *JointWalletService) Create(userID uint, input JointWalletInput) (*JointWalletView, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("joint wallet name is required")
+	}
+	if len(name) > maxJointNameLength {
+		return nil, fmt.Errorf("joint wallet name must be at most %d characters", maxJointNameLength)
+	}
+
+	currency, err := resolveCurrency(input.Currency, s.defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	joint := &models.JointWallet{Name: name, Currency: currency, CreatedBy: userID}
+	now := time.Now()
+	owner := &models.JointMember{
+		UserID:    userID,
+		Role:      models.JointRoleOwner,
+		Status:    models.JointMemberActive,
+		Currency:  currency,
+		InvitedBy: userID,
+		JoinedAt:  &now,
+	}
+
+	var wallet *models.Wallet
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Joint.Create(joint); err != nil {
+			return err
+		}
+
+		wallet = &models.Wallet{JointID: joint.ID, Currency: currency}
+		if err := repos.Wallets.Create(wallet); err != nil {
+			return err
+		}
+
+		owner.JointWalletID = joint.ID
+		return repos.Joint.SaveMember(owner)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Joint wallet created", map[string]interface{}{
+		"joint_id": joint.ID,
+		"user_id":  userID,
+		"currency": currency,
+	})
+
+	return s.view(joint, owner, wallet, []models.JointMember{*owner}), nil
+}
+
+// List returns the joint wallets the user belongs to or is invited to
+// List kullanıcının üyesi olduğu veya davet edildiği ortak cüzdanları döndürür
+func (s *JointWalletService) List(userID uint) ([]JointWalletView, error) {
+	joints, err := s.jointRepo.FindByMember(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]JointWalletView, 0, len(joints))
+	for i := range joints {
+		member, err := s.jointRepo.FindMember(joints[i].ID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		var wallet *models.Wallet
+		if member.Status == models.JointMemberActive {
+			if wallet, err = s.walletRepo.FindByJoint(joints[i].ID); err != nil {
+				return nil, err
+			}
+		}
+		views = append(views, *s.view(&joints[i], member, wallet, nil))
+	}
+	return views, nil
+}
+
+// Get returns a joint wallet with its balance and members
+// Get bir ortak cüzdanı bakiyesi ve üyeleriyle birlikte döndürür
+func (s *JointWalletService) Get(userID, jointID uint) (*JointWalletView, error) {
+	joint, member, err := s.access(s.jointRepo, userID, jointID)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.walletRepo.FindByJoint(joint.ID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.jointRepo.FindMembers(joint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.view(joint, member, wallet, members), nil
+}
+
+// SetPolicy changes when withdrawals need approvals. The required count must be at least
+// two and no more than the owners and spenders there are now, so a withdrawal can complete.
+//
+// SetPolicy çekimlerin ne zaman onay gerektirdiğini değiştirir. Gereken sayı en az iki
+// olmalı ve şu anki sahip ve harcayıcı sayısını aşmamalıdır, böylece çekim tamamlanabilir.
+func (s *JointWalletService) SetPolicy(userID, jointID uint, input JointPolicyInput) (*models.JointWallet, error) {
+	var joint *models.JointWallet
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		var member *models.JointMember
+		var err error
+		joint, member, err = s.access(repos.Joint, userID, jointID)
+		if err != nil {
+			return err
+		}
+		if member.Role != models.JointRoleOwner {
+			return ErrJointRole
+		}
+
+		if input.ApprovalThreshold == nil {
+			joint.ApprovalThreshold = nil
+			joint.ApprovalsRequired = 0
+			return repos.Joint.UpdatePolicy(joint)
+		}
+
+		_, threshold, err := resolveAmount(joint.Currency, s.defaultCurrency, *input.ApprovalThreshold)
+		if err != nil {
+			return err
+		}
+		if input.ApprovalsRequired < 2 {
+			return errors.New("approvals_required must be at least 2")
+		}
+		signers, err := repos.Joint.CountActive(joint.ID, models.JointRoleOwner, models.JointRoleSpender)
+		if err != nil {
+			return err
+		}
+		if int64(input.ApprovalsRequired) > signers {
+			return fmt.Errorf("approvals_required cannot exceed the %d owners and spenders", signers)
+		}
+
+		joint.ApprovalThreshold = &threshold
+		joint.ApprovalsRequired = input.ApprovalsRequired
+		return repos.Joint.UpdatePolicy(joint)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Joint wallet policy updated", map[string]interface{}{
+		"joint_id":           jointID,
+		"user_id":            userID,
+		"approvals_required": joint.ApprovalsRequired,
+	})
+	s.settlePending(jointID)
+
+	return joint, nil
+}
+
+// Invite asks another user to join with the given role; owners only
+// Invite başka bir kullanıcıyı verilen rolle katılmaya davet eder; sadece sahipler
+func (s *JointWalletService) Invite(userID, jointID, inviteeID uint, input JointMemberInput) (*models.JointMember, error) {
+	var invited *models.JointMember
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		joint, member, err := s.access(repos.Joint, userID, jointID)
+		if err != nil {
+			return err
+		}
+		if member.Role != models.JointRoleOwner {
+			return ErrJointRole
+		}
+		if _, err := repos.Users.FindByID(inviteeID); err != nil {
+			return errors.New("user to invite not found")
+		}
+
+		// A declined or removed member can be invited again on the same row
+		// Reddetmiş veya çıkarılmış bir üye aynı satır üzerinden yeniden davet edilebilir
+		invited, err = repos.Joint.FindMember(joint.ID, inviteeID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			invited = &models.JointMember{JointWalletID: joint.ID, UserID: inviteeID}
+		case err != nil:
+			return err
+		case invited.Status == models.JointMemberActive || invited.Status == models.JointMemberInvited:
+			return errors.New("user is already a member or invited")
+		}
+
+		if err := s.applyMemberInput(joint, invited, input); err != nil {
+			return err
+		}
+		invited.Status = models.JointMemberInvited
+		invited.Currency = joint.Currency
+		invited.InvitedBy = userID
+		invited.JoinedAt = nil
+		return repos.Joint.SaveMember(invited)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Joint wallet invitation sent", map[string]interface{}{
+		"joint_id":   jointID,
+		"user_id":    userID,
+		"invitee_id": inviteeID,
+		"role":       invited.Role,
+	})
+
+	return invited, nil
+}
+
+// Respond accepts or declines the user's invitation to a joint wallet
+// Respond kullanıcının bir ortak cüzdana davetini kabul eder veya reddeder
+func (s *JointWalletService) Respond(userID, jointID uint, accept bool) (*models.JointMember, error) {
+	var member *models.JointMember
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		member, err = repos.Joint.FindMember(jointID, userID)
+		if err != nil {
+			return err
+		}
+		if member.Status != models.JointMemberInvited {
+			return errors.New("no open invitation to this joint wallet")
+		}
+
+		member.Status = models.JointMemberDeclined
+		if accept {
+			now := time.Now()
+			member.Status = models.JointMemberActive
+			member.JoinedAt = &now
+		}
+		return repos.Joint.SaveMember(member)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Joint wallet invitation answered", map[string]interface{}{
+		"joint_id": jointID,
+		"user_id":  userID,
+		"status":   member.Status,
+	})
+
+	return member, nil
+}
+
+// UpdateMember replaces a member's role and daily limit; owners only.
+// The last active owner cannot be demoted.
+//
+// UpdateMember bir üyenin rolünü ve günlük limitini değiştirir; sadece sahipler.
+// Son aktif sahibin rolü düşürülemez.
+func (s *JointWalletService) UpdateMember(userID, jointID, memberID uint, input JointMemberInput) (*models.JointMember, error) {
+	var target *models.JointMember
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		joint, member, err := s.access(repos.Joint, userID, jointID)
+		if err != nil {
+			return err
+		}
+		if member.Role != models.JointRoleOwner {
+			return ErrJointRole
+		}
+
+		target, err = s.member(repos, joint.ID, memberID)
+		if err != nil {
+			return err
+		}
+		if input.Role != models.JointRoleOwner {
+			if err := s.keepAnOwner(repos, target); err != nil {
+				return err
+			}
+		}
+
+		if err := s.applyMemberInput(joint, target, input); err != nil {
+			return err
+		}
+		if err := repos.Joint.SaveMember(target); err != nil {
+			return err
+		}
+		return s.fitPolicy(repos, joint)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Joint wallet member updated", map[string]interface{}{
+		"joint_id":  jointID,
+		"user_id":   userID,
+		"member_id": memberID,
+		"role":      target.Role,
+	})
+	s.settlePending(jointID)
+
+	return target, nil
+}
+
+// RemoveMember takes a member out of the joint wallet. Owners can remove anyone and
+// members can leave; the last active owner cannot go while the wallet has other members.
+//
+// RemoveMember bir üyeyi ortak cüzdandan çıkarır. Sahipler herkesi çıkarabilir, üyeler
+// ayrılabilir; cüzdanın başka üyeleri varken son aktif sahip ayrılamaz.
+func (s *JointWalletService) RemoveMember(userID, jointID, memberID uint) error {
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		joint, member, err := s.access(repos.Joint, userID, jointID)
+		if err != nil {
+			return err
+		}
+		if member.Role != models.JointRoleOwner && memberID != userID {
+			return ErrJointRole
+		}
+
+		target, err := s.member(repos, joint.ID, memberID)
+		if err != nil {
+			return err
+		}
+		if err := s.keepAnOwner(repos, target); err != nil {
+			return err
+		}
+
+		target.Status = models.JointMemberRemoved
+		if err := repos.Joint.SaveMember(target); err != nil {
+			return err
+		}
+		return s.fitPolicy(repos, joint)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Joint wallet member removed", map[string]interface{}{
+		"joint_id":  jointID,
+		"user_id":   userID,
+		"member_id": memberID,
+	})
+	s.settlePending(jointID)
+
+	return nil
+}
+
+// Contribute moves money from the member's own wallet in the same currency into the joint wallet
+// Contribute üyenin aynı para birimindeki kendi cüzdanından ortak cüzdana para taşır
+func (s *JointWalletService) Contribute(userID, jointID uint, value models.Decimal) (*JointWalletView, error) {
+	var view *JointWalletView
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			joint, member, err := s.access(repos.Joint, userID, jointID)
+			if err != nil {
+				return err
+			}
+			if !member.CanSpend() {
+				return ErrJointRole
+			}
+
+			_, amount, err := resolveAmount(joint.Currency, s.defaultCurrency, value)
+			if err != nil {
+				return err
+			}
+			if amount <= 0 {
+				return errors.New("invalid amount")
+			}
+
+			personal, err := repos.Wallets.FindByUserAndCurrency(userID, joint.Currency)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("no %s wallet to contribute from", joint.Currency)
+				}
+				return err
+			}
+			shared, err := repos.Wallets.FindByJoint(joint.ID)
+			if err != nil {
+				return err
+			}
+			if personal.Available() < amount {
+				return ErrInsufficientFunds
+			}
+
+			ledger := s.ledgerService.WithTx(repos)
+			history := s.transactionService.WithTx(repos)
+
+			personalAccount, err := ledger.WalletAccount(personal)
+			if err != nil {
+				return err
+			}
+			sharedAccount, err := ledger.WalletAccount(shared)
+			if err != nil {
+				return err
+			}
+
+			credited, err := models.NewMoney(shared.Balance, joint.Currency).Add(models.NewMoney(amount, joint.Currency))
+			if err != nil {
+				return err
+			}
+			if err := repos.Wallets.UpdateBalance(personal, personal.Balance-amount); err != nil {
+				return err
+			}
+			if err := repos.Wallets.UpdateBalance(shared, credited.Minor); err != nil {
+				return err
+			}
+
+			// POST LEDGER ENTRY: member's wallet down, joint wallet up
+			// Defter kaydı: üyenin cüzdanı azalır, ortak cüzdan artar
+			if err := ledger.Post(models.TransactionTypeJointContribution, fmt.Sprintf("contribution user:%d -> joint:%d", userID, joint.ID),
+				LedgerLine{Account: personalAccount, Amount: -amount},
+				LedgerLine{Account: sharedAccount, Amount: amount},
+			); err != nil {
+				return err
+			}
+
+			correlationID := models.NewCorrelationID()
+			if err := history.RecordEntry(&models.Transaction{
+				UserID:        userID,
+				Type:          models.TransactionTypeJointContribution,
+				Amount:        amount,
+				Currency:      joint.Currency,
+				BalanceAfter:  personal.Balance,
+				CorrelationID: correlationID,
+				Reason:        joint.Name,
+			}); err != nil {
+				return err
+			}
+			if err := history.RecordEntry(&models.Transaction{
+				JointID:       joint.ID,
+				InitiatedBy:   &userID,
+				Type:          models.TransactionTypeContributionReceived,
+				Amount:        amount,
+				Currency:      joint.Currency,
+				BalanceAfter:  shared.Balance,
+				CorrelationID: correlationID,
+			}); err != nil {
+				return err
+			}
+
+			view = s.view(joint, member, shared, nil)
+			return nil
+		})
+	})
+	if err != nil {
+		s.log.Error("Joint wallet contribution failed", map[string]interface{}{
+			"joint_id": jointID,
+			"user_id":  userID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Joint wallet contribution successful", map[string]interface{}{
+		"joint_id": jointID,
+		"user_id":  userID,
+	})
+
+	return view, nil
+}
+
+// Withdraw takes money out of the joint wallet. Above the approval threshold the withdrawal
+// waits, already approved by its initiator, until enough owners and spenders approve it.
+//
+// Withdraw ortak cüzdandan para çeker. Onay eşiğinin üzerindeki çekim, başlatanca onaylanmış
+// olarak, yeterli sahip ve harcayıcı onaylayana kadar bekler.
+func (s *JointWalletService) Withdraw(userID, jointID uint, value models.Decimal) (*JointWithdrawalResult, error) {
+	result, err := s.request(userID, jointID, value, nil)
+	if err != nil {
+		s.log.Error("Joint wallet withdraw failed", map[string]interface{}{
+			"joint_id": jointID,
+			"user_id":  userID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Joint wallet withdraw accepted", map[string]interface{}{
+		"joint_id": jointID,
+		"user_id":  userID,
+		"pending":  result.Withdrawal != nil,
+	})
+
+	return result, nil
+}
+
+// Transfer pays a user from the joint wallet; the recipient sees the initiating member as the sender.
+// It follows the same approval policy as Withdraw, so a large payment waits for approvals too.
+//
+// Transfer ortak cüzdandan bir kullanıcıya ödeme yapar; alıcı gönderen olarak işlemi başlatan üyeyi görür.
+// Withdraw ile aynı onay politikasına uyar, böylece büyük bir ödeme de onay bekler.
+func (s *JointWalletService) Transfer(userID, jointID, toUserID uint, value models.Decimal) (*JointWithdrawalResult, error) {
+	if toUserID == userID {
+		return nil, errors.New("cannot transfer to self; withdraw from the joint wallet instead")
+	}
+
+	result, err := s.request(userID, jointID, value, &toUserID)
+	if err != nil {
+		s.log.Error("Joint wallet transfer failed", map[string]interface{}{
+			"joint_id": jointID,
+			"user_id":  userID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Joint wallet transfer accepted", map[string]interface{}{
+		"joint_id": jointID,
+		"user_id":  userID,
+		"to_user":  toUserID,
+		"pending":  result.Withdrawal != nil,
+	})
+
+	return result, nil
+}
+
+// Withdrawals lists the joint wallet's withdrawals that needed approvals, pending ones first
+// Withdrawals ortak cüzdanın onay gerektiren çekimlerini listeler, önce bekleyenler
+func (s *JointWalletService) Withdrawals(userID, jointID uint) ([]models.JointWithdrawal, error) {
+	joint, _, err := s.access(s.jointRepo, userID, jointID)
+	if err != nil {
+		return nil, err
+	}
+	return s.jointRepo.FindWithdrawals(joint.ID)
+}
+
+// Approve adds the member's approval to a pending withdrawal and executes it once the
+// approvals of current owners and spenders reach the required count. If the withdrawal
+// cannot be executed (e.g. the balance is short), the approval is rolled back with it.
+//
+// Approve üyenin onayını bekleyen bir çekime ekler ve mevcut sahip ve harcayıcıların
+// onayları gereken sayıya ulaşınca çekimi gerçekleştirir. Çekim gerçekleştirilemezse
+// (örn. bakiye yetersizse) onay da onunla birlikte geri alınır.
+func (s *JointWalletService) Approve(userID, jointID, withdrawalID uint) (*JointWithdrawalResult, error) {
+	result := &JointWithdrawalResult{}
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			joint, member, withdrawal, err := s.pendingWithdrawal(repos, userID, jointID, withdrawalID)
+			if err != nil {
+				return err
+			}
+			result.Withdrawal = withdrawal
+
+			for _, approval := range withdrawal.Approvals {
+				if approval.UserID == userID {
+					return errors.New("you already approved this withdrawal")
+				}
+			}
+			if err := repos.Joint.AddApproval(withdrawal, member.UserID); err != nil {
+				return err
+			}
+
+			result.Fee, err = s.settle(repos, joint, withdrawal)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Joint wallet withdrawal approved", map[string]interface{}{
+		"joint_id":      jointID,
+		"user_id":       userID,
+		"withdrawal_id": withdrawalID,
+		"status":        result.Withdrawal.Status,
+	})
+
+	return result, nil
+}
+
+// Reject stops a pending withdrawal; any owner or spender, the initiator included, may reject it
+// Reject bekleyen bir çekimi durdurur; başlatan dahil herhangi bir sahip veya harcayıcı reddedebilir
+func (s *JointWalletService) Reject(userID, jointID, withdrawalID uint) (*models.JointWithdrawal, error) {
+	var withdrawal *models.JointWithdrawal
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		_, _, withdrawal, err = s.pendingWithdrawal(repos, userID, jointID, withdrawalID)
+		if err != nil {
+			return err
+		}
+		return repos.Joint.CloseWithdrawal(withdrawal, models.JointWithdrawalRejected, nil, &userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Joint wallet withdrawal rejected", map[string]interface{}{
+		"joint_id":      jointID,
+		"user_id":       userID,
+		"withdrawal_id": withdrawalID,
+	})
+
+	return withdrawal, nil
+}
+
+// History returns one page of the joint wallet's history, newest first
+// History ortak cüzdanın geçmişinin bir sayfasını yeniden eskiye döndürür
+func (s *JointWalletService) History(userID, jointID uint, cursor string, limit int) (*HistoryPage, error) {
+	joint, _, err := s.access(s.jointRepo, userID, jointID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transactionService.GetHistory(0, repositories.HistoryFilter{JointID: joint.ID}, cursor, limit)
+}
+
+// access loads a joint wallet and the user's active membership.
+// Users who are not active members get gorm.ErrRecordNotFound, as if it did not exist.
+//
+// access bir ortak cüzdanı ve kullanıcının aktif üyeliğini yükler.
+// Aktif üye olmayan kullanıcılar, cüzdan yokmuş gibi gorm.ErrRecordNotFound alır.
+func (s *JointWalletService) access(jointRepo *repositories.JointWalletRepository, userID, jointID uint) (*models.JointWallet, *models.JointMember, error) {
+	member, err := jointRepo.FindMember(jointID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if member.Status != models.JointMemberActive {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	joint, err := jointRepo.FindByID(jointID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return joint, member, nil
+}
+
+// member loads another active or invited member of the joint wallet
+// member ortak cüzdanın başka bir aktif veya davetli üyesini yükler
+func (s *JointWalletService) member(repos *repositories.Repositories, jointID, memberID uint) (*models.JointMember, error) {
+	target, err := repos.Joint.FindMember(jointID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Status != models.JointMemberActive && target.Status != models.JointMemberInvited {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return target, nil
+}
+
+// keepAnOwner refuses to demote or remove the last active owner
+// keepAnOwner son aktif sahibin rolünün düşürülmesini veya çıkarılmasını reddeder
+func (s *JointWalletService) keepAnOwner(repos *repositories.Repositories, target *models.JointMember) error {
+	if target.Role != models.JointRoleOwner || target.Status != models.JointMemberActive {
+		return nil
+	}
+	owners, err := repos.Joint.CountActive(target.JointWalletID, models.JointRoleOwner)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return errors.New("a joint wallet needs at least one owner")
+	}
+	return nil
+}
+
+// fitPolicy lowers the required approvals when members leave or lose their role,
+// so future withdrawals can still collect enough of them; pending ones are settled
+// by settlePending once the change has committed.
+//
+// fitPolicy üyeler ayrıldığında veya rolünü kaybettiğinde gereken onay sayısını düşürür,
+// böylece gelecekteki çekimler yeterli onayı toplayabilir; bekleyenler değişiklik commit
+// edildikten sonra settlePending tarafından sonuçlandırılır.
+func (s *JointWalletService) fitPolicy(repos *repositories.Repositories, joint *models.JointWallet) error {
+	if joint.ApprovalThreshold == nil {
+		return nil
+	}
+	signers, err := repos.Joint.CountActive(joint.ID, models.JointRoleOwner, models.JointRoleSpender)
+	if err != nil {
+		return err
+	}
+	if int64(joint.ApprovalsRequired) <= signers {
+		return nil
+	}
+
+	s.log.Info("Joint wallet approvals lowered to the remaining signers", map[string]interface{}{
+		"joint_id": joint.ID,
+		"signers":  signers,
+	})
+	joint.ApprovalsRequired = int(signers)
+	return repos.Joint.UpdatePolicy(joint)
+}
+
+// settle executes a pending withdrawal once the approvals of current owners and spenders
+// reach the required number. It returns a nil fee while more approvals are needed.
+//
+// settle bekleyen bir çekimi, mevcut sahip ve harcayıcıların onayları gereken sayıya
+// ulaşınca gerçekleştirir. Daha fazla onay gerekirken nil ücret döndürür.
+func (s *JointWalletService) settle(repos *repositories.Repositories, joint *models.JointWallet, withdrawal *models.JointWithdrawal) (*Fee, error) {
+	// Approvals of members who left or lost their role no longer count
+	// Ayrılan veya rolünü kaybeden üyelerin onayları artık sayılmaz
+	approvals := 0
+	for _, approval := range withdrawal.Approvals {
+		approver, err := repos.Joint.FindMember(joint.ID, approval.UserID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if approver != nil && approver.CanSpend() {
+			approvals++
+		}
+	}
+	if approvals < joint.ApprovalsRequired {
+		return nil, nil
+	}
+
+	initiator, err := repos.Joint.FindMember(joint.ID, withdrawal.InitiatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !initiator.CanSpend() {
+		return nil, errors.New("the member who started this withdrawal can no longer spend from the wallet")
+	}
+
+	transaction, fee, err := s.spend(repos, joint, initiator, withdrawal.Amount, withdrawal.ToUserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := repos.Joint.CloseWithdrawal(withdrawal, models.JointWithdrawalExecuted, &transaction.ID, nil); err != nil {
+		return nil, err
+	}
+	return fee, nil
+}
+
+// settlePending looks at the pending withdrawals again after the policy or the members changed.
+// One that already has every approval now required is executed; if it can no longer be executed
+// it is rejected, because nobody is left to approve it again. Each runs in its own unit of work,
+// so one failure does not hold back the others or the change that triggered it.
+//
+// settlePending politika veya üyeler değiştikten sonra bekleyen çekimlere yeniden bakar.
+// Artık gereken tüm onaylara sahip olan gerçekleştirilir; artık gerçekleştirilemiyorsa
+// reddedilir, çünkü onu yeniden onaylayacak kimse kalmamıştır. Her biri kendi unit of work'ünde
+// çalışır, böylece bir hata diğerlerini veya onu tetikleyen değişikliği engellemez.
+func (s *JointWalletService) settlePending(jointID uint) {
+	withdrawals, err := s.jointRepo.FindWithdrawals(jointID)
+	if err != nil {
+		s.log.Error("Failed to load pending joint withdrawals", map[string]interface{}{
+			"joint_id": jointID,
+			"error":    err.Error(),
+		})
+		return
+	}
+
+	// Oldest first, so earlier requests get the balance first
+	// En eskiden başlayarak, böylece bakiyeyi önce daha erken istekler alır
+	for i := len(withdrawals) - 1; i >= 0; i-- {
+		pending := withdrawals[i]
+		if pending.Status != models.JointWithdrawalPending {
+			continue
+		}
+
+		var executed bool
+		err := retryOnConflict(s.log, func() error {
+			return s.uow.Do(func(repos *repositories.Repositories) error {
+				joint, err := repos.Joint.FindByID(jointID)
+				if err != nil {
+					return err
+				}
+				withdrawal, err := repos.Joint.FindWithdrawal(pending.ID)
+				if err != nil {
+					return err
+				}
+				if withdrawal.Status != models.JointWithdrawalPending {
+					return nil
+				}
+				if _, err := s.settle(repos, joint, withdrawal); err != nil {
+					return err
+				}
+				executed = withdrawal.Status == models.JointWithdrawalExecuted
+				return nil
+			})
+		})
+		if err == nil {
+			if executed {
+				s.log.Info("Approved joint withdrawal executed after a policy change", map[string]interface{}{
+					"joint_id":      jointID,
+					"withdrawal_id": pending.ID,
+				})
+			}
+			continue
+		}
+
+		s.log.Error("Approved joint withdrawal could not be executed; rejecting it", map[string]interface{}{
+			"joint_id":      jointID,
+			"withdrawal_id": pending.ID,
+			"error":         err.Error(),
+		})
+		if err := s.uow.Do(func(repos *repositories.Repositories) error {
+			return repos.Joint.CloseWithdrawal(&pending, models.JointWithdrawalRejected, nil, nil)
+		}); err != nil && !errors.Is(err, repositories.ErrWithdrawalNotPending) {
+			s.log.Error("Failed to reject joint withdrawal", map[string]interface{}{
+				"joint_id":      jointID,
+				"withdrawal_id": pending.ID,
+				"error":         err.Error(),
+			})
+		}
+	}
+}
+
+// applyMemberInput validates and copies a role and daily limit onto a member
+// applyMemberInput bir rolü ve günlük limiti doğrulayıp üyeye kopyalar
+func (s *JointWalletService) applyMemberInput(joint *models.JointWallet, member *models.JointMember, input JointMemberInput) error {
+	switch input.Role {
+	case models.JointRoleOwner, models.JointRoleSpender, models.JointRoleViewer:
+	default:
+		return errors.New("role must be owner, spender or viewer")
+	}
+
+	member.Role = input.Role
+	member.DailyLimit = nil
+	if input.DailyLimit != nil {
+		_, limit, err := resolveAmount(joint.Currency, s.defaultCurrency, *input.DailyLimit)
+		if err != nil {
+			return err
+		}
+		member.DailyLimit = &limit
+	}
+	return nil
+}
+
+// request spends from the joint wallet right away, or opens a pending withdrawal approved by
+// its initiator when the amount needs approvals. toUserID is set for a transfer.
+//
+// request ortak cüzdandan hemen harcar veya tutar onay gerektiriyorsa başlatanca onaylanmış
+// bekleyen bir çekim açar. Transfer için toUserID doludur.
+func (s *JointWalletService) request(userID, jointID uint, value models.Decimal, toUserID *uint) (*JointWithdrawalResult, error) {
+	result := &JointWithdrawalResult{}
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			joint, member, err := s.access(repos.Joint, userID, jointID)
+			if err != nil {
+				return err
+			}
+			if !member.CanSpend() {
+				return ErrJointRole
+			}
+
+			_, amount, err := resolveAmount(joint.Currency, s.defaultCurrency, value)
+			if err != nil {
+				return err
+			}
+			if amount <= 0 && toUserID != nil {
+				return errors.New("invalid transfer amount")
+			}
+			if amount <= 0 {
+				return errors.New("invalid withdraw amount")
+			}
+
+			if !joint.NeedsApproval(amount) {
+				_, result.Fee, err = s.spend(repos, joint, member, amount, toUserID)
+				return err
+			}
+
+			// Fail early on what the initiator can already see; execution checks again
+			// Başlatanın zaten görebildiği sorunlarda erken hata ver; gerçekleştirme yeniden kontrol eder
+			if err := s.checkSpend(repos, joint, member, amount, toUserID); err != nil {
+				return err
+			}
+
+			withdrawal := &models.JointWithdrawal{
+				JointWalletID: joint.ID,
+				InitiatedBy:   userID,
+				Amount:        amount,
+				Currency:      joint.Currency,
+				ToUserID:      toUserID,
+				Status:        models.JointWithdrawalPending,
+			}
+			if err := repos.Joint.CreateWithdrawal(withdrawal); err != nil {
+				return err
+			}
+			result.Withdrawal = withdrawal
+			return repos.Joint.AddApproval(withdrawal, userID)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// pendingWithdrawal loads a pending withdrawal of the joint wallet for an owner or spender
+// pendingWithdrawal bir sahip veya harcayıcı için ortak cüzdanın bekleyen bir çekimini yükler
+func (s *JointWalletService) pendingWithdrawal(repos *repositories.Repositories, userID, jointID, withdrawalID uint) (*models.JointWallet, *models.JointMember, *models.JointWithdrawal, error) {
+	joint, member, err := s.access(repos.Joint, userID, jointID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !member.CanSpend() {
+		return nil, nil, nil, ErrJointRole
+	}
+
+	withdrawal, err := repos.Joint.FindWithdrawal(withdrawalID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if withdrawal.JointWalletID != joint.ID {
+		return nil, nil, nil, gorm.ErrRecordNotFound
+	}
+	if withdrawal.Status != models.JointWithdrawalPending {
+		return nil, nil, nil, repositories.ErrWithdrawalNotPending
+	}
+	return joint, member, withdrawal, nil
+}
+
+// checkDailyLimit refuses an amount that would take the member past their daily limit
+// checkDailyLimit üyeyi günlük limitinin üzerine çıkaracak bir tutarı reddeder
+func (s *JointWalletService) checkDailyLimit(repos *repositories.Repositories, joint *models.JointWallet, member *models.JointMember, amount int64) error {
+	if member.DailyLimit == nil {
+		return nil
+	}
+
+	used, err := repos.Transactions.SumInitiatedSince(joint.ID, member.UserID, jointSpendTypes, startOfDay(time.Now().UTC()))
+	if err != nil {
+		return err
+	}
+	remaining := *member.DailyLimit - used
+	if amount <= remaining {
+		return nil
+	}
+
+	currency, err := models.LookupCurrency(joint.Currency)
+	if err != nil {
+		return err
+	}
+	return &LimitError{
+		Operation: "joint wallet spending",
+		Window:    LimitWindowDaily,
+		Remaining: max(remaining, 0),
+		Currency:  currency,
+	}
+}
+
+// checkSpend refuses a withdrawal or transfer the member may not make: one past their daily
+// limit in the joint wallet or past their own withdraw or transfer limits, or one to a missing user
+//
+// checkSpend üyenin yapamayacağı bir çekim veya transferi reddeder: ortak cüzdandaki günlük
+// limitini veya kendi çekim ya da transfer limitlerini aşanı veya olmayan bir kullanıcıya olanı
+func (s *JointWalletService) checkSpend(repos *repositories.Repositories, joint *models.JointWallet, member *models.JointMember, amount int64, toUserID *uint) error {
+	operation := models.LimitOperationWithdraw
+	if toUserID != nil {
+		operation = models.LimitOperationTransfer
+		if _, err := repos.Users.FindByID(*toUserID); err != nil {
+			return errors.New("recipient not found")
+		}
+	}
+
+	if err := s.checkDailyLimit(repos, joint, member, amount); err != nil {
+		return err
+	}
+	return s.limitService.Check(repos, member.UserID, operation, joint.Currency, amount)
+}
+
+// spend withdraws from the joint wallet, or pays toUserID when it is set, inside the caller's
+// unit of work. The member's limits are checked here, so every path out of the wallet is capped.
+//
+// spend çağıranın unit of work'ü içinde ortak cüzdandan para çeker veya toUserID doluysa ona öder.
+// Üyenin limitleri burada kontrol edilir, böylece cüzdandan her çıkış yolu sınırlanır.
+func (s *JointWalletService) spend(repos *repositories.Repositories, joint *models.JointWallet, member *models.JointMember, amount int64, toUserID *uint) (*models.Transaction, *Fee, error) {
+	ledger := s.ledgerService.WithTx(repos)
+	history := s.transactionService.WithTx(repos)
+
+	if err := s.checkSpend(repos, joint, member, amount, toUserID); err != nil {
+		return nil, nil, err
+	}
+
+	wallet, err := repos.Wallets.FindByJoint(joint.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	operation, txType := fees.OperationWithdraw, models.TransactionTypeWithdraw
+	if toUserID != nil {
+		operation, txType = fees.OperationTransfer, models.TransactionTypeTransferSent
+	}
+	fee, err := s.feeService.Quote(operation, joint.Currency, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	total, err := models.NewMoney(amount, joint.Currency).Add(fee.Amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	if wallet.Available() < total.Minor {
+		return nil, nil, ErrInsufficientFunds
+	}
+
+	walletAccount, err := ledger.WalletAccount(wallet)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := repos.Wallets.UpdateBalance(wallet, wallet.Balance-amount); err != nil {
+		return nil, nil, err
+	}
+
+	correlationID := models.NewCorrelationID()
+	initiatedBy := member.UserID
+	entry := &models.Transaction{
+		JointID:       joint.ID,
+		InitiatedBy:   &initiatedBy,
+		Type:          txType,
+		Amount:        amount,
+		Currency:      joint.Currency,
+		TargetUserID:  toUserID,
+		BalanceAfter:  wallet.Balance,
+		CorrelationID: correlationID,
+	}
+
+	if toUserID == nil {
+		withdrawAccount, err := ledger.SystemAccount(models.SystemAccountWithdrawals, joint.Currency)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// POST LEDGER ENTRY: joint wallet down, withdrawals counter account up
+		// Defter kaydı: ortak cüzdan azalır, çekim karşı hesabı artar
+		if err := ledger.Post(models.TransactionTypeWithdraw, fmt.Sprintf("withdraw joint:%d by user:%d", joint.ID, member.UserID),
+			LedgerLine{Account: walletAccount, Amount: -amount},
+			LedgerLine{Account: withdrawAccount, Amount: amount},
+		); err != nil {
+			return nil, nil, err
+		}
+		if err := history.RecordEntry(entry); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		recipient, err := repos.Wallets.FindOrCreate(*toUserID, joint.Currency)
+		if err != nil {
+			return nil, nil, err
+		}
+		recipientAccount, err := ledger.WalletAccount(recipient)
+		if err != nil {
+			return nil, nil, err
+		}
+		credited, err := models.NewMoney(recipient.Balance, joint.Currency).Add(models.NewMoney(amount, joint.Currency))
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := repos.Wallets.UpdateBalance(recipient, credited.Minor); err != nil {
+			return nil, nil, err
+		}
+
+		// POST LEDGER ENTRY: joint wallet down, recipient up
+		// Defter kaydı: ortak cüzdan azalır, alıcı artar
+		if err := ledger.Post(models.JournalEntryTransfer, fmt.Sprintf("transfer joint:%d by user:%d -> user:%d", joint.ID, member.UserID, *toUserID),
+			LedgerLine{Account: walletAccount, Amount: -amount},
+			LedgerLine{Account: recipientAccount, Amount: amount},
+		); err != nil {
+			return nil, nil, err
+		}
+
+		if err := history.RecordEntry(entry); err != nil {
+			return nil, nil, err
+		}
+		if err := history.RecordEntry(&models.Transaction{
+			UserID:        *toUserID,
+			Type:          models.TransactionTypeTransferReceived,
+			Amount:        amount,
+			Currency:      joint.Currency,
+			TargetUserID:  &initiatedBy,
+			BalanceAfter:  recipient.Balance,
+			CorrelationID: correlationID,
+			Reason:        joint.Name,
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := s.feeService.Charge(repos, wallet, fee, correlationID, &initiatedBy); err != nil {
+		return nil, nil, err
+	}
+	return entry, fee, nil
+}
+
+// view builds what one member sees of a joint wallet; wallet is nil for invited users
+// view bir üyenin ortak cüzdandan gördüğünü oluşturur; davetli kullanıcılar için wallet nil'dir
+func (s *JointWalletService) view(joint *models.JointWallet, member *models.JointMember, wallet *models.Wallet, members []models.JointMember) *JointWalletView {
+	view := &JointWalletView{
+		JointWallet: joint,
+		Role:        member.Role,
+		Status:      member.Status,
+		Members:     members,
+	}
+	if wallet != nil {
+		balance := models.NewMoney(wallet.Balance, wallet.Currency)
+		available := models.NewMoney(wallet.Available(), wallet.Currency)
+		view.Balance, view.Available = &balance, &available
+	}
+	return view
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnbalancedEntry is returned when postings of an entry do not sum to zero
+	// ErrUnbalancedEntry bir kaydın hareketleri toplamı sıfır olmadığında döner
+	ErrUnbalancedEntry = errors.New("ledger entry does not balance")
+
+	// ErrLedgerMismatch is returned when a wallet balance drifts from its postings
+	// ErrLedgerMismatch cüzdan bakiyesi defter hareketlerinden saptığında döner
+	ErrLedgerMismatch = errors.New("wallet balance does not match ledger")
+)
+
+// LedgerLine is one side of a money movement before it is persisted
+// LedgerLine bir para hareketinin kaydedilmeden önceki bir tarafıdır
+type LedgerLine struct {
+	Account *models.LedgerAccount
+	Amount  int64
+}
+
+// LedgerService keeps the double-entry books behind every wallet operation
+// LedgerService her cüzdan işleminin arkasındaki çift taraflı defteri tutar
+type LedgerService struct {
+	ledgerRepo *repositories.LedgerRepository
+	log        logger.Logger
+}
+
+func NewLedgerService(repo *repositories.LedgerRepository, log logger.Logger) *LedgerService {
+	return &LedgerService{ledgerRepo: repo, log: log}
+}
+
+// WithTx returns a copy of the service bound to a unit of work
+// WithTx bir unit of work'e bağlı servis kopyası döndürür
+func (s *LedgerService) WithTx(repos *repositories.Repositories) *LedgerService {
+	return &LedgerService{ledgerRepo: repos.Ledger, log: s.log}
+}
+
+// WalletAccount returns the ledger account of a wallet.
+// Wallets created before the ledger get an opening balance entry on first use,
+// so it must be called before the wallet balance is changed.
+//
+// WalletAccount cüzdanın defter hesabını döndürür.
+// Defterden önce açılmış cüzdanlara ilk kullanımda açılış kaydı eklenir,
+// bu yüzden bakiye değişmeden önce çağrılmalıdır.
+func (s *LedgerService) WalletAccount(wallet *models.Wallet) (*models.LedgerAccount, error) {
+	code := fmt.Sprintf("wallet:%d", wallet.ID)
+
+	account, created, err := s.ledgerRepo.FindOrCreateAccount(code, models.LedgerAccountWallet, wallet.Currency, &wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if created && wallet.Balance != 0 {
+		opening, err := s.SystemAccount(models.SystemAccountOpening, wallet.Currency)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Post(models.JournalEntryOpeningBalance, code,
+			LedgerLine{Account: account, Amount: wallet.Balance},
+			LedgerLine{Account: opening, Amount: -wallet.Balance},
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	return account, nil
+}
+
+// PotAccount returns (and lazily creates) the ledger account of a savings pot
+// PotAccount bir birikim kumbarasının defter hesabını döndürür (gerekirse oluşturur)
+func (s *LedgerService) PotAccount(pot *models.Pot) (*models.LedgerAccount, error) {
+	account, _, err := s.ledgerRepo.FindOrCreateAccount(fmt.Sprintf("pot:%d", pot.ID), models.LedgerAccountPot, pot.Currency, nil)
+	return account, err
+}
+
+// EscrowAccount returns (and lazily creates) the ledger account of an escrow payment
+// EscrowAccount bir emanet ödemesinin defter hesabını döndürür (gerekirse oluşturur)
+func (s *LedgerService) EscrowAccount(escrow *models.Escrow) (*models.LedgerAccount, error) {
+	account, _, err := s.ledgerRepo.FindOrCreateAccount(fmt.Sprintf("escrow:%d", escrow.ID), models.LedgerAccountEscrow, escrow.Currency, nil)
+	return account, err
+}
+
+// SystemAccount returns (and lazily creates) an internal account in the given currency
+// SystemAccount verilen para birimindeki dahili bir hesabı döndürür (gerekirse oluşturur)
+func (s *LedgerService) SystemAccount(code, currency string) (*models.LedgerAccount, error) {
+	account, _, err := s.ledgerRepo.FindOrCreateAccount(code+":"+currency, models.LedgerAccountSystem, currency, nil)
+	return account, err
+}
+
+// Post writes a journal entry that balances in every currency it touches
+// Post dokunduğu her para biriminde dengeli olan bir yevmiye kaydı yazar
+func (s *LedgerService) Post(entryType, description string, lines ...LedgerLine) error {
+	if len(lines) < 2 {
+		return ErrUnbalancedEntry
+	}
+
+	sums := make(map[string]int64)
+	postings := make([]models.Posting, 0, len(lines))
+	for _, line := range lines {
+		if line.Amount == 0 {
+			return ErrUnbalancedEntry
+		}
+		sums[line.Account.Currency] += line.Amount
+		postings = append(postings, models.Posting{
+			AccountID: line.Account.ID,
+			Amount:    line.Amount,
+		})
+	}
+	for currency, sum := range sums {
+		if sum != 0 {
+			s.log.Error("Rejected unbalanced ledger entry", map[string]interface{}{
+				"type":     entryType,
+				"currency": currency,
+				"sum":      sum,
+			})
+			return ErrUnbalancedEntry
+		}
+	}
+
+	entry := &models.JournalEntry{
+		Type:        entryType,
+		Description: description,
+		Postings:    postings,
+	}
+	if err := s.ledgerRepo.CreateEntry(entry); err != nil {
+		s.log.Error("Failed to post ledger entry", map[string]interface{}{
+			"type": entryType,
+		})
+		return err
+	}
+
+	return nil
+}
+
+// VerifyWallet checks the wallet balance against the sum of its postings. It only reads:
+// a wallet without an account yet gets its opening entry on its next money movement.
+//
+// VerifyWallet cüzdan bakiyesini hareketlerin toplamı ile karşılaştırır. Sadece okur:
+// henüz hesabı olmayan bir cüzdanın açılış kaydı bir sonraki para hareketinde yazılır.
+func (s *LedgerService) VerifyWallet(wallet *models.Wallet) error {
+	account, err := s.ledgerRepo.FindAccountByWallet(wallet.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ledgerBalance, err := s.ledgerRepo.AccountBalance(account.ID)
+	if err != nil {
+		return err
+	}
+
+	if ledgerBalance != wallet.Balance {
+		s.log.Error("Wallet balance drifted from ledger", map[string]interface{}{
+			"wallet_id":      wallet.ID,
+			"balance":        wallet.Balance,
+			"ledger_balance": ledgerBalance,
+		})
+		return ErrLedgerMismatch
+	}
+
+	return nil
+}
+
+// TrialBalance returns the sum of all postings per currency; anything but zero means broken books
+// TrialBalance tüm hareketlerin para birimi bazında toplamını döndürür; sıfır dışı sonuç defterin bozuk olduğunu gösterir
+func (s *LedgerService) TrialBalance() (map[string]int64, error) {
+	return s.ledgerRepo.TotalBalances()
+}
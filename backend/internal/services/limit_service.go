@@ -0,0 +1,386 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/rates"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// ErrLimitExceeded is matched by every LimitError
+// ErrLimitExceeded her LimitError ile eşleşir
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// Limit windows
+// Limit pencereleri
+const (
+	LimitWindowPerTransaction = "per-transaction"
+	LimitWindowDaily          = "daily"
+	LimitWindowMonthly        = "monthly"
+)
+
+// limitTransactionTypes maps an operation to the history rows that use up its allowance
+// limitTransactionTypes bir işlemi, hakkını tüketen geçmiş satırlarına eşler
+var limitTransactionTypes = map[string][]string{
+	models.LimitOperationWithdraw: {models.TransactionTypeWithdraw},
+	models.LimitOperationTransfer: {models.TransactionTypeTransferSent, models.TransactionTypeEscrowFunded, models.TransactionTypeHoldCaptured},
+}
+
+// LimitError tells which cap an operation hit and how much is still allowed
+// LimitError bir işlemin hangi sınıra takıldığını ve hâlâ ne kadarına izin verildiğini söyler
+type LimitError struct {
+	Operation string
+	Window    string
+	Remaining int64
+	Currency  models.Currency
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %s limit exceeded, %s %s allowed", e.Window, e.Operation,
+		e.Currency.FormatMinor(e.Remaining), e.Currency.Code)
+}
+
+// Is makes errors.Is(err, ErrLimitExceeded) true for every LimitError
+// Is her LimitError için errors.Is(err, ErrLimitExceeded) sonucunu true yapar
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// LimitWindow shows one cap and how much of it is used; nil Limit means no cap
+// LimitWindow bir sınırı ve ne kadarının kullanıldığını gösterir; nil Limit sınır yok demektir
+type LimitWindow struct {
+	Limit     *models.Money `json:"limit"`
+	Used      models.Money  `json:"used"`
+	Remaining *models.Money `json:"remaining"`
+	ResetsAt  time.Time     `json:"resets_at"`
+}
+
+// LimitStatus is the allowance of one operation right now
+// LimitStatus bir işlemin şu anki kullanım hakkıdır
+type LimitStatus struct {
+	Operation      string        `json:"operation"`
+	Currency       string        `json:"currency"`
+	PerTransaction *models.Money `json:"per_transaction"`
+	Daily          LimitWindow   `json:"daily"`
+	Monthly        LimitWindow   `json:"monthly"`
+
+	// MaxAmount is the largest single operation allowed now; nil means no cap
+	// MaxAmount şu anda izin verilen en büyük tek işlemdir; nil sınır yok demektir
+	MaxAmount  *models.Money `json:"max_amount"`
+	Overridden bool          `json:"overridden"`
+}
+
+// LimitService enforces per-operation caps computed from transaction history.
+// Every cap is in the limit currency (the default currency); amounts in other
+// currencies are converted at the provider's mid rate.
+//
+// LimitService işlem geçmişinden hesaplanan işlem başına sınırları uygular.
+// Tüm sınırlar limit para birimindedir (varsayılan para birimi); diğer para
+// birimlerindeki tutarlar sağlayıcının orta kuru ile çevrilir.
+type LimitService struct {
+	limitRepo       *repositories.LimitRepository
+	transactionRepo *repositories.TransactionRepository
+	userRepo        *repositories.UserRepository
+	rateProvider    rates.Provider
+	defaults        map[string]models.Limits
+	currency        string
+	log             logger.Logger
+}
+
+func NewLimitService(
+	limitRepo *repositories.LimitRepository,
+	transactionRepo *repositories.TransactionRepository,
+	userRepo *repositories.UserRepository,
+	rateProvider rates.Provider,
+	defaults map[string]models.Limits,
+	currency string,
+	log logger.Logger,
+) *LimitService {
+	return &LimitService{
+		limitRepo:       limitRepo,
+		transactionRepo: transactionRepo,
+		userRepo:        userRepo,
+		rateProvider:    rateProvider,
+		defaults:        defaults,
+		currency:        currency,
+		log:             log,
+	}
+}
+
+// Check fails with a LimitError when amount would exceed one of the user's caps.
+// It runs inside the caller's unit of work, so writers are serialized and two
+// concurrent operations cannot both spend the last of the allowance.
+//
+// Check tutar kullanıcının sınırlarından birini aşacaksa LimitError döndürür.
+// Çağıranın unit of work'ü içinde çalışır, böylece yazıcılar sıraya girer ve
+// eşzamanlı iki işlem kalan hakkın aynı kısmını harcayamaz.
+func (s *LimitService) Check(repos *repositories.Repositories, userID uint, operation, currency string, amount int64) error {
+	limits, _, err := s.effective(repos.Limits, userID, operation)
+	if err != nil {
+		return err
+	}
+	if limits == (models.Limits{}) {
+		return nil
+	}
+
+	converted, err := s.toLimitCurrency(currency, amount)
+	if err != nil {
+		return err
+	}
+	limitCurrency, err := models.LookupCurrency(s.currency)
+	if err != nil {
+		return err
+	}
+
+	if limits.PerTransaction > 0 && converted > limits.PerTransaction {
+		return &LimitError{Operation: operation, Window: LimitWindowPerTransaction, Remaining: limits.PerTransaction, Currency: limitCurrency}
+	}
+
+	now := time.Now().UTC()
+	windows := []struct {
+		name  string
+		limit int64
+		since time.Time
+	}{
+		{LimitWindowDaily, limits.Daily, startOfDay(now)},
+		{LimitWindowMonthly, limits.Monthly, startOfMonth(now)},
+	}
+	for _, window := range windows {
+		if window.limit <= 0 {
+			continue
+		}
+		used, err := s.used(repos.Transactions, userID, operation, window.since)
+		if err != nil {
+			return err
+		}
+		total, err := used.Add(models.NewMoney(converted, s.currency))
+		if err != nil {
+			return err
+		}
+		if total.Minor > window.limit {
+			return &LimitError{Operation: operation, Window: window.name, Remaining: max(window.limit-used.Minor, 0), Currency: limitCurrency}
+		}
+	}
+
+	return nil
+}
+
+// Status returns the caps, usage and remaining allowance of every operation
+// Status her işlemin sınırlarını, kullanımını ve kalan hakkını döndürür
+func (s *LimitService) Status(userID uint) ([]LimitStatus, error) {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	dayStart, monthStart := startOfDay(now), startOfMonth(now)
+
+	statuses := make([]LimitStatus, 0, len(models.LimitOperations))
+	for _, operation := range models.LimitOperations {
+		limits, overridden, err := s.effective(s.limitRepo, userID, operation)
+		if err != nil {
+			return nil, err
+		}
+
+		status := LimitStatus{
+			Operation:      operation,
+			Currency:       s.currency,
+			PerTransaction: s.capOrNil(limits.PerTransaction),
+			Overridden:     overridden,
+		}
+
+		if status.Daily.Used, err = s.used(s.transactionRepo, userID, operation, dayStart); err != nil {
+			return nil, err
+		}
+		if status.Monthly.Used, err = s.used(s.transactionRepo, userID, operation, monthStart); err != nil {
+			return nil, err
+		}
+		status.Daily.fill(s.capOrNil(limits.Daily), dayStart.AddDate(0, 0, 1))
+		status.Monthly.fill(s.capOrNil(limits.Monthly), monthStart.AddDate(0, 1, 0))
+
+		for _, candidate := range []*models.Money{status.PerTransaction, status.Daily.Remaining, status.Monthly.Remaining} {
+			if candidate != nil && (status.MaxAmount == nil || candidate.Minor < status.MaxAmount.Minor) {
+				status.MaxAmount = candidate
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// SetOverride replaces caps of one operation for a user (admin only).
+// Caps are read in the limit currency; a nil cap keeps the default.
+//
+// SetOverride bir kullanıcı için bir işlemin sınırlarını değiştirir (sadece admin).
+// Sınırlar limit para biriminde okunur; nil sınır varsayılanı korur.
+func (s *LimitService) SetOverride(adminID, userID uint, operation string, perTransaction, daily, monthly *models.Decimal) (*models.LimitOverride, error) {
+	if _, ok := limitTransactionTypes[operation]; !ok {
+		return nil, errors.New("unknown limit operation")
+	}
+	limitCurrency, err := models.LookupCurrency(s.currency)
+	if err != nil {
+		return nil, err
+	}
+	caps := make([]*int64, 3)
+	for i, value := range []*models.Decimal{perTransaction, daily, monthly} {
+		if value == nil {
+			continue
+		}
+		amount, err := value.Minor(limitCurrency)
+		if err != nil {
+			return nil, err
+		}
+		caps[i] = &amount
+	}
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return nil, err
+	}
+
+	override := &models.LimitOverride{
+		UserID:         userID,
+		Operation:      operation,
+		PerTransaction: caps[0],
+		Daily:          caps[1],
+		Monthly:        caps[2],
+		SetBy:          adminID,
+	}
+	if err := s.limitRepo.Save(override); err != nil {
+		return nil, err
+	}
+
+	// Reload, since an upsert that updated an existing row leaves its ID and CreatedAt unset
+	// Tekrar oku, çünkü mevcut satırı güncelleyen bir upsert ID ve CreatedAt alanlarını boş bırakır
+	override, err = s.limitRepo.FindOverride(userID, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Limit override set", map[string]interface{}{
+		"user_id":   userID,
+		"operation": operation,
+		"admin_id":  adminID,
+	})
+
+	return override, nil
+}
+
+// ClearOverride makes the defaults apply to the user again
+// ClearOverride kullanıcıya tekrar varsayılanların uygulanmasını sağlar
+func (s *LimitService) ClearOverride(adminID, userID uint, operation string) error {
+	if _, ok := limitTransactionTypes[operation]; !ok {
+		return errors.New("unknown limit operation")
+	}
+	if err := s.limitRepo.Delete(userID, operation); err != nil {
+		return err
+	}
+
+	s.log.Info("Limit override cleared", map[string]interface{}{
+		"user_id":   userID,
+		"operation": operation,
+		"admin_id":  adminID,
+	})
+
+	return nil
+}
+
+// effective returns the defaults of the operation with the user's override applied
+// effective işlemin varsayılanlarını kullanıcının override'ı uygulanmış olarak döndürür
+func (s *LimitService) effective(repo *repositories.LimitRepository, userID uint, operation string) (models.Limits, bool, error) {
+	limits := s.defaults[operation]
+
+	override, err := repo.FindOverride(userID, operation)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return limits, false, nil
+	}
+	if err != nil {
+		return models.Limits{}, false, err
+	}
+	return override.Apply(limits), true, nil
+}
+
+// used sums what the user spent on the operation since t, in the limit currency
+// used kullanıcının t anından beri işlem için harcadığını limit para biriminde toplar
+func (s *LimitService) used(repo *repositories.TransactionRepository, userID uint, operation string, since time.Time) (models.Money, error) {
+	used := models.NewMoney(0, s.currency)
+
+	totals, err := repo.SumSince(userID, limitTransactionTypes[operation], since)
+	if err != nil {
+		return used, err
+	}
+
+	for _, total := range totals {
+		converted, err := s.toLimitCurrency(total.Currency, total.Total)
+		if err != nil {
+			return used, err
+		}
+		if used, err = used.Add(models.NewMoney(converted, s.currency)); err != nil {
+			return used, err
+		}
+	}
+	return used, nil
+}
+
+// toLimitCurrency converts minor units of a currency into minor units of the limit currency.
+// A missing rate fails the check rather than letting the amount through uncounted.
+//
+// toLimitCurrency bir para biriminin alt birimlerini limit para biriminin alt birimlerine çevirir.
+// Eksik kur, tutarı sayılmadan geçirmek yerine kontrolü başarısız kılar.
+func (s *LimitService) toLimitCurrency(currency string, amount int64) (int64, error) {
+	if currency == s.currency {
+		return amount, nil
+	}
+
+	from, err := models.LookupCurrency(currency)
+	if err != nil {
+		return 0, err
+	}
+	to, err := models.LookupCurrency(s.currency)
+	if err != nil {
+		return 0, err
+	}
+	rate, err := s.rateProvider.MidRate(from.Code, to.Code)
+	if err != nil {
+		return 0, err
+	}
+
+	return rates.Convert(amount, rate, from.MinorUnits, to.MinorUnits), nil
+}
+
+// fill sets the cap and remaining allowance of a window whose Used is already known
+// fill Used değeri bilinen bir pencerenin sınırını ve kalan hakkını ayarlar
+func (w *LimitWindow) fill(limit *models.Money, resetsAt time.Time) {
+	w.Limit = limit
+	w.ResetsAt = resetsAt
+	if limit != nil {
+		remaining := models.NewMoney(max(limit.Minor-w.Used.Minor, 0), limit.Currency)
+		w.Remaining = &remaining
+	}
+}
+
+// capOrNil turns "0 = no cap" into nil for JSON responses
+// capOrNil "0 = sınır yok" değerini JSON cevapları için nil yapar
+func (s *LimitService) capOrNil(limit int64) *models.Money {
+	if limit <= 0 {
+		return nil
+	}
+	money := models.NewMoney(limit, s.currency)
+	return &money
+}
+
+// startOfDay and startOfMonth are the UTC boundaries where daily and monthly allowances reset
+// startOfDay ve startOfMonth günlük ve aylık hakların sıfırlandığı UTC sınırlarıdır
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,455 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// Merchant text limits keep names and descriptions short enough for checkout pages and statements
+// İşletme metin sınırları isim ve açıklamaları ödeme sayfaları ve hesap özetleri için kısa tutar
+const (
+	maxBusinessNameLength = 80
+	maxLinkDescLength     = 140
+)
+
+var (
+	// ErrNotMerchant is returned when the user has not registered a merchant account
+	// ErrNotMerchant kullanıcı bir işletme hesabı açmamışsa döner
+	ErrNotMerchant = errors.New("you have no merchant account")
+
+	// ErrMerchantExists is returned when the user registers a second merchant account
+	// ErrMerchantExists kullanıcı ikinci bir işletme hesabı açmaya çalışırsa döner
+	ErrMerchantExists = errors.New("you already have a merchant account")
+)
+
+// MerchantProfile is the business profile a merchant registers or updates
+// MerchantProfile bir işletmenin kaydettiği veya güncellediği işletme profilidir
+type MerchantProfile struct {
+	BusinessName       string
+	Website            string
+	SupportEmail       string
+	SettlementCurrency string
+}
+
+// PaymentLinkView is a payment link with the payments made through it
+// PaymentLinkView bir ödeme bağlantısı ve onun üzerinden yapılan ödemelerdir
+type PaymentLinkView struct {
+	Link     *models.PaymentLink  `json:"link"`
+	Payments []models.LinkPayment `json:"payments"`
+}
+
+// Checkout is what a payer sees before paying a link: the link and who gets the money
+// Checkout ödeyenin bir bağlantıyı ödemeden önce gördüğüdür: bağlantı ve parayı kimin alacağı
+type Checkout struct {
+	Link         *models.PaymentLink `json:"link"`
+	BusinessName string              `json:"business_name"`
+	Website      string              `json:"website,omitempty"`
+	SupportEmail string              `json:"support_email,omitempty"`
+}
+
+// LinkPaymentResult is a completed link payment with the transfer fee the payer paid
+// LinkPaymentResult ödeyenin ödediği transfer ücretiyle birlikte tamamlanmış bir bağlantı ödemesidir
+type LinkPaymentResult struct {
+	Payment *models.LinkPayment `json:"payment"`
+	Link    *models.PaymentLink `json:"link"`
+	Fee     *Fee                `json:"fee"`
+}
+
+// MerchantService runs merchant accounts and their hosted payment links.
+// Paying a link is a regular transfer from the payer to the merchant's user,
+// committed together with the link's payment count, so a single-use link is paid once.
+//
+// MerchantService işletme hesaplarını ve barındırılan ödeme bağlantılarını yürütür.
+// Bir bağlantıyı ödemek, ödeyenden işletmenin kullanıcısına bağlantının ödeme sayısıyla
+// birlikte commit edilen sıradan bir transferdir; böylece tek kullanımlık bağlantı bir kez ödenir.
+type MerchantService struct {
+	uow             *repositories.UnitOfWork
+	merchantRepo    *repositories.MerchantRepository
+	walletService   *WalletService
+	defaultCurrency string
+	log             logger.Logger
+}
+
+// Constructor for MerchantService
+// MerchantService için constructor
+func NewMerchantService(
+	uow *repositories.UnitOfWork,
+	merchantRepo *repositories.MerchantRepository,
+	walletService *WalletService,
+	defaultCurrency string,
+	log logger.Logger,
+) *MerchantService {
+	return &MerchantService{
+		uow:             uow,
+		merchantRepo:    merchantRepo,
+		walletService:   walletService,
+		defaultCurrency: defaultCurrency,
+		log:             log,
+	}
+}
+
+// Register turns the user into a merchant and opens the settlement wallet
+// Register kullanıcıyı bir işletmeye çevirir ve tahsilat cüzdanını açar
+func (s *MerchantService) Register(userID uint, profile MerchantProfile) (*models.Merchant, error) {
+	merchant := &models.Merchant{UserID: userID}
+	if err := s.applyProfile(merchant, profile); err != nil {
+		return nil, err
+	}
+
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		if _, err := repos.Merchants.FindByUser(userID); err == nil {
+			return ErrMerchantExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if _, err := repos.Wallets.FindOrCreate(userID, merchant.SettlementCurrency); err != nil {
+			return err
+		}
+		return repos.Merchants.Save(merchant)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Merchant registered", map[string]interface{}{
+		"merchant_id": merchant.ID,
+		"user_id":     userID,
+		"currency":    merchant.SettlementCurrency,
+	})
+
+	return merchant, nil
+}
+
+// Get returns the user's merchant account
+// Get kullanıcının işletme hesabını döndürür
+func (s *MerchantService) Get(userID uint) (*models.Merchant, error) {
+	return s.merchant(s.merchantRepo, userID)
+}
+
+// Update replaces the business profile; a new settlement currency opens its wallet
+// Update işletme profilini değiştirir; yeni bir tahsilat para birimi kendi cüzdanını açar
+func (s *MerchantService) Update(userID uint, profile MerchantProfile) (*models.Merchant, error) {
+	var merchant *models.Merchant
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		merchant, err = s.merchant(repos.Merchants, userID)
+		if err != nil {
+			return err
+		}
+		if err := s.applyProfile(merchant, profile); err != nil {
+			return err
+		}
+
+		if _, err := repos.Wallets.FindOrCreate(userID, merchant.SettlementCurrency); err != nil {
+			return err
+		}
+		return repos.Merchants.Save(merchant)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merchant, nil
+}
+
+// CreateLink creates a payment link for a fixed amount. The currency defaults to the
+// settlement currency; ttl zero means the link never expires.
+//
+// CreateLink sabit bir tutar için bir ödeme bağlantısı oluşturur. Para birimi varsayılan
+// olarak tahsilat para birimidir; ttl sıfırsa bağlantının süresi hiç dolmaz.
+func (s *MerchantService) CreateLink(userID uint, currency string, value models.Decimal, description string, ttl time.Duration, multiUse bool) (*models.PaymentLink, error) {
+
+	if value.IsZero() {
+		return nil, errors.New("invalid payment link amount")
+	}
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil, errors.New("payment link description is required")
+	}
+	if len(description) > maxLinkDescLength {
+		return nil, fmt.Errorf("payment link description must be at most %d characters", maxLinkDescLength)
+	}
+
+	merchant, err := s.merchant(s.merchantRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, amount, err := resolveAmount(currency, merchant.SettlementCurrency, value)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.PaymentLink{
+		MerchantID:  merchant.ID,
+		Code:        models.NewLinkCode(),
+		Amount:      amount,
+		Currency:    currency,
+		Description: description,
+		MultiUse:    multiUse,
+		Status:      models.PaymentLinkActive,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		link.ExpiresAt = &expiresAt
+	}
+	if err := s.merchantRepo.CreateLink(link); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Payment link created", map[string]interface{}{
+		"link_id":     link.ID,
+		"merchant_id": merchant.ID,
+		"amount":      amount,
+		"multi_use":   multiUse,
+	})
+
+	return link, nil
+}
+
+// ListLinks returns the merchant's links, optionally only those with one status
+// ListLinks işletmenin bağlantılarını, isteğe bağlı olarak yalnızca tek bir durumdakileri döndürür
+func (s *MerchantService) ListLinks(userID uint, status string) ([]models.PaymentLink, error) {
+	switch status {
+	case "", models.PaymentLinkActive, models.PaymentLinkCompleted, models.PaymentLinkCancelled, models.PaymentLinkExpired:
+	default:
+		return nil, fmt.Errorf("unknown payment link status %q", status)
+	}
+
+	merchant, err := s.merchant(s.merchantRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.merchantRepo.FindLinks(merchant.ID, status)
+}
+
+// GetLink returns one of the merchant's links with its payments
+// GetLink işletmenin bağlantılarından birini ödemeleriyle birlikte döndürür
+func (s *MerchantService) GetLink(userID, linkID uint) (*PaymentLinkView, error) {
+	merchant, err := s.merchant(s.merchantRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.merchantRepo.FindLink(merchant.ID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.merchantRepo.FindPayments(link.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentLinkView{Link: link, Payments: payments}, nil
+}
+
+// CancelLink stops an active link from taking more payments; past payments stay
+// CancelLink aktif bir bağlantının daha fazla ödeme almasını durdurur; geçmiş ödemeler kalır
+func (s *MerchantService) CancelLink(userID, linkID uint) (*models.PaymentLink, error) {
+	merchant, err := s.merchant(s.merchantRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.merchantRepo.FindLink(merchant.ID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.merchantRepo.Cancel(link); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Payment link cancelled", map[string]interface{}{
+		"link_id":     link.ID,
+		"merchant_id": merchant.ID,
+	})
+
+	return link, nil
+}
+
+// Checkout returns the public view of a link by its code
+// Checkout bir bağlantının herkese açık görünümünü koduyla döndürür
+func (s *MerchantService) Checkout(code string) (*Checkout, error) {
+	link, err := s.merchantRepo.FindLinkByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	merchant, err := s.merchantRepo.FindByID(link.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A link past its expiry is shown as expired before the sweep gets to it
+	// Süresi geçmiş bir bağlantı, tarama ona ulaşmadan da expired olarak gösterilir
+	if link.Status == models.PaymentLinkActive && link.ExpiresAt != nil && !time.Now().Before(*link.ExpiresAt) {
+		link.Status = models.PaymentLinkExpired
+	}
+
+	return &Checkout{
+		Link:         link,
+		BusinessName: merchant.BusinessName,
+		Website:      merchant.Website,
+		SupportEmail: merchant.SupportEmail,
+	}, nil
+}
+
+// Pay completes a link: the payer sends the link's amount to the merchant. The transfer
+// and the link's payment count commit together, so a single-use link is paid only once
+// and never counted without the money moving.
+//
+// Pay bir bağlantıyı tamamlar: ödeyen bağlantının tutarını işletmeye gönderir. Transfer ve
+// bağlantının ödeme sayısı birlikte commit edilir, böylece tek kullanımlık bağlantı yalnızca
+// bir kez ödenir ve para taşınmadan asla sayılmaz.
+func (s *MerchantService) Pay(payerID uint, code string) (*LinkPaymentResult, error) {
+
+	var result *LinkPaymentResult
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			link, err := repos.Merchants.FindLinkByCode(code)
+			if err != nil {
+				return err
+			}
+			if link.Status != models.PaymentLinkActive {
+				return repositories.ErrLinkNotPayable
+			}
+			if link.ExpiresAt != nil && !time.Now().Before(*link.ExpiresAt) {
+				return errors.New("payment link has expired")
+			}
+
+			merchant, err := repos.Merchants.FindByID(link.MerchantID)
+			if err != nil {
+				return err
+			}
+			if merchant.UserID == payerID {
+				return errors.New("cannot pay your own payment link")
+			}
+
+			if _, err := repos.Wallets.FindByUserAndCurrency(payerID, link.Currency); errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("you have no %s wallet to pay from", link.Currency)
+			}
+
+			sent, fee, err := s.walletService.transfer(repos, payerID, merchant.UserID, link.Currency, link.Amount, models.TransferPurposePaymentLink)
+			if err != nil {
+				return err
+			}
+
+			payment := &models.LinkPayment{
+				LinkID:        link.ID,
+				MerchantID:    merchant.ID,
+				PayerID:       payerID,
+				Amount:        link.Amount,
+				Currency:      link.Currency,
+				TransactionID: sent.ID,
+			}
+			if err := repos.Merchants.RecordPayment(link, payment); err != nil {
+				return err
+			}
+
+			result = &LinkPaymentResult{Payment: payment, Link: link, Fee: fee}
+			return nil
+		})
+	})
+	if err != nil {
+		s.log.Error("Payment link payment failed", map[string]interface{}{
+			"code":     code,
+			"payer_id": payerID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Payment link paid", map[string]interface{}{
+		"link_id":        result.Link.ID,
+		"payer_id":       payerID,
+		"transaction_id": result.Payment.TransactionID,
+	})
+
+	return result, nil
+}
+
+// ExpireDue marks active links past their expiry as expired
+// ExpireDue süresi geçmiş aktif bağlantıları expired olarak işaretler
+func (s *MerchantService) ExpireDue() (int64, error) {
+	expired, err := s.merchantRepo.ExpireDue(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if expired > 0 {
+		s.log.Info("Payment links expired", map[string]interface{}{
+			"count": expired,
+		})
+	}
+
+	return expired, nil
+}
+
+// RunExpiry calls ExpireDue on every tick; it blocks, so start it in a goroutine
+// RunExpiry her tikte ExpireDue çağırır; bloklar, bu yüzden goroutine içinde başlatılmalıdır
+func (s *MerchantService) RunExpiry(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, _ = s.ExpireDue()
+	}
+}
+
+// merchant loads the user's merchant account
+// merchant kullanıcının işletme hesabını yükler
+func (s *MerchantService) merchant(merchants *repositories.MerchantRepository, userID uint) (*models.Merchant, error) {
+	merchant, err := merchants.FindByUser(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotMerchant
+	}
+	return merchant, err
+}
+
+// applyProfile validates a business profile and copies it onto the merchant
+// applyProfile bir işletme profilini doğrular ve işletmeye kopyalar
+func (s *MerchantService) applyProfile(merchant *models.Merchant, profile MerchantProfile) error {
+	name := strings.TrimSpace(profile.BusinessName)
+	if name == "" {
+		return errors.New("business name is required")
+	}
+	if len(name) > maxBusinessNameLength {
+		return fmt.Errorf("business name must be at most %d characters", maxBusinessNameLength)
+	}
+
+	website := strings.TrimSpace(profile.Website)
+	if website != "" {
+		u, err := url.Parse(website)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("website must be an http or https URL")
+		}
+	}
+
+	email := strings.TrimSpace(profile.SupportEmail)
+	if email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return errors.New("invalid support email")
+		}
+	}
+
+	currency, err := resolveCurrency(profile.SettlementCurrency, s.defaultCurrency)
+	if err != nil {
+		return err
+	}
+
+	merchant.BusinessName = name
+	merchant.Website = website
+	merchant.SupportEmail = email
+	merchant.SettlementCurrency = currency
+	return nil
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// MoneyRequestService lets users request money from each other
+// MoneyRequestService kullanıcıların birbirinden para istemesini sağlar
+type MoneyRequestService struct {
+	uow             *repositories.UnitOfWork
+	requestRepo     *repositories.MoneyRequestRepository
+	userRepo        *repositories.UserRepository
+	walletService   *WalletService
+	defaultCurrency string
+	ttl             time.Duration
+	log             logger.Logger
+}
+
+// Constructor for MoneyRequestService; ttl is used when the requester gives no expiry
+// MoneyRequestService için constructor; isteyen süre vermezse ttl kullanılır
+func NewMoneyRequestService(
+	uow *repositories.UnitOfWork,
+	requestRepo *repositories.MoneyRequestRepository,
+	userRepo *repositories.UserRepository,
+	walletService *WalletService,
+	defaultCurrency string,
+	ttl time.Duration,
+	log logger.Logger,
+) *MoneyRequestService {
+	return &MoneyRequestService{
+		uow:             uow,
+		requestRepo:     requestRepo,
+		userRepo:        userRepo,
+		walletService:   walletService,
+		defaultCurrency: defaultCurrency,
+		ttl:             ttl,
+		log:             log,
+	}
+}
+
+// Create asks the payer to send an amount to the requester
+// Create ödeyenden isteyene bir tutar göndermesini ister
+func (s *MoneyRequestService) Create(requesterID, payerID uint, currency string, value models.Decimal, note string, ttl time.Duration) (*models.MoneyRequest, error) {
+
+	if requesterID == payerID {
+		return nil, errors.New("cannot request money from self")
+	}
+	if value.IsZero() {
+		return nil, errors.New("invalid request amount")
+	}
+	if ttl <= 0 {
+		ttl = s.ttl
+	}
+
+	currency, amount, err := resolveAmount(currency, s.defaultCurrency, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.FindByID(payerID); err != nil {
+		return nil, errors.New("payer not found")
+	}
+
+	request := &models.MoneyRequest{
+		RequesterID: requesterID,
+		PayerID:     payerID,
+		Amount:      amount,
+		Currency:    currency,
+		Note:        note,
+		Status:      models.MoneyRequestPending,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	if err := s.requestRepo.Create(request); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Money request created", map[string]interface{}{
+		"request_id":   request.ID,
+		"requester_id": requesterID,
+		"payer_id":     payerID,
+		"amount":       amount,
+	})
+
+	return request, nil
+}
+
+// ListIncoming returns requests the user is asked to pay
+// ListIncoming kullanıcıdan ödemesi istenen istekleri döndürür
+func (s *MoneyRequestService) ListIncoming(userID uint) ([]models.MoneyRequest, error) {
+	return s.requestRepo.FindIncoming(userID)
+}
+
+// ListOutgoing returns requests the user sent
+// ListOutgoing kullanıcının gönderdiği istekleri döndürür
+func (s *MoneyRequestService) ListOutgoing(userID uint) ([]models.MoneyRequest, error) {
+	return s.requestRepo.FindOutgoing(userID)
+}
+
+// Accept pays a pending request. The transfer and the status change commit together,
+// so a request is never paid twice and never marked paid without the money moving.
+//
+// Accept bekleyen bir isteği öder. Transfer ve durum değişikliği birlikte commit edilir,
+// böylece istek asla iki kez ödenmez ve para taşınmadan ödendi olarak işaretlenmez.
+func (s *MoneyRequestService) Accept(payerID, requestID uint) (*models.MoneyRequest, error) {
+
+	var request *models.MoneyRequest
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			var err error
+			request, err = s.pending(repos, requestID, payerID)
+			if err != nil {
+				return err
+			}
+			if request.PayerID != payerID {
+				return errors.New("only the payer can accept a request")
+			}
+
+			sent, _, err := s.walletService.transfer(repos, payerID, request.RequesterID, request.Currency, request.Amount, models.TransferPurposeMoneyRequest)
+			if err != nil {
+				return err
+			}
+
+			return repos.Requests.Respond(request, models.MoneyRequestPaid, &sent.ID)
+		})
+	})
+	if err != nil {
+		s.log.Error("Money request payment failed", map[string]interface{}{
+			"request_id": requestID,
+			"payer_id":   payerID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Money request paid", map[string]interface{}{
+		"request_id":     request.ID,
+		"transaction_id": *request.TransactionID,
+	})
+
+	return request, nil
+}
+
+// Decline lets the payer refuse a pending request
+// Decline ödeyenin bekleyen bir isteği reddetmesini sağlar
+func (s *MoneyRequestService) Decline(payerID, requestID uint) (*models.MoneyRequest, error) {
+	return s.close(requestID, payerID, models.MoneyRequestDeclined)
+}
+
+// Cancel lets the requester withdraw a pending request
+// Cancel isteyenin bekleyen bir isteği geri çekmesini sağlar
+func (s *MoneyRequestService) Cancel(requesterID, requestID uint) (*models.MoneyRequest, error) {
+	return s.close(requestID, requesterID, models.MoneyRequestCancelled)
+}
+
+// ExpireDue marks pending requests past their expiry as expired
+// ExpireDue süresi geçmiş bekleyen istekleri expired olarak işaretler
+func (s *MoneyRequestService) ExpireDue() (int64, error) {
+	expired, err := s.requestRepo.ExpireDue(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if expired > 0 {
+		s.log.Info("Money requests expired", map[string]interface{}{
+			"count": expired,
+		})
+	}
+
+	return expired, nil
+}
+
+// RunExpiry calls ExpireDue on every tick; it blocks, so start it in a goroutine
+// RunExpiry her tikte ExpireDue çağırır; bloklar, bu yüzden goroutine içinde başlatılmalıdır
+func (s *MoneyRequestService) RunExpiry(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, _ = s.ExpireDue()
+	}
+}
+
+// close moves a pending request to a final status without moving money.
+// Declining is up to the payer, cancelling up to the requester.
+//
+// close bekleyen isteği para taşımadan son durumuna taşır.
+// Reddetmek ödeyene, iptal etmek isteyene aittir.
+func (s *MoneyRequestService) close(requestID, userID uint, status string) (*models.MoneyRequest, error) {
+	var request *models.MoneyRequest
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		request, err = s.pending(repos, requestID, userID)
+		if err != nil {
+			return err
+		}
+		if status == models.MoneyRequestCancelled && request.RequesterID != userID {
+			return errors.New("only the requester can cancel a request")
+		}
+		if status == models.MoneyRequestDeclined && request.PayerID != userID {
+			return errors.New("only the payer can decline a request")
+		}
+
+		return repos.Requests.Respond(request, status, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Money request closed", map[string]interface{}{
+		"request_id": request.ID,
+		"status":     status,
+	})
+
+	return request, nil
+}
+
+// pending loads a request the user takes part in and checks it can still be answered
+// pending kullanıcının taraf olduğu isteği yükler ve hâlâ cevaplanabilir olduğunu kontrol eder
+func (s *MoneyRequestService) pending(repos *repositories.Repositories, requestID, userID uint) (*models.MoneyRequest, error) {
+	request, err := repos.Requests.FindByID(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests of other users are reported as not found
+	// Başka kullanıcıların istekleri bulunamadı olarak raporlanır
+	if request.PayerID != userID && request.RequesterID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if request.Status != models.MoneyRequestPending {
+		return nil, repositories.ErrRequestNotPending
+	}
+	if !time.Now().Before(request.ExpiresAt) {
+		return nil, errors.New("money request has expired")
+	}
+
+	return request, nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+)
+
+// ErrPayeeNotFound is the one answer for every recipient that cannot be resolved, so callers
+// cannot tell a malformed identifier, an unknown user or an unverified phone apart
+//
+// ErrPayeeNotFound çözümlenemeyen her alıcı için tek cevaptır, böylece çağıranlar hatalı bir
+// tanımlayıcıyı, bilinmeyen bir kullanıcıyı veya doğrulanmamış bir telefonu ayırt edemez
+var ErrPayeeNotFound = errors.New("no user matches this email, phone or handle")
+
+// PayeeService turns what a sender knows about the recipient (email, phone or @handle)
+// into a user, so clients never need database IDs
+//
+// PayeeService göndericinin alıcı hakkında bildiğini (e-posta, telefon veya @handle)
+// kullanıcıya çevirir, böylece istemcilerin veritabanı ID'lerine ihtiyacı olmaz
+type PayeeService struct {
+	userRepo *repositories.UserRepository
+	log      logger.Logger
+}
+
+func NewPayeeService(userRepo *repositories.UserRepository, log logger.Logger) *PayeeService {
+	return &PayeeService{userRepo: userRepo, log: log}
+}
+
+// Resolve finds the user behind an identifier:
+// "@name" is a handle, anything else with "@" an email, a number a verified phone, the rest a handle.
+//
+// Resolve bir tanımlayıcının arkasındaki kullanıcıyı bulur:
+// "@ad" handle, "@" içeren diğer her şey e-posta, numara doğrulanmış telefon, geri kalanı handle'dır.
+func (s *PayeeService) Resolve(identifier string) (*models.User, error) {
+	identifier = strings.TrimSpace(identifier)
+
+	var user *models.User
+	var err error
+	switch {
+	case strings.HasPrefix(identifier, "@"):
+		user, err = s.findByHandle(identifier)
+	case strings.Contains(identifier, "@"):
+		user, err = s.userRepo.FindByEmail(identifier)
+	default:
+		if phone, phoneErr := normalizePhone(identifier); phoneErr == nil {
+			user, err = s.userRepo.FindByPhone(phone)
+		} else {
+			user, err = s.findByHandle(identifier)
+		}
+	}
+
+	// Every miss looks the same to the caller; the log keeps the real reason
+	// Her başarısızlık çağırana aynı görünür; gerçek neden logda kalır
+	if err != nil {
+		s.log.Info("Payee not resolved", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, ErrPayeeNotFound
+	}
+
+	return user, nil
+}
+
+// Lookup returns the masked name a sender confirms before sending money
+// Lookup göndericinin para göndermeden önce onayladığı maskelenmiş adı döndürür
+func (s *PayeeService) Lookup(identifier string) (*Counterparty, error) {
+	user, err := s.Resolve(identifier)
+	if err != nil {
+		return nil, err
+	}
+	return &Counterparty{DisplayName: user.DisplayName()}, nil
+}
+
+func (s *PayeeService) findByHandle(identifier string) (*models.User, error) {
+	handle, err := normalizeHandle(identifier)
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepo.FindByHandle(handle)
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// maxPotNameLength keeps pot names short enough for lists and statements
+// maxPotNameLength kumbara isimlerini listeler ve hesap özetleri için kısa tutar
+const maxPotNameLength = 64
+
+// PotInput carries the fields a user sets when opening a pot
+// PotInput kullanıcının kumbara açarken belirlediği alanları taşır
+type PotInput struct {
+	Name         string          `json:"name"`
+	Currency     string          `json:"currency"`
+	TargetAmount *models.Decimal `json:"target_amount"`
+	TargetDate   *time.Time      `json:"target_date"`
+}
+
+// PotService sets money aside in savings pots. Moving money in or out is an internal
+// transfer: the wallet balance changes, a pot_in or pot_out row goes into history and
+// the ledger moves the money between the wallet and pot accounts.
+//
+// PotService parayı birikim kumbaralarına ayırır. Para girişi veya çıkışı dahili bir
+// transferdir: cüzdan bakiyesi değişir, geçmişe pot_in veya pot_out satırı yazılır ve
+// defter parayı cüzdan ile kumbara hesapları arasında taşır.
+type PotService struct {
+	uow                *repositories.UnitOfWork
+	potRepo            *repositories.PotRepository
+	ledgerService      *LedgerService
+	transactionService *TransactionService
+	defaultCurrency    string
+	log                logger.Logger
+}
+
+func NewPotService(
+	uow *repositories.UnitOfWork,
+	potRepo *repositories.PotRepository,
+	ledgerService *LedgerService,
+	transactionService *TransactionService,
+	defaultCurrency string,
+	log logger.Logger,
+) *PotService {
+	return &PotService{
+		uow:                uow,
+		potRepo:            potRepo,
+		ledgerService:      ledgerService,
+		transactionService: transactionService,
+		defaultCurrency:    defaultCurrency,
+		log:                log,
+	}
+}
+
+// Create opens an empty pot under the user's wallet in the given currency
+// Create kullanıcının verilen para birimindeki cüzdanının altında boş bir kumbara açar
+func (s *PotService) Create(userID uint, input PotInput) (*models.Pot, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("pot name is required")
+	}
+	if len(name) > maxPotNameLength {
+		return nil, fmt.Errorf("pot name must be at most %d characters", maxPotNameLength)
+	}
+
+	currency, err := resolveCurrency(input.Currency, s.defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	pot := &models.Pot{
+		UserID:     userID,
+		Currency:   currency,
+		Name:       name,
+		TargetDate: input.TargetDate,
+		Status:     models.PotStatusOpen,
+	}
+	if input.TargetAmount != nil {
+		_, target, err := resolveAmount(currency, s.defaultCurrency, *input.TargetAmount)
+		if err != nil {
+			return nil, err
+		}
+		if target <= 0 {
+			return nil, errors.New("invalid target amount")
+		}
+		pot.TargetAmount = &target
+	}
+	if input.TargetDate != nil && !input.TargetDate.After(time.Now()) {
+		return nil, errors.New("target_date must be in the future")
+	}
+
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		// Pots live under a wallet, so the wallet must exist
+		// Kumbaralar bir cüzdanın altında yaşar, bu yüzden cüzdan var olmalıdır
+		if _, err := repos.Wallets.FindByUserAndCurrency(userID, currency); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("no %s wallet to open the pot under", currency)
+			}
+			return err
+		}
+		return repos.Pots.Create(pot)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Pot created", map[string]interface{}{
+		"pot_id":   pot.ID,
+		"user_id":  userID,
+		"currency": currency,
+	})
+
+	return pot, nil
+}
+
+// Get returns one of the user's pots
+// Get kullanıcının kumbaralarından birini döndürür
+func (s *PotService) Get(userID, potID uint) (*models.Pot, error) {
+	pot, err := s.potRepo.FindByID(potID)
+	if err != nil {
+		return nil, err
+	}
+	if pot.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return pot, nil
+}
+
+// List returns all pots of the user, open ones first
+// List kullanıcının tüm kumbaralarını döndürür, önce açık olanlar
+func (s *PotService) List(userID uint) ([]models.Pot, error) {
+	return s.potRepo.FindByUser(userID)
+}
+
+// Totals returns how much the user keeps in open pots, per currency
+// Totals kullanıcının açık kumbaralarda ne kadar tuttuğunu para birimi bazında döndürür
+func (s *PotService) Totals(userID uint) (map[string]int64, error) {
+	totals, err := s.potRepo.TotalsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byCurrency := make(map[string]int64, len(totals))
+	for _, total := range totals {
+		byCurrency[total.Currency] = total.Total
+	}
+	return byCurrency, nil
+}
+
+// MoveIn sets money from the wallet aside in the pot; held money cannot be moved
+// MoveIn cüzdandaki parayı kumbaraya ayırır; provizyondaki para taşınamaz
+func (s *PotService) MoveIn(userID, potID uint, value models.Decimal) (*models.Pot, error) {
+	return s.move(userID, potID, value, models.TransactionTypePotIn)
+}
+
+// MoveOut brings money from the pot back into the wallet
+// MoveOut kumbaradaki parayı cüzdana geri getirir
+func (s *PotService) MoveOut(userID, potID uint, value models.Decimal) (*models.Pot, error) {
+	return s.move(userID, potID, value, models.TransactionTypePotOut)
+}
+
+// Close sweeps whatever is left in the pot back into the wallet and closes it
+// Close kumbarada kalanı cüzdana geri aktarır ve kumbarayı kapatır
+func (s *PotService) Close(userID, potID uint) (*models.Pot, error) {
+
+	var pot *models.Pot
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			var err error
+			pot, err = s.load(repos, userID, potID)
+			if err != nil {
+				return err
+			}
+
+			if pot.Balance > 0 {
+				if err := s.transfer(repos, pot, pot.Balance, models.TransactionTypePotOut); err != nil {
+					return err
+				}
+			}
+			return repos.Pots.Close(pot)
+		})
+	})
+	if err != nil {
+		s.log.Error("Closing pot failed", map[string]interface{}{
+			"pot_id":  potID,
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Pot closed", map[string]interface{}{
+		"pot_id":  pot.ID,
+		"user_id": userID,
+	})
+
+	return pot, nil
+}
+
+// move runs a pot_in or pot_out of a client amount in its own unit of work
+// move bir istemci tutarının pot_in veya pot_out işlemini kendi unit of work'ünde çalıştırır
+func (s *PotService) move(userID, potID uint, value models.Decimal, txType string) (*models.Pot, error) {
+
+	if value.IsZero() {
+		return nil, errors.New("invalid amount")
+	}
+
+	var pot *models.Pot
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			var err error
+			pot, err = s.load(repos, userID, potID)
+			if err != nil {
+				return err
+			}
+
+			currency, err := models.LookupCurrency(pot.Currency)
+			if err != nil {
+				return err
+			}
+			amount, err := value.Minor(currency)
+			if err != nil {
+				return err
+			}
+
+			return s.transfer(repos, pot, amount, txType)
+		})
+	})
+	if err != nil {
+		s.log.Error("Pot transfer failed", map[string]interface{}{
+			"pot_id":  potID,
+			"user_id": userID,
+			"type":    txType,
+		})
+		return nil, err
+	}
+
+	s.log.Info("Pot transfer successful", map[string]interface{}{
+		"pot_id":  pot.ID,
+		"user_id": userID,
+		"type":    txType,
+		"balance": pot.Balance,
+	})
+
+	return pot, nil
+}
+
+// load reads an open pot of the user inside the unit of work
+// load kullanıcının açık bir kumbarasını unit of work içinde okur
+func (s *PotService) load(repos *repositories.Repositories, userID, potID uint) (*models.Pot, error) {
+	pot, err := repos.Pots.FindByID(potID)
+	if err != nil {
+		return nil, err
+	}
+	if pot.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if pot.Status != models.PotStatusOpen {
+		return nil, repositories.ErrPotClosed
+	}
+	return pot, nil
+}
+
+// transfer moves minor units between the wallet and the pot inside the caller's unit of work
+// transfer çağıranın unit of work'ü içinde cüzdan ile kumbara arasında alt birim taşır
+func (s *PotService) transfer(repos *repositories.Repositories, pot *models.Pot, amount int64, txType string) error {
+	ledger := s.ledgerService.WithTx(repos)
+
+	wallet, err := repos.Wallets.FindByUserAndCurrency(pot.UserID, pot.Currency)
+	if err != nil {
+		return err
+	}
+
+	walletAccount, err := ledger.WalletAccount(wallet)
+	if err != nil {
+		return err
+	}
+	potAccount, err := ledger.PotAccount(pot)
+	if err != nil {
+		return err
+	}
+
+	// Signed change of the wallet; the pot changes by the opposite amount
+	// Cüzdanın işaretli değişimi; kumbara ters tutarda değişir
+	change := models.NewMoney(-amount, pot.Currency)
+	if txType == models.TransactionTypePotIn {
+		if wallet.Available() < amount {
+			return ErrInsufficientFunds
+		}
+	} else {
+		if pot.Balance < amount {
+			return errors.New("pot has insufficient funds")
+		}
+		change.Minor = amount
+	}
+
+	walletBalance, err := models.NewMoney(wallet.Balance, pot.Currency).Add(change)
+	if err != nil {
+		return err
+	}
+	potBalance, err := models.NewMoney(pot.Balance, pot.Currency).Sub(change)
+	if err != nil {
+		return err
+	}
+
+	if err := repos.Wallets.UpdateBalance(wallet, walletBalance.Minor); err != nil {
+		return err
+	}
+	if err := repos.Pots.UpdateBalance(pot, potBalance.Minor); err != nil {
+		return err
+	}
+
+	// POST LEDGER ENTRY: money moves between the wallet and pot accounts
+	// Defter kaydı: para cüzdan ve kumbara hesapları arasında taşınır
+	if err := ledger.Post(txType, fmt.Sprintf("%s user:%d pot:%d", txType, pot.UserID, pot.ID),
+		LedgerLine{Account: walletAccount, Amount: change.Minor},
+		LedgerLine{Account: potAccount, Amount: -change.Minor},
+	); err != nil {
+		return err
+	}
+
+	return s.transactionService.WithTx(repos).RecordEntry(&models.Transaction{
+		UserID:       pot.UserID,
+		Type:         txType,
+		Amount:       amount,
+		Currency:     pot.Currency,
+		BalanceAfter: wallet.Balance,
+		PotID:        &pot.ID,
+		Reason:       pot.Name,
+	})
+}
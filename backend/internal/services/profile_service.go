@@ -0,0 +1,228 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+	"mini-pay-backend/internal/sms"
+
+	"gorm.io/gorm"
+)
+
+// Phone verification limits
+// Telefon doğrulama sınırları
+const (
+	phoneCodeTTL         = 10 * time.Minute
+	phoneCodeMaxAttempts = 5
+)
+
+var (
+	handlePattern = regexp.MustCompile(`^[a-z0-9_]{3,30}$`)
+	phonePattern  = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+	// phoneSeparators are characters people type inside phone numbers
+	// phoneSeparators insanların telefon numaralarının içine yazdığı karakterlerdir
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+)
+
+// ErrHandleTaken is returned when another user already has the handle
+// ErrHandleTaken handle başka bir kullanıcıya aitse döner
+var ErrHandleTaken = errors.New("handle is already taken")
+
+// normalizeHandle lowercases a handle and strips the leading "@"
+// normalizeHandle handle'ı küçük harfe çevirir ve baştaki "@" işaretini kaldırır
+func normalizeHandle(handle string) (string, error) {
+	handle = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+	if !handlePattern.MatchString(handle) {
+		return "", errors.New("handle must be 3-30 letters, digits or underscores")
+	}
+	return handle, nil
+}
+
+// normalizePhone turns a typed phone number into E.164 (e.g. "+90 555 123-45-67" → "+905551234567")
+// normalizePhone yazılmış bir telefon numarasını E.164 formatına çevirir
+func normalizePhone(phone string) (string, error) {
+	phone = phoneSeparators.Replace(strings.TrimSpace(phone))
+	if strings.HasPrefix(phone, "00") {
+		phone = "+" + phone[2:]
+	}
+	if !phonePattern.MatchString(phone) {
+		return "", errors.New("phone must be in international format, e.g. +905551234567")
+	}
+	return phone, nil
+}
+
+// hashPhoneCode keeps verification codes out of the database in plain text
+// hashPhoneCode doğrulama kodlarının veritabanında düz metin olarak durmamasını sağlar
+func hashPhoneCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// ProfileService manages how a user can be found by others: handle and verified phone
+// ProfileService kullanıcının başkaları tarafından nasıl bulunacağını yönetir: handle ve doğrulanmış telefon
+type ProfileService struct {
+	uow      *repositories.UnitOfWork
+	userRepo *repositories.UserRepository
+	sender   sms.Sender
+	log      logger.Logger
+}
+
+func NewProfileService(uow *repositories.UnitOfWork, userRepo *repositories.UserRepository, sender sms.Sender, log logger.Logger) *ProfileService {
+	return &ProfileService{uow: uow, userRepo: userRepo, sender: sender, log: log}
+}
+
+// Get returns the user's own profile
+// Get kullanıcının kendi profilini döndürür
+func (s *ProfileService) Get(userID uint) (*models.User, error) {
+	return s.userRepo.FindByID(userID)
+}
+
+// SetHandle claims a unique handle for the user (replacing the old one)
+// SetHandle kullanıcı için benzersiz bir handle alır (eskisinin yerine)
+func (s *ProfileService) SetHandle(userID uint, handle string) (*models.User, error) {
+	handle, err := normalizeHandle(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.userRepo.FindByHandle(handle)
+	if err == nil && owner.ID != userID {
+		return nil, ErrHandleTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// The unique index still catches two users racing for the same handle
+	// Aynı handle için yarışan iki kullanıcıyı yine de unique index yakalar
+	user.Handle = &handle
+	if err := s.userRepo.Update(user, "handle"); err != nil {
+		return nil, ErrHandleTaken
+	}
+
+	s.log.Info("Handle set", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	return user, nil
+}
+
+// StartPhoneVerification texts a one-time code to the number. The number only
+// becomes the user's phone once the code is confirmed with VerifyPhone.
+//
+// StartPhoneVerification numaraya tek kullanımlık bir kod gönderir. Numara ancak
+// kod VerifyPhone ile onaylandığında kullanıcının telefonu olur.
+func (s *ProfileService) StartPhoneVerification(userID uint, phone string) error {
+	phone, err := normalizePhone(phone)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	digits, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return err
+	}
+	code := fmt.Sprintf("%06d", digits.Int64())
+	expiresAt := time.Now().Add(phoneCodeTTL)
+
+	user.PendingPhone = phone
+	user.PhoneCodeHash = hashPhoneCode(code)
+	user.PhoneCodeExpiresAt = &expiresAt
+	user.PhoneCodeAttempts = 0
+	if err := s.userRepo.Update(user, "pending_phone", "phone_code_hash", "phone_code_expires_at", "phone_code_attempts"); err != nil {
+		return err
+	}
+
+	if err := s.sender.Send(phone, fmt.Sprintf("Your Mini Pay verification code is %s", code)); err != nil {
+		s.log.Error("Verification SMS failed", map[string]interface{}{
+			"user_id": userID,
+		})
+		return errors.New("verification code could not be sent")
+	}
+
+	s.log.Info("Phone verification started", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	return nil
+}
+
+// VerifyPhone confirms the pending number with the code that was sent to it
+// VerifyPhone bekleyen numarayı ona gönderilen kod ile onaylar
+func (s *ProfileService) VerifyPhone(userID uint, code string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.PendingPhone == "" || user.PhoneCodeExpiresAt == nil || !time.Now().Before(*user.PhoneCodeExpiresAt) {
+		return nil, errors.New("no phone verification in progress or the code has expired")
+	}
+	if user.PhoneCodeAttempts >= phoneCodeMaxAttempts {
+		return nil, errors.New("too many attempts, request a new code")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashPhoneCode(strings.TrimSpace(code))), []byte(user.PhoneCodeHash)) != 1 {
+		user.PhoneCodeAttempts++
+		if err := s.userRepo.Update(user, "phone_code_attempts"); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("invalid verification code")
+	}
+
+	// A number verified by someone else moves to this user; whoever holds the SIM owns it.
+	// Both changes commit together so the number is never lost in between.
+	//
+	// Başkası tarafından doğrulanmış bir numara bu kullanıcıya geçer; SIM kimdeyse numara onundur.
+	// İki değişiklik birlikte commit edilir, böylece numara arada kaybolmaz.
+	phone := user.PendingPhone
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		previous, err := repos.Users.FindByPhone(phone)
+		if err == nil && previous.ID != userID {
+			previous.Phone = nil
+			if err := repos.Users.Update(previous, "phone"); err != nil {
+				return err
+			}
+		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		user.Phone = &phone
+		user.PendingPhone = ""
+		user.PhoneCodeHash = ""
+		user.PhoneCodeExpiresAt = nil
+		user.PhoneCodeAttempts = 0
+		return repos.Users.Update(user, "phone", "pending_phone", "phone_code_hash", "phone_code_expires_at", "phone_code_attempts")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Phone verified", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	return user, nil
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mini-pay-backend/internal/emvqr"
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+// QR code rendering and content defaults
+// QR kodu çizim ve içerik varsayılanları
+const (
+	qrImageSize = 256
+
+	// qrCategoryUnclassified is the merchant category code written into every QR code
+	// qrCategoryUnclassified her QR koduna yazılan işletme kategori kodudur
+	qrCategoryUnclassified = "0000"
+
+	maxQRNameLength = 25
+	maxQRCityLength = 15
+
+	// Dynamic merchant codes are single-use payment links with this description and lifetime
+	// Dinamik işletme kodları bu açıklama ve ömürle tek kullanımlık ödeme bağlantılarıdır
+	qrLinkDescription = "QR payment"
+	qrLinkTTL         = 15 * time.Minute
+)
+
+// QRCode is an EMVCo payload to show at the till, with its PNG rendering.
+// Link is the single-use payment link behind a dynamic merchant code.
+//
+// QRCode kasada gösterilecek bir EMVCo verisi ve onun PNG çizimidir.
+// Link dinamik bir işletme kodunun arkasındaki tek kullanımlık ödeme bağlantısıdır.
+type QRCode struct {
+	Payload  string              `json:"payload"`
+	Dynamic  bool                `json:"dynamic"`
+	Currency string              `json:"currency"`
+	Amount   *models.Money       `json:"amount,omitempty"`
+	Link     *models.PaymentLink `json:"link,omitempty"`
+	PNG      []byte              `json:"png"`
+}
+
+// ScannedQR is what a payer's app learns from a QR code before paying it
+// ScannedQR ödeyenin uygulamasının bir QR kodunu ödemeden önce ondan öğrendikleridir
+type ScannedQR struct {
+	AccountType string        `json:"account_type"`
+	Name        string        `json:"name"`
+	Dynamic     bool          `json:"dynamic"`
+	Currency    string        `json:"currency"`
+	Amount      *models.Money `json:"amount,omitempty"`
+
+	recipientID uint
+	linkCode    string
+}
+
+// QRPayment is a completed pay-by-QR transfer
+// QRPayment tamamlanmış bir QR ile ödeme transferidir
+type QRPayment struct {
+	Recipient string       `json:"recipient"`
+	Amount    models.Money `json:"amount"`
+	Fee       *Fee         `json:"fee"`
+}
+
+// QRService issues EMVCo merchant-presented QR codes for merchants and users and pays them.
+// A static code carries only the recipient; a dynamic one also fixes currency and amount.
+// Codes name the recipient by a random token, never by ID. A dynamic merchant code is a
+// single-use payment link, so it is paid once; a dynamic user code can be paid again, like
+// a static one with the amount filled in.
+//
+// QRService işletmeler ve kullanıcılar için EMVCo satıcı tarafından gösterilen QR kodları üretir
+// ve onları öder. Statik kod yalnızca alıcıyı taşır; dinamik olan para birimi ve tutarı da sabitler.
+// Kodlar alıcıyı ID ile değil rastgele bir belirteçle adlandırır. Dinamik işletme kodu tek
+// kullanımlık bir ödeme bağlantısıdır, bu yüzden bir kez ödenir; dinamik kullanıcı kodu, tutarı
+// doldurulmuş statik bir kod gibi tekrar ödenebilir.
+type QRService struct {
+	merchantRepo    *repositories.MerchantRepository
+	userRepo        *repositories.UserRepository
+	merchantService *MerchantService
+	walletService   *WalletService
+	defaultCurrency string
+	countryCode     string
+	city            string
+	log             logger.Logger
+}
+
+// Constructor for QRService; countryCode and city are written into every code
+// QRService için constructor; countryCode ve city her koda yazılır
+func NewQRService(
+	merchantRepo *repositories.MerchantRepository,
+	userRepo *repositories.UserRepository,
+	merchantService *MerchantService,
+	walletService *WalletService,
+	defaultCurrency, countryCode, city string,
+	log logger.Logger,
+) *QRService {
+	return &QRService{
+		merchantRepo:    merchantRepo,
+		userRepo:        userRepo,
+		merchantService: merchantService,
+		walletService:   walletService,
+		defaultCurrency: defaultCurrency,
+		countryCode:     strings.ToUpper(countryCode),
+		city:            emvqr.FitText(city, maxQRCityLength),
+		log:             log,
+	}
+}
+
+// Generate builds a QR code paying the user, or their merchant account when account is
+// "merchant". Giving an amount makes a user code dynamic; otherwise the payer enters it.
+// It creates nothing, so a merchant code with an amount must come from Checkout.
+//
+// Generate kullanıcıya, account "merchant" ise işletme hesabına ödeme yapan bir QR kodu üretir.
+// Tutar verilirse kullanıcı kodu dinamik olur; verilmezse tutarı ödeyen girer.
+// Hiçbir şey oluşturmaz, bu yüzden tutarlı bir işletme kodu Checkout'tan gelmelidir.
+func (s *QRService) Generate(userID uint, account, currency string, value models.Decimal) (*QRCode, error) {
+	if account == emvqr.AccountMerchant && !value.IsZero() {
+		return nil, errors.New("a merchant code with an amount is single-use; create it as a checkout")
+	}
+	return s.generate(userID, account, currency, value)
+}
+
+// Checkout creates a merchant's dynamic code: a fresh single-use payment link for the amount
+// Checkout bir işletmenin dinamik kodunu oluşturur: tutar için yeni, tek kullanımlık bir ödeme bağlantısı
+func (s *QRService) Checkout(userID uint, currency string, value models.Decimal) (*QRCode, error) {
+	if value.IsZero() {
+		return nil, errors.New("amount is required for a checkout QR code")
+	}
+	return s.generate(userID, emvqr.AccountMerchant, currency, value)
+}
+
+// generate builds the payload for Generate and Checkout
+// generate Generate ve Checkout için veriyi oluşturur
+func (s *QRService) generate(userID uint, account, currency string, value models.Decimal) (*QRCode, error) {
+	payload := &emvqr.Payload{
+		MerchantCategoryCode: qrCategoryUnclassified,
+		CountryCode:          s.countryCode,
+		MerchantCity:         s.city,
+	}
+
+	var merchant *models.Merchant
+	defaultCurrency := s.defaultCurrency
+	switch account {
+	case "", emvqr.AccountUser:
+		user, err := s.userRepo.FindByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if user.QRToken == nil {
+			if err := s.userRepo.AssignQRToken(user, models.NewQRToken()); err != nil {
+				return nil, err
+			}
+		}
+		payload.AccountType = emvqr.AccountUser
+		payload.AccountRef = *user.QRToken
+		payload.MerchantName = qrName(user)
+	case emvqr.AccountMerchant:
+		var err error
+		merchant, err = s.merchantRepo.FindByUser(userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotMerchant
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload.AccountType = emvqr.AccountMerchant
+		payload.MerchantName = emvqr.FitText(merchant.BusinessName, maxQRNameLength)
+		defaultCurrency = merchant.SettlementCurrency
+	default:
+		return nil, errors.New("account must be user or merchant")
+	}
+	if payload.MerchantName == "" {
+		payload.MerchantName = "mini-pay"
+	}
+
+	code := &QRCode{}
+	if value.IsZero() {
+		resolved, err := resolveCurrency(currency, defaultCurrency)
+		if err != nil {
+			return nil, err
+		}
+		code.Currency = resolved
+	} else {
+		resolved, amount, err := resolveAmount(currency, defaultCurrency, value)
+		if err != nil {
+			return nil, err
+		}
+		money := models.NewMoney(amount, resolved)
+		code.Currency = resolved
+		code.Amount = &money
+		payload.Amount = money.String()
+	}
+
+	definition, err := models.LookupCurrency(code.Currency)
+	if err != nil {
+		return nil, err
+	}
+	payload.Currency = definition.Numeric
+
+	// A merchant's dynamic code is a till checkout: it points to a fresh single-use link
+	// Bir işletmenin dinamik kodu bir kasa ödemesidir: yeni, tek kullanımlık bir bağlantıyı gösterir
+	if merchant != nil && code.Amount != nil {
+		link, err := s.merchantService.CreateLink(userID, code.Currency, value, qrLinkDescription, qrLinkTTL, false)
+		if err != nil {
+			return nil, err
+		}
+		payload.AccountType = emvqr.AccountPaymentLink
+		payload.AccountRef = link.Code
+		code.Link = link
+	} else if merchant != nil {
+		if merchant.QRToken == nil {
+			if err := s.merchantRepo.AssignQRToken(merchant, models.NewQRToken()); err != nil {
+				return nil, err
+			}
+		}
+		payload.AccountRef = *merchant.QRToken
+	}
+
+	code.Payload, err = emvqr.Encode(payload)
+	if err != nil {
+		return nil, err
+	}
+	code.Dynamic = payload.Dynamic()
+	code.PNG, err = qrcode.Encode(code.Payload, qrcode.Medium, qrImageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return code, nil
+}
+
+// Scan validates a payload and looks up who it pays
+// Scan bir veriyi doğrular ve kime ödeme yaptığını bulur
+func (s *QRService) Scan(text string) (*ScannedQR, error) {
+	payload, err := emvqr.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := models.LookupCurrencyNumeric(payload.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	scanned := &ScannedQR{
+		AccountType: payload.AccountType,
+		Dynamic:     payload.Dynamic(),
+		Currency:    currency.Code,
+	}
+	if payload.Dynamic() {
+		value, err := models.ParseDecimal(payload.Amount)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := value.Minor(currency)
+		if err != nil {
+			return nil, err
+		}
+		if amount <= 0 {
+			return nil, errors.New("invalid QR amount")
+		}
+		money := models.NewMoney(amount, currency.Code)
+		scanned.Amount = &money
+	}
+
+	// The name comes from our records, not from the payload, so a forged name cannot mislead the payer
+	// İsim veriden değil kayıtlarımızdan gelir, böylece sahte bir isim ödeyeni yanıltamaz
+	switch payload.AccountType {
+	case emvqr.AccountPaymentLink:
+		link, err := s.merchantRepo.FindLinkByCode(payload.AccountRef)
+		if err != nil {
+			return nil, err
+		}
+		if link.Status != models.PaymentLinkActive || (link.ExpiresAt != nil && !time.Now().Before(*link.ExpiresAt)) {
+			return nil, repositories.ErrLinkNotPayable
+		}
+		if link.Currency != currency.Code || link.Amount != scanned.Amount.Minor {
+			return nil, fmt.Errorf("%w: amount does not match its payment link", emvqr.ErrInvalidPayload)
+		}
+		merchant, err := s.merchantRepo.FindByID(link.MerchantID)
+		if err != nil {
+			return nil, err
+		}
+		scanned.recipientID = merchant.UserID
+		scanned.linkCode = link.Code
+		scanned.Name = merchant.BusinessName
+	case emvqr.AccountMerchant:
+		merchant, err := s.merchantRepo.FindByQRToken(payload.AccountRef)
+		if err != nil {
+			return nil, err
+		}
+		scanned.recipientID = merchant.UserID
+		scanned.Name = merchant.BusinessName
+	default:
+		user, err := s.userRepo.FindByQRToken(payload.AccountRef)
+		if err != nil {
+			return nil, err
+		}
+		scanned.recipientID = user.ID
+		scanned.Name = qrName(user)
+	}
+
+	return scanned, nil
+}
+
+// Pay transfers to the recipient of a QR code. A dynamic code fixes the amount; a static
+// one needs the amount from the payer. Fees and limits are those of a transfer.
+//
+// Pay bir QR kodunun alıcısına transfer yapar. Dinamik kod tutarı sabitler; statik kod
+// tutarı ödeyenden ister. Ücret ve limitler bir transferinkilerdir.
+func (s *QRService) Pay(payerID uint, text string, value models.Decimal) (*QRPayment, error) {
+	scanned, err := s.Scan(text)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := models.LookupCurrency(scanned.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if scanned.Amount != nil {
+		if !value.IsZero() {
+			amount, err := value.Minor(currency)
+			if err != nil {
+				return nil, err
+			}
+			if amount != scanned.Amount.Minor {
+				return nil, errors.New("amount is fixed by this QR code")
+			}
+		}
+		value = models.DecimalOf(scanned.Amount.Minor, currency)
+	} else if value.IsZero() {
+		return nil, errors.New("amount is required for a static QR code")
+	}
+
+	amount, err := value.Minor(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// A link code is paid through its link, which completes it so the code cannot be paid twice
+	// Bağlantı kodu kendi bağlantısı üzerinden ödenir; bağlantı tamamlanır ve kod iki kez ödenemez
+	if scanned.linkCode != "" {
+		result, err := s.merchantService.Pay(payerID, scanned.linkCode)
+		if err != nil {
+			return nil, err
+		}
+		return &QRPayment{
+			Recipient: scanned.Name,
+			Amount:    models.NewMoney(result.Payment.Amount, result.Payment.Currency),
+			Fee:       result.Fee,
+		}, nil
+	}
+
+	// The recipient was found while scanning, so a missing record here is the payer's wallet.
+	// The payment paid for something at the till, so the payer cannot reverse it.
+	//
+	// Alıcı tarama sırasında bulundu, bu yüzden burada eksik kayıt ödeyenin cüzdanıdır.
+	// Ödeme kasada bir şeyin karşılığıdır, bu yüzden ödeyen onu geri alamaz.
+	fee, err := s.walletService.send(payerID, scanned.recipientID, currency.Code, amount, models.TransferPurposeQRPayment)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("you have no %s wallet to pay from", currency.Code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("QR payment completed", map[string]interface{}{
+		"payer_id":     payerID,
+		"account_type": scanned.AccountType,
+		"recipient_id": scanned.recipientID,
+		"amount":       amount,
+		"currency":     currency.Code,
+	})
+
+	return &QRPayment{
+		Recipient: scanned.Name,
+		Amount:    models.NewMoney(amount, currency.Code),
+		Fee:       fee,
+	}, nil
+}
+
+// qrName is how a user is named on a QR code: their handle, or the masked email
+// qrName bir kullanıcının QR kodundaki adıdır: handle'ı veya maskelenmiş e-postası
+func qrName(user *models.User) string {
+	if user.Handle != nil {
+		return emvqr.FitText("@"+*user.Handle, maxQRNameLength)
+	}
+	return emvqr.FitText(user.DisplayName(), maxQRNameLength)
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// Reconciliation issue kinds
+// Mutabakat sorun türleri
+const (
+	// IssueUnknownType is a history row whose effect on the balance is unknown
+	// IssueUnknownType bakiyeye etkisi bilinmeyen bir geçmiş satırıdır
+	IssueUnknownType = "unknown_type"
+
+	// IssueContinuityBreak is a row whose BalanceAfter does not follow from the previous row
+	// IssueContinuityBreak BalanceAfter değeri önceki satırdan çıkmayan bir satırdır
+	IssueContinuityBreak = "continuity_break"
+
+	// IssueBalanceMismatch means the wallet balance differs from the sum of its history
+	// IssueBalanceMismatch cüzdan bakiyesinin geçmiş toplamından farklı olduğunu gösterir
+	IssueBalanceMismatch = "balance_mismatch"
+
+	// IssueLedgerMismatch means the wallet balance differs from its ledger account
+	// IssueLedgerMismatch cüzdan bakiyesinin defter hesabından farklı olduğunu gösterir
+	IssueLedgerMismatch = "ledger_mismatch"
+)
+
+// ReconciliationIssue is a single finding on a wallet
+// ReconciliationIssue bir cüzdandaki tek bir bulgudur
+type ReconciliationIssue struct {
+	Kind          string       `json:"kind"`
+	TransactionID *uint        `json:"transaction_id,omitempty"`
+	Expected      models.Money `json:"expected"`
+	Actual        models.Money `json:"actual"`
+}
+
+// WalletDiscrepancy lists everything wrong with one wallet
+// WalletDiscrepancy bir cüzdandaki tüm sorunları listeler
+type WalletDiscrepancy struct {
+	WalletID        uint                  `json:"wallet_id"`
+	UserID          uint                  `json:"user_id"`
+	JointID         uint                  `json:"joint_id,omitempty"`
+	Currency        string                `json:"currency"`
+	Balance         models.Money          `json:"balance"`
+	ComputedBalance models.Money          `json:"computed_balance"`
+	LedgerBalance   *models.Money         `json:"ledger_balance,omitempty"`
+	Issues          []ReconciliationIssue `json:"issues"`
+	Frozen          bool                  `json:"frozen"`
+}
+
+// ReconciliationReport is the audit record of one reconciliation run
+// ReconciliationReport bir mutabakat çalıştırmasının denetim kaydıdır
+type ReconciliationReport struct {
+	StartedAt       time.Time           `json:"started_at"`
+	FinishedAt      time.Time           `json:"finished_at"`
+	WalletsChecked  int                 `json:"wallets_checked"`
+	FreezeRequested bool                `json:"freeze_requested"`
+	Discrepancies   []WalletDiscrepancy `json:"discrepancies"`
+}
+
+// ReconciliationService recomputes wallets from their history and reports drift
+// ReconciliationService cüzdanları geçmişlerinden yeniden hesaplar ve sapmaları raporlar
+type ReconciliationService struct {
+	uow        *repositories.UnitOfWork
+	walletRepo *repositories.WalletRepository
+	log        logger.Logger
+}
+
+func NewReconciliationService(uow *repositories.UnitOfWork, walletRepo *repositories.WalletRepository, log logger.Logger) *ReconciliationService {
+	return &ReconciliationService{uow: uow, walletRepo: walletRepo, log: log}
+}
+
+// Run checks every wallet; with freeze set, wallets with discrepancies are frozen
+// Run tüm cüzdanları kontrol eder; freeze verilirse sorunlu cüzdanlar dondurulur
+func (s *ReconciliationService) Run(freeze bool) (*ReconciliationReport, error) {
+	report := &ReconciliationReport{
+		StartedAt:       time.Now(),
+		FreezeRequested: freeze,
+		Discrepancies:   []WalletDiscrepancy{},
+	}
+
+	ids, err := s.walletRepo.FindAllIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		// Each wallet is read in its own transaction so balance and history are consistent
+		// Her cüzdan kendi transaction'ında okunur, böylece bakiye ve geçmiş tutarlıdır
+		var discrepancy *WalletDiscrepancy
+		err := retryOnConflict(s.log, func() error {
+			return s.uow.Do(func(repos *repositories.Repositories) error {
+				var err error
+				discrepancy, err = s.checkWallet(repos, id, freeze)
+				return err
+			})
+		})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		report.WalletsChecked++
+		if discrepancy != nil {
+			report.Discrepancies = append(report.Discrepancies, *discrepancy)
+		}
+	}
+
+	report.FinishedAt = time.Now()
+
+	if len(report.Discrepancies) > 0 {
+		s.log.Error("Reconciliation found discrepancies", map[string]interface{}{
+			"wallets_checked": report.WalletsChecked,
+			"discrepancies":   len(report.Discrepancies),
+		})
+	} else {
+		s.log.Info("Reconciliation clean", map[string]interface{}{
+			"wallets_checked": report.WalletsChecked,
+		})
+	}
+
+	return report, nil
+}
+
+// RunJob reconciles on every tick and appends each report as a JSON line to reportFile
+// (or only logs it when reportFile is empty); it blocks, so start it in a goroutine.
+//
+// RunJob her tikte mutabakat yapar ve her raporu reportFile dosyasına JSON satırı olarak
+// ekler (reportFile boşsa sadece loglar); bloklar, bu yüzden goroutine içinde başlatılmalıdır.
+func (s *ReconciliationService) RunJob(interval time.Duration, freeze bool, reportFile string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		report, err := s.Run(freeze)
+		if err != nil {
+			s.log.Error("Reconciliation run failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+			continue
+		}
+		if reportFile == "" {
+			continue
+		}
+		if err := AppendReport(reportFile, report); err != nil {
+			s.log.Error("Failed to write reconciliation report", map[string]interface{}{
+				"file":  reportFile,
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// Unfreeze lets a frozen wallet move money again
+// Unfreeze dondurulmuş bir cüzdanın tekrar para hareketi yapmasına izin verir
+func (s *ReconciliationService) Unfreeze(walletID uint) (*models.Wallet, error) {
+	var wallet *models.Wallet
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			var err error
+			wallet, err = repos.Wallets.FindByID(walletID)
+			if err != nil {
+				return err
+			}
+			if !wallet.Frozen {
+				return errors.New("wallet is not frozen")
+			}
+			return repos.Wallets.SetFrozen(wallet, false, "")
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Wallet unfrozen", map[string]interface{}{
+		"wallet_id": walletID,
+	})
+
+	return wallet, nil
+}
+
+// AppendReport writes the report as one JSON line at the end of the file
+// AppendReport raporu dosyanın sonuna tek bir JSON satırı olarak yazar
+func AppendReport(path string, report *ReconciliationReport) error {
+	line, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// checkWallet replays the wallet's history and compares it with the stored balances.
+// It returns nil when nothing is wrong.
+//
+// checkWallet cüzdanın geçmişini yeniden oynatır ve kayıtlı bakiyelerle karşılaştırır.
+// Sorun yoksa nil döner.
+func (s *ReconciliationService) checkWallet(repos *repositories.Repositories, walletID uint, freeze bool) (*WalletDiscrepancy, error) {
+	wallet, err := repos.Wallets.FindByID(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := repos.Transactions.FindByWallet(repositories.ChainOf(wallet))
+	if err != nil {
+		return nil, err
+	}
+
+	discrepancy := &WalletDiscrepancy{
+		WalletID: wallet.ID,
+		UserID:   wallet.UserID,
+		JointID:  wallet.JointID,
+		Currency: wallet.Currency,
+		Balance:  models.NewMoney(wallet.Balance, wallet.Currency),
+		Issues:   []ReconciliationIssue{},
+	}
+
+	// Every row must continue from the BalanceAfter of the row before it
+	// Her satır kendinden önceki satırın BalanceAfter değerinden devam etmelidir
+	var computed, previous int64
+	for i := range transactions {
+		transaction := &transactions[i]
+
+		delta, known := transaction.BalanceDelta()
+		if !known {
+			discrepancy.Issues = append(discrepancy.Issues, ReconciliationIssue{
+				Kind:          IssueUnknownType,
+				TransactionID: &transaction.ID,
+				Expected:      models.NewMoney(0, wallet.Currency),
+				Actual:        models.NewMoney(0, wallet.Currency),
+			})
+		}
+
+		computed += delta
+		if expected := previous + delta; expected != transaction.BalanceAfter {
+			discrepancy.Issues = append(discrepancy.Issues, ReconciliationIssue{
+				Kind:          IssueContinuityBreak,
+				TransactionID: &transaction.ID,
+				Expected:      models.NewMoney(expected, wallet.Currency),
+				Actual:        models.NewMoney(transaction.BalanceAfter, wallet.Currency),
+			})
+		}
+		previous = transaction.BalanceAfter
+	}
+	discrepancy.ComputedBalance = models.NewMoney(computed, wallet.Currency)
+
+	if computed != wallet.Balance {
+		discrepancy.Issues = append(discrepancy.Issues, ReconciliationIssue{
+			Kind:     IssueBalanceMismatch,
+			Expected: discrepancy.ComputedBalance,
+			Actual:   discrepancy.Balance,
+		})
+	}
+
+	// Wallets never touched since the ledger was introduced have no account yet
+	// Defter eklendiğinden beri hiç kullanılmamış cüzdanların henüz hesabı yoktur
+	account, err := repos.Ledger.FindAccountByWallet(wallet.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if account != nil {
+		ledgerBalance, err := repos.Ledger.AccountBalance(account.ID)
+		if err != nil {
+			return nil, err
+		}
+		ledgerMoney := models.NewMoney(ledgerBalance, wallet.Currency)
+		discrepancy.LedgerBalance = &ledgerMoney
+
+		if ledgerBalance != wallet.Balance {
+			discrepancy.Issues = append(discrepancy.Issues, ReconciliationIssue{
+				Kind:     IssueLedgerMismatch,
+				Expected: ledgerMoney,
+				Actual:   discrepancy.Balance,
+			})
+		}
+	}
+
+	if len(discrepancy.Issues) == 0 {
+		return nil, nil
+	}
+
+	if freeze && !wallet.Frozen {
+		reason := fmt.Sprintf("reconciliation %s", time.Now().UTC().Format(time.RFC3339))
+		if err := repos.Wallets.SetFrozen(wallet, true, reason); err != nil {
+			return nil, err
+		}
+		s.log.Error("Wallet frozen by reconciliation", map[string]interface{}{
+			"wallet_id": wallet.ID,
+			"issues":    len(discrepancy.Issues),
+		})
+	}
+	discrepancy.Frozen = wallet.Frozen
+
+	return discrepancy, nil
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrReasonRequired is returned when a reversal is asked for without a reason
+	// ErrReasonRequired bir geri alma nedensiz istendiğinde döner
+	ErrReasonRequired = errors.New("reversal reason is required")
+
+	// ErrNotReversible is returned when the transaction is not of a kind that can be reversed
+	// ErrNotReversible işlem geri alınabilecek türde değilse döner
+	ErrNotReversible = errors.New("transaction cannot be reversed")
+
+	// ErrAlreadyReversed is returned when nothing of the transaction is left to reverse
+	// ErrAlreadyReversed işlemin geri alınacak kısmı kalmadığında döner
+	ErrAlreadyReversed = errors.New("transaction was already reversed")
+
+	// ErrReversalWindowExpired is returned when a sender asks too late to reverse their transfer
+	// ErrReversalWindowExpired gönderici transferini geri almayı çok geç istediğinde döner
+	ErrReversalWindowExpired = errors.New("reversal window has expired")
+)
+
+// ReversalService undoes deposits and transfers with compensating entries
+// ReversalService yatırma ve transferleri telafi kayıtları ile geri alır
+type ReversalService struct {
+	uow                *repositories.UnitOfWork
+	ledgerService      *LedgerService
+	transactionService *TransactionService
+	window             time.Duration
+	log                logger.Logger
+}
+
+// Constructor for ReversalService; window limits user-initiated reversals
+// ReversalService için constructor; window kullanıcı kaynaklı geri almaları sınırlar
+func NewReversalService(
+	uow *repositories.UnitOfWork,
+	ledgerService *LedgerService,
+	transactionService *TransactionService,
+	window time.Duration,
+	log logger.Logger,
+) *ReversalService {
+	return &ReversalService{
+		uow:                uow,
+		ledgerService:      ledgerService,
+		transactionService: transactionService,
+		window:             window,
+		log:                log,
+	}
+}
+
+// ReverseOwnTransfer lets a sender fully reverse their own transfer within the window
+// ReverseOwnTransfer göndericinin kendi transferini süre içinde tamamen geri almasını sağlar
+func (s *ReversalService) ReverseOwnTransfer(userID, transactionID uint, reason string) (*models.Transaction, error) {
+
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	var reversal *models.Transaction
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+
+			original, err := repos.Transactions.FindByID(transactionID)
+			if err != nil {
+				return err
+			}
+
+			// Other users' transactions are reported as not found
+			// Başka kullanıcıların işlemleri bulunamadı olarak raporlanır
+			if original.UserID != userID {
+				return gorm.ErrRecordNotFound
+			}
+
+			// Only plain transfers between users; payments that settled something stay paid
+			// Sadece kullanıcılar arası düz transferler; bir şeyi kapatan ödemeler ödenmiş kalır
+			if original.Type != models.TransactionTypeTransferSent || original.TargetUserID == nil {
+				return fmt.Errorf("%w: only sent transfers can be reversed", ErrNotReversible)
+			}
+			if original.Purpose != "" {
+				return fmt.Errorf("%w: %s transfers are final", ErrNotReversible, strings.ReplaceAll(original.Purpose, "_", " "))
+			}
+			if original.ReversedAmount > 0 {
+				return ErrAlreadyReversed
+			}
+			if time.Since(original.CreatedAt) > s.window {
+				return ErrReversalWindowExpired
+			}
+
+			reversal, err = s.reverse(repos, original, original.Amount, reason)
+			return err
+		})
+	})
+	if err != nil {
+		s.log.Error("Reversal failed", map[string]interface{}{
+			"user_id":        userID,
+			"transaction_id": transactionID,
+		})
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+// AdminReverse reverses a deposit or transfer, fully or partially.
+// An amount of 0 reverses whatever is left. The amount is read in the transaction's currency.
+//
+// AdminReverse bir yatırma veya transferi tamamen ya da kısmen geri alır.
+// 0 tutarı kalan miktarın tamamını geri alır. Tutar işlemin para biriminde okunur.
+func (s *ReversalService) AdminReverse(transactionID uint, value models.Decimal, reason string) (*models.Transaction, error) {
+
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	var reversal *models.Transaction
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+
+			original, err := repos.Transactions.FindByID(transactionID)
+			if err != nil {
+				return err
+			}
+
+			if original.Type != models.TransactionTypeDeposit && original.Type != models.TransactionTypeTransferSent {
+				return fmt.Errorf("%w: only deposits and sent transfers can be reversed", ErrNotReversible)
+			}
+
+			remaining := original.Amount - original.ReversedAmount
+			if remaining == 0 {
+				return ErrAlreadyReversed
+			}
+
+			currency, err := models.LookupCurrency(original.Currency)
+			if err != nil {
+				return err
+			}
+			refund, err := value.Minor(currency)
+			if err != nil {
+				return err
+			}
+			if refund == 0 {
+				refund = remaining
+			}
+
+			reversal, err = s.reverse(repos, original, refund, reason)
+			return err
+		})
+	})
+	if err != nil {
+		s.log.Error("Admin reversal failed", map[string]interface{}{
+			"transaction_id": transactionID,
+		})
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+// reverse moves the money back inside the unit of work and writes linked history rows.
+// It returns the entry of the user who gets the money back.
+//
+// reverse parayı unit of work içinde geri taşır ve bağlantılı geçmiş satırlarını yazar.
+// Parayı geri alan kullanıcının kaydını döndürür.
+func (s *ReversalService) reverse(repos *repositories.Repositories, original *models.Transaction, amount int64, reason string) (*models.Transaction, error) {
+	ledger := s.ledgerService.WithTx(repos)
+	history := s.transactionService.WithTx(repos)
+
+	// Joint wallet payments were approved by their members, so they are not undone here
+	// Ortak cüzdan ödemeleri üyelerince onaylanmıştır, bu yüzden burada geri alınmaz
+	if original.JointID != 0 {
+		return nil, fmt.Errorf("%w: joint wallet transactions are final", ErrNotReversible)
+	}
+
+	// Guard against reversing more than the original, even under concurrency
+	// Eşzamanlılıkta bile orijinalden fazlasının geri alınmasını engelle
+	if err := repos.Transactions.AddReversedAmount(original, amount); err != nil {
+		return nil, err
+	}
+
+	switch original.Type {
+
+	case models.TransactionTypeDeposit:
+		wallet, err := repos.Wallets.FindByUserAndCurrency(original.UserID, original.Currency)
+		if err != nil {
+			return nil, err
+		}
+		if wallet.Available() < amount {
+			return nil, fmt.Errorf("%w to reverse the deposit", ErrInsufficientFunds)
+		}
+
+		walletAccount, err := ledger.WalletAccount(wallet)
+		if err != nil {
+			return nil, err
+		}
+		depositAccount, err := ledger.SystemAccount(models.SystemAccountDeposits, original.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := repos.Wallets.UpdateBalance(wallet, wallet.Balance-amount); err != nil {
+			return nil, err
+		}
+
+		if err := ledger.Post(models.JournalEntryReversal, fmt.Sprintf("reversal of transaction:%d", original.ID),
+			LedgerLine{Account: walletAccount, Amount: -amount},
+			LedgerLine{Account: depositAccount, Amount: amount},
+		); err != nil {
+			return nil, err
+		}
+
+		debit := &models.Transaction{
+			UserID:       original.UserID,
+			Type:         models.TransactionTypeReversalDebit,
+			Amount:       amount,
+			Currency:     original.Currency,
+			BalanceAfter: wallet.Balance,
+			ReversalOfID: &original.ID,
+			Reason:       reason,
+		}
+		if err := history.RecordEntry(debit); err != nil {
+			return nil, err
+		}
+		return debit, nil
+
+	case models.TransactionTypeTransferSent:
+		if original.Rate != "" {
+			return nil, fmt.Errorf("%w: cross-currency transfers are final", ErrNotReversible)
+		}
+
+		senderID := original.UserID
+		recipientID := *original.TargetUserID
+
+		sender, err := repos.Wallets.FindByUserAndCurrency(senderID, original.Currency)
+		if err != nil {
+			return nil, err
+		}
+		recipient, err := repos.Wallets.FindByUserAndCurrency(recipientID, original.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		// The money must still be in the recipient's wallet
+		// Para hâlâ alıcının cüzdanında olmalıdır
+		if recipient.Available() < amount {
+			return nil, fmt.Errorf("%w: the recipient no longer holds the money", ErrInsufficientFunds)
+		}
+
+		senderAccount, err := ledger.WalletAccount(sender)
+		if err != nil {
+			return nil, err
+		}
+		recipientAccount, err := ledger.WalletAccount(recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := repos.Wallets.UpdateBalance(recipient, recipient.Balance-amount); err != nil {
+			return nil, err
+		}
+		credited, err := models.NewMoney(sender.Balance, original.Currency).Add(models.NewMoney(amount, original.Currency))
+		if err != nil {
+			return nil, err
+		}
+		if err := repos.Wallets.UpdateBalance(sender, credited.Minor); err != nil {
+			return nil, err
+		}
+
+		if err := ledger.Post(models.JournalEntryReversal, fmt.Sprintf("reversal of transaction:%d", original.ID),
+			LedgerLine{Account: recipientAccount, Amount: -amount},
+			LedgerLine{Account: senderAccount, Amount: amount},
+		); err != nil {
+			return nil, err
+		}
+
+		correlationID := models.NewCorrelationID()
+		debit := &models.Transaction{
+			UserID:        recipientID,
+			Type:          models.TransactionTypeReversalDebit,
+			Amount:        amount,
+			Currency:      original.Currency,
+			TargetUserID:  &senderID,
+			BalanceAfter:  recipient.Balance,
+			ReversalOfID:  &original.ID,
+			Reason:        reason,
+			CorrelationID: correlationID,
+		}
+		if err := history.RecordEntry(debit); err != nil {
+			return nil, err
+		}
+
+		credit := &models.Transaction{
+			UserID:        senderID,
+			Type:          models.TransactionTypeReversalCredit,
+			Amount:        amount,
+			Currency:      original.Currency,
+			TargetUserID:  &recipientID,
+			BalanceAfter:  sender.Balance,
+			ReversalOfID:  &original.ID,
+			Reason:        reason,
+			CorrelationID: correlationID,
+		}
+		if err := history.RecordEntry(credit); err != nil {
+			return nil, err
+		}
+
+		// Once the whole transfer is undone the sender gets its fee back too
+		// Transferin tamamı geri alındığında gönderici ücretini de geri alır
+		if original.ReversedAmount == original.Amount {
+			if err := s.refundFee(repos, original, sender, reason); err != nil {
+				return nil, err
+			}
+		}
+		return credit, nil
+	}
+
+	return nil, ErrNotReversible
+}
+
+// refundFee gives the sender back the fee charged with a transfer, found by its correlation ID.
+// The fee row is marked as reversed like the transfer, so it is never refunded twice.
+//
+// refundFee bir transferle alınan ve correlation ID ile bulunan ücreti göndericiye geri verir.
+// Ücret satırı transfer gibi geri alınmış olarak işaretlenir, böylece asla iki kez iade edilmez.
+func (s *ReversalService) refundFee(repos *repositories.Repositories, original *models.Transaction, sender *models.Wallet, reason string) error {
+	if original.CorrelationID == "" {
+		return nil
+	}
+
+	linked, err := repos.Transactions.FindByCorrelation(original.CorrelationID)
+	if err != nil {
+		return err
+	}
+
+	for i := range linked {
+		fee := &linked[i]
+		if fee.Type != models.TransactionTypeFee || fee.UserID != original.UserID || fee.JointID != 0 {
+			continue
+		}
+
+		if err := repos.Transactions.AddReversedAmount(fee, fee.Amount); err != nil {
+			return err
+		}
+
+		ledger := s.ledgerService.WithTx(repos)
+		senderAccount, err := ledger.WalletAccount(sender)
+		if err != nil {
+			return err
+		}
+		revenueAccount, err := ledger.SystemAccount(models.SystemAccountFees, fee.Currency)
+		if err != nil {
+			return err
+		}
+
+		refunded, err := models.NewMoney(sender.Balance, fee.Currency).Add(models.NewMoney(fee.Amount, fee.Currency))
+		if err != nil {
+			return err
+		}
+		if err := repos.Wallets.UpdateBalance(sender, refunded.Minor); err != nil {
+			return err
+		}
+
+		if err := ledger.Post(models.JournalEntryReversal, fmt.Sprintf("reversal of transaction:%d", fee.ID),
+			LedgerLine{Account: revenueAccount, Amount: -fee.Amount},
+			LedgerLine{Account: senderAccount, Amount: fee.Amount},
+		); err != nil {
+			return err
+		}
+
+		if err := s.transactionService.WithTx(repos).RecordEntry(&models.Transaction{
+			UserID:       sender.UserID,
+			Type:         models.TransactionTypeReversalCredit,
+			Amount:       fee.Amount,
+			Currency:     fee.Currency,
+			BalanceAfter: sender.Balance,
+			ReversalOfID: &fee.ID,
+			Reason:       reason,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
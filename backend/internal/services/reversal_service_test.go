@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// sentTransfer returns the sender's row of the last transfer from userID
+// sentTransfer userID'den yapılan son transferin gönderici satırını döndürür
+func (e *testEnv) sentTransfer(t *testing.T, userID uint) *models.Transaction {
+	t.Helper()
+
+	var sent models.Transaction
+	if err := e.db.GetDB().Where("user_id = ? AND type = ?", userID, models.TransactionTypeTransferSent).
+		Order("id DESC").First(&sent).Error; err != nil {
+		t.Fatalf("load sent transfer: %v", err)
+	}
+	return &sent
+}
+
+// TestReverseOwnTransferRules checks who may reverse a transfer, for how long and which transfers stay final
+// TestReverseOwnTransferRules bir transferi kimin, ne kadar süre geri alabileceğini ve hangi transferlerin kesin kaldığını kontrol eder
+func TestReverseOwnTransferRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		purpose string
+		age     time.Duration
+		caller  func(sender, recipient uint) uint
+		want    error
+	}{
+		{name: "plain transfer in the window", caller: func(sender, _ uint) uint { return sender }},
+		{name: "window expired", age: 2 * time.Hour, caller: func(sender, _ uint) uint { return sender }, want: ErrReversalWindowExpired},
+		{name: "settlement is final", purpose: models.TransferPurposeSettlement, caller: func(sender, _ uint) uint { return sender }, want: ErrNotReversible},
+		{name: "qr payment is final", purpose: models.TransferPurposeQRPayment, caller: func(sender, _ uint) uint { return sender }, want: ErrNotReversible},
+		{name: "recipient cannot reverse", caller: func(_, recipient uint) uint { return recipient }, want: gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			sender := env.newUser(t, "sender@example.com")
+			recipient := env.newUser(t, "recipient@example.com")
+			if err := env.walletService.Deposit(sender, "TRY", mustDecimal(t, "100.00")); err != nil {
+				t.Fatalf("deposit: %v", err)
+			}
+			if _, err := env.walletService.send(sender, recipient, "TRY", 4000, tt.purpose); err != nil {
+				t.Fatalf("transfer: %v", err)
+			}
+
+			sent := env.sentTransfer(t, sender)
+			if tt.age > 0 {
+				if err := env.db.GetDB().Model(sent).UpdateColumn("created_at", time.Now().Add(-tt.age)).Error; err != nil {
+					t.Fatalf("age transfer: %v", err)
+				}
+			}
+
+			reversals := NewReversalService(env.uow, env.ledgerService, env.transactionService, time.Hour, nopLogger{})
+			_, err := reversals.ReverseOwnTransfer(tt.caller(sender, recipient), sent.ID, "sent by mistake")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ReverseOwnTransfer = %v, want %v", err, tt.want)
+			}
+
+			wantSender, wantRecipient := int64(6000), int64(4000)
+			if tt.want == nil {
+				wantSender, wantRecipient = 10000, 0
+			}
+			if got := env.balance(t, sender); got != wantSender {
+				t.Errorf("sender balance = %d, want %d", got, wantSender)
+			}
+			if got := env.balance(t, recipient); got != wantRecipient {
+				t.Errorf("recipient balance = %d, want %d", got, wantRecipient)
+			}
+		})
+	}
+}
+
+// TestAdminReversePartialAmounts reverses a transfer in parts; nothing may be reversed twice
+// and the transfer fee comes back only with the last part.
+//
+// TestAdminReversePartialAmounts bir transferi parça parça geri alır; hiçbir şey iki kez
+// geri alınmamalı ve transfer ücreti yalnızca son parçayla geri gelmelidir.
+func TestAdminReversePartialAmounts(t *testing.T) {
+	env := newTestEnv(t)
+	sender := env.newUser(t, "sender@example.com")
+	recipient := env.newUser(t, "recipient@example.com")
+	if err := env.walletService.Deposit(sender, "TRY", mustDecimal(t, "5000.00")); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+
+	// 0.1% of 2,000.00 TRY under the built-in fee table
+	// Yerleşik ücret tablosuna göre 2.000,00 TRY'nin %0,1'i
+	fee, err := env.walletService.Transfer(sender, recipient, "TRY", mustDecimal(t, "2000.00"))
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if fee.Amount.Minor != 200 {
+		t.Fatalf("fee = %d, want 200", fee.Amount.Minor)
+	}
+	sent := env.sentTransfer(t, sender)
+	reversals := NewReversalService(env.uow, env.ledgerService, env.transactionService, time.Hour, nopLogger{})
+
+	steps := []struct {
+		name          string
+		amount        string
+		want          error
+		wantSender    int64
+		wantRecipient int64
+	}{
+		{name: "part", amount: "500.00", wantSender: 349800, wantRecipient: 150000},
+		{name: "more than is left", amount: "1500.01", want: repositories.ErrReversalExceedsAmount, wantSender: 349800, wantRecipient: 150000},
+		{name: "too many decimals", amount: "0.001", want: models.ErrExcessPrecision, wantSender: 349800, wantRecipient: 150000},
+		{name: "the rest with the fee", amount: "0", wantSender: 500000, wantRecipient: 0},
+		{name: "nothing left", amount: "0", want: ErrAlreadyReversed, wantSender: 500000, wantRecipient: 0},
+	}
+
+	for _, step := range steps {
+		_, err := reversals.AdminReverse(sent.ID, mustDecimal(t, step.amount), "disputed")
+		if !errors.Is(err, step.want) {
+			t.Fatalf("%s: AdminReverse = %v, want %v", step.name, err, step.want)
+		}
+		if got := env.balance(t, sender); got != step.wantSender {
+			t.Errorf("%s: sender balance = %d, want %d", step.name, got, step.wantSender)
+		}
+		if got := env.balance(t, recipient); got != step.wantRecipient {
+			t.Errorf("%s: recipient balance = %d, want %d", step.name, got, step.wantRecipient)
+		}
+	}
+
+	env.assertBooksAgree(t)
+}
@@ -0,0 +1,379 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"mini-pay-backend/internal/clock"
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// scheduleBatchSize bounds how many schedules one scheduler tick runs
+// scheduleBatchSize zamanlayıcının bir tikte çalıştırdığı zamanlama sayısını sınırlar
+const scheduleBatchSize = 100
+
+// ScheduleInput carries the fields a user sets on a schedule
+// ScheduleInput kullanıcının bir zamanlamada belirlediği alanları taşır
+type ScheduleInput struct {
+	ToUserID  uint           `json:"to_user_id"`
+	Amount    models.Decimal `json:"amount"`
+	Currency  string         `json:"currency"`
+	Note      string         `json:"note"`
+	Frequency string         `json:"frequency"`
+	StartAt   time.Time      `json:"start_at"`
+	EndAt     *time.Time     `json:"end_at"`
+	MaxRuns   int            `json:"max_runs"`
+}
+
+// ScheduleService stores scheduled transfers and runs them through the wallet service's transfer path
+// ScheduleService zamanlanmış transferleri saklar ve cüzdan servisinin transfer yolu ile çalıştırır
+type ScheduleService struct {
+	uow             *repositories.UnitOfWork
+	scheduleRepo    *repositories.ScheduleRepository
+	userRepo        *repositories.UserRepository
+	walletService   *WalletService
+	clock           clock.Clock
+	defaultCurrency string
+	retryDelay      time.Duration
+	maxAttempts     int
+	log             logger.Logger
+}
+
+// Constructor for ScheduleService.
+// A run that hits insufficient funds is retried after retryDelay, at most maxAttempts times.
+//
+// ScheduleService için constructor.
+// Bakiye yetersizliğine takılan çalıştırma retryDelay sonra, en fazla maxAttempts kez tekrar denenir.
+func NewScheduleService(
+	uow *repositories.UnitOfWork,
+	scheduleRepo *repositories.ScheduleRepository,
+	userRepo *repositories.UserRepository,
+	walletService *WalletService,
+	clock clock.Clock,
+	defaultCurrency string,
+	retryDelay time.Duration,
+	maxAttempts int,
+	log logger.Logger,
+) *ScheduleService {
+	return &ScheduleService{
+		uow:             uow,
+		scheduleRepo:    scheduleRepo,
+		userRepo:        userRepo,
+		walletService:   walletService,
+		clock:           clock,
+		defaultCurrency: defaultCurrency,
+		retryDelay:      retryDelay,
+		maxAttempts:     maxAttempts,
+		log:             log,
+	}
+}
+
+// Create stores a new schedule; its first run is at StartAt
+// Create yeni bir zamanlama kaydeder; ilk çalıştırma StartAt zamanındadır
+func (s *ScheduleService) Create(userID uint, input ScheduleInput) (*models.ScheduledTransfer, error) {
+	schedule := &models.ScheduledTransfer{
+		UserID: userID,
+		Status: models.ScheduleStatusActive,
+	}
+	if err := s.apply(schedule, input); err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = schedule.StartAt
+
+	if err := s.scheduleRepo.Create(schedule); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Schedule created", map[string]interface{}{
+		"schedule_id": schedule.ID,
+		"user_id":     userID,
+		"frequency":   schedule.Frequency,
+		"next_run_at": schedule.NextRunAt,
+	})
+
+	return schedule, nil
+}
+
+// Get returns one of the user's schedules
+// Get kullanıcının zamanlamalarından birini döndürür
+func (s *ScheduleService) Get(userID, scheduleID uint) (*models.ScheduledTransfer, error) {
+	schedule, err := s.scheduleRepo.FindByID(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return schedule, nil
+}
+
+// List returns all schedules of the user
+// List kullanıcının tüm zamanlamalarını döndürür
+func (s *ScheduleService) List(userID uint) ([]models.ScheduledTransfer, error) {
+	return s.scheduleRepo.FindByUser(userID)
+}
+
+// Update replaces the schedule's settings and restarts counting from the new StartAt.
+// A failed schedule becomes active again; completed and cancelled ones cannot change.
+//
+// Update zamanlamanın ayarlarını değiştirir ve saymaya yeni StartAt'tan yeniden başlar.
+// Başarısız zamanlama tekrar aktif olur; tamamlanmış ve iptal edilmiş olanlar değişemez.
+func (s *ScheduleService) Update(userID, scheduleID uint, input ScheduleInput) (*models.ScheduledTransfer, error) {
+	schedule, err := s.Get(userID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != models.ScheduleStatusActive && schedule.Status != models.ScheduleStatusFailed {
+		return nil, errors.New("schedule can no longer be changed")
+	}
+
+	if err := s.apply(schedule, input); err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = schedule.StartAt
+	schedule.RunCount = 0
+	schedule.Attempts = 0
+	schedule.LastError = ""
+	schedule.Status = models.ScheduleStatusActive
+
+	if err := s.scheduleRepo.Update(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Cancel stops a schedule; past runs are not affected
+// Cancel zamanlamayı durdurur; geçmiş çalıştırmalar etkilenmez
+func (s *ScheduleService) Cancel(userID, scheduleID uint) (*models.ScheduledTransfer, error) {
+	schedule, err := s.Get(userID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status == models.ScheduleStatusCompleted || schedule.Status == models.ScheduleStatusCancelled {
+		return nil, errors.New("schedule is already finished")
+	}
+
+	schedule.Status = models.ScheduleStatusCancelled
+	if err := s.scheduleRepo.Update(schedule); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Schedule cancelled", map[string]interface{}{
+		"schedule_id": schedule.ID,
+		"user_id":     userID,
+	})
+
+	return schedule, nil
+}
+
+// RunDue executes every schedule that is due by the clock and returns how many were tried.
+// A schedule that cannot be run is logged and skipped, so it does not hold up the others.
+//
+// RunDue saate göre zamanı gelmiş tüm zamanlamaları çalıştırır ve kaç tanesinin denendiğini döndürür.
+// Çalıştırılamayan zamanlama loglanır ve atlanır, böylece diğerlerini bekletmez.
+func (s *ScheduleService) RunDue() (int, error) {
+	now := s.clock.Now()
+
+	schedules, err := s.scheduleRepo.FindDue(now, scheduleBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	tried := 0
+	for i := range schedules {
+		err := s.execute(&schedules[i], now)
+
+		// Changed by its owner or another runner in the meantime
+		// Bu arada sahibi veya başka bir çalıştırıcı tarafından değiştirildi
+		if errors.Is(err, repositories.ErrScheduleChanged) {
+			continue
+		}
+		if err != nil {
+			s.log.Error("Schedule could not be run", map[string]interface{}{
+				"schedule_id": schedules[i].ID,
+				"error":       err.Error(),
+			})
+			continue
+		}
+		tried++
+	}
+
+	return tried, nil
+}
+
+// RunScheduler calls RunDue on every tick; it blocks, so start it in a goroutine
+// RunScheduler her tikte RunDue çağırır; bloklar, bu yüzden goroutine içinde başlatılmalıdır
+func (s *ScheduleService) RunScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := s.RunDue(); err != nil {
+			s.log.Error("Scheduler run failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// execute runs a single due schedule.
+// The transfer and the schedule's advance to its next run commit in one unit of work,
+// so a crash either leaves the run undone or done and counted, and never pays twice.
+// A runner that lost the race to another one fails the version check and rolls its transfer back.
+//
+// execute zamanı gelmiş tek bir zamanlamayı çalıştırır.
+// Transfer ve zamanlamanın bir sonraki çalıştırmaya ilerlemesi tek bir unit of work'te commit edilir,
+// böylece bir çökme çalıştırmayı ya yapılmamış ya da yapılmış ve sayılmış bırakır, asla iki kez ödemez.
+// Başka bir çalıştırıcıya yarışı kaybeden çalıştırıcı versiyon kontrolüne takılır ve transferini geri alır.
+func (s *ScheduleService) execute(schedule *models.ScheduledTransfer, now time.Time) error {
+	var done models.ScheduledTransfer
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+
+			// Work on a copy, so a rollback leaves the schedule as it was read
+			// Bir kopya üzerinde çalış, böylece geri alma zamanlamayı okunduğu gibi bırakır
+			done = *schedule
+
+			if _, _, err := s.walletService.transfer(repos, done.UserID, done.ToUserID, done.Currency, done.Amount, ""); err != nil {
+				return err
+			}
+
+			done.LastRunAt = &now
+			done.RunCount++
+			done.Attempts = 0
+			done.LastError = ""
+
+			next := nextOccurrence(done.StartAt, done.Frequency, done.RunCount)
+			if scheduleFinished(&done, next) {
+				done.Status = models.ScheduleStatusCompleted
+			} else {
+				done.NextRunAt = next
+			}
+
+			return repos.Schedules.Update(&done)
+		})
+	})
+	if errors.Is(err, repositories.ErrScheduleChanged) {
+		return err
+	}
+
+	if err == nil {
+		*schedule = done
+		s.log.Info("Scheduled transfer executed", map[string]interface{}{
+			"schedule_id": schedule.ID,
+			"run_count":   schedule.RunCount,
+			"status":      schedule.Status,
+		})
+		return nil
+	}
+
+	// Nothing moved; record the failed attempt and retry later if only the money was missing
+	// Hiçbir şey taşınmadı; başarısız denemeyi kaydet ve sadece para eksikse sonra tekrar dene
+	schedule.LastRunAt = &now
+	schedule.Attempts++
+	schedule.LastError = err.Error()
+	if errors.Is(err, ErrInsufficientFunds) && schedule.Attempts < s.maxAttempts {
+		schedule.NextRunAt = now.Add(s.retryDelay)
+	} else {
+		schedule.Status = models.ScheduleStatusFailed
+	}
+
+	s.log.Error("Scheduled transfer failed", map[string]interface{}{
+		"schedule_id": schedule.ID,
+		"attempts":    schedule.Attempts,
+		"status":      schedule.Status,
+		"error":       err.Error(),
+	})
+
+	return s.scheduleRepo.Update(schedule)
+}
+
+// apply validates input and copies it onto the schedule
+// apply girdiyi doğrular ve zamanlamaya kopyalar
+func (s *ScheduleService) apply(schedule *models.ScheduledTransfer, input ScheduleInput) error {
+	if input.ToUserID == schedule.UserID {
+		return errors.New("cannot schedule a transfer to self")
+	}
+	if input.Amount.IsZero() {
+		return errors.New("invalid transfer amount")
+	}
+	if _, err := s.userRepo.FindByID(input.ToUserID); err != nil {
+		return errors.New("recipient not found")
+	}
+
+	currency, amount, err := resolveAmount(input.Currency, s.defaultCurrency, input.Amount)
+	if err != nil {
+		return err
+	}
+
+	switch input.Frequency {
+	case "":
+		input.Frequency = models.ScheduleOnce
+	case models.ScheduleOnce, models.ScheduleDaily, models.ScheduleWeekly, models.ScheduleMonthly:
+	default:
+		return errors.New("frequency must be once, daily, weekly or monthly")
+	}
+
+	if input.StartAt.IsZero() || input.StartAt.Before(s.clock.Now()) {
+		return errors.New("start_at must be in the future")
+	}
+	if input.EndAt != nil && input.EndAt.Before(input.StartAt) {
+		return errors.New("end_at must not be before start_at")
+	}
+	if input.MaxRuns < 0 {
+		return errors.New("invalid max_runs")
+	}
+
+	schedule.ToUserID = input.ToUserID
+	schedule.Amount = amount
+	schedule.Currency = currency
+	schedule.Note = input.Note
+	schedule.Frequency = input.Frequency
+	schedule.StartAt = input.StartAt
+	schedule.EndAt = input.EndAt
+	schedule.MaxRuns = input.MaxRuns
+	return nil
+}
+
+// nextOccurrence returns the n-th run after start (n = 0 is start itself).
+// Months are clamped to their last day, so a schedule starting on the 31st
+// runs on the 30th in April instead of drifting into May.
+//
+// nextOccurrence start'tan sonraki n. çalıştırmayı döndürür (n = 0 start'ın kendisidir).
+// Aylar son günlerine sabitlenir, böylece 31'inde başlayan zamanlama
+// Mayıs'a kaymak yerine Nisan'da 30'unda çalışır.
+func nextOccurrence(start time.Time, frequency string, n int) time.Time {
+	switch frequency {
+	case models.ScheduleDaily:
+		return start.AddDate(0, 0, n)
+	case models.ScheduleWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case models.ScheduleMonthly:
+		firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(n), 1,
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+		day := start.Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return firstOfMonth.AddDate(0, 0, day-1)
+	}
+	return start
+}
+
+// scheduleFinished reports whether a schedule has no run left at next
+// scheduleFinished zamanlamanın next zamanında çalıştırması kalmadığını bildirir
+func scheduleFinished(schedule *models.ScheduledTransfer, next time.Time) bool {
+	if schedule.Frequency == models.ScheduleOnce {
+		return true
+	}
+	if schedule.MaxRuns > 0 && schedule.RunCount >= schedule.MaxRuns {
+		return true
+	}
+	return schedule.EndAt != nil && next.After(*schedule.EndAt)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"mini-pay-backend/internal/clock"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+)
+
+func newTestScheduleService(env *testEnv, clk clock.Clock) (*ScheduleService, *repositories.ScheduleRepository) {
+	scheduleRepo := repositories.NewScheduleRepository(env.db)
+	service := NewScheduleService(env.uow, scheduleRepo, env.userRepo, env.walletService, clk, "TRY", time.Hour, 2, nopLogger{})
+	return service, scheduleRepo
+}
+
+func runDue(t *testing.T, service *ScheduleService, want int) {
+	t.Helper()
+
+	tried, err := service.RunDue()
+	if err != nil {
+		t.Fatalf("run due: %v", err)
+	}
+	if tried != want {
+		t.Fatalf("RunDue tried %d schedules, want %d", tried, want)
+	}
+}
+
+// TestScheduleRunsOnManualClock drives a daily schedule with a manual clock: each run pays
+// once, running again before the next occurrence pays nothing, and max_runs completes it.
+//
+// TestScheduleRunsOnManualClock günlük bir zamanlamayı manuel saatle çalıştırır: her çalıştırma
+// bir kez öder, sonraki zamandan önce tekrar çalıştırmak hiçbir şey ödemez ve max_runs onu tamamlar.
+func TestScheduleRunsOnManualClock(t *testing.T) {
+	env := newTestEnv(t)
+	payer := env.newUser(t, "payer@example.com")
+	payee := env.newUser(t, "payee@example.com")
+	if err := env.walletService.Deposit(payer, "TRY", mustDecimal(t, "100.00")); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+
+	clk := clock.NewManual(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	service, scheduleRepo := newTestScheduleService(env, clk)
+
+	start := clk.Now().Add(time.Hour)
+	schedule, err := service.Create(payer, ScheduleInput{
+		ToUserID:  payee,
+		Amount:    mustDecimal(t, "10.00"),
+		Frequency: models.ScheduleDaily,
+		StartAt:   start,
+		MaxRuns:   3,
+	})
+	if err != nil {
+		t.Fatalf("create schedule: %v", err)
+	}
+
+	runDue(t, service, 0)
+
+	clk.Advance(time.Hour)
+	runDue(t, service, 1)
+	runDue(t, service, 0)
+
+	schedule, err = scheduleRepo.FindByID(schedule.ID)
+	if err != nil {
+		t.Fatalf("load schedule: %v", err)
+	}
+	if schedule.RunCount != 1 || !schedule.NextRunAt.Equal(start.AddDate(0, 0, 1)) {
+		t.Fatalf("after first run: run_count = %d, next_run_at = %s", schedule.RunCount, schedule.NextRunAt)
+	}
+
+	for day := 0; day < 3; day++ {
+		clk.Advance(24 * time.Hour)
+		want := 1
+		if day == 2 {
+			want = 0
+		}
+		runDue(t, service, want)
+	}
+
+	schedule, err = scheduleRepo.FindByID(schedule.ID)
+	if err != nil {
+		t.Fatalf("load schedule: %v", err)
+	}
+	if schedule.Status != models.ScheduleStatusCompleted || schedule.RunCount != 3 {
+		t.Errorf("schedule status = %s with %d runs, want completed with 3", schedule.Status, schedule.RunCount)
+	}
+	if got := env.balance(t, payee); got != 3000 {
+		t.Errorf("payee balance = %d, want 3000", got)
+	}
+	if got := env.balance(t, payer); got != 7000 {
+		t.Errorf("payer balance = %d, want 7000", got)
+	}
+
+	env.assertBooksAgree(t)
+}
+
+// TestScheduleFailureDoesNotStopOthers checks that a schedule short of money is retried
+// and then failed, while another schedule due at the same time still runs.
+//
+// TestScheduleFailureDoesNotStopOthers parası yetmeyen bir zamanlamanın tekrar denenip
+// sonra başarısız olduğunu, aynı anda zamanı gelen diğer zamanlamanın yine de çalıştığını kontrol eder.
+func TestScheduleFailureDoesNotStopOthers(t *testing.T) {
+	env := newTestEnv(t)
+	poor := env.newUser(t, "poor@example.com")
+	rich := env.newUser(t, "rich@example.com")
+	payee := env.newUser(t, "payee@example.com")
+	if err := env.walletService.Deposit(rich, "TRY", mustDecimal(t, "50.00")); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+
+	clk := clock.NewManual(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	service, scheduleRepo := newTestScheduleService(env, clk)
+
+	start := clk.Now().Add(time.Minute)
+	failing, err := service.Create(poor, ScheduleInput{ToUserID: payee, Amount: mustDecimal(t, "20.00"), StartAt: start})
+	if err != nil {
+		t.Fatalf("create schedule: %v", err)
+	}
+	paying, err := service.Create(rich, ScheduleInput{ToUserID: payee, Amount: mustDecimal(t, "20.00"), StartAt: start})
+	if err != nil {
+		t.Fatalf("create schedule: %v", err)
+	}
+
+	clk.Advance(time.Minute)
+	runDue(t, service, 2)
+
+	paying, err = scheduleRepo.FindByID(paying.ID)
+	if err != nil {
+		t.Fatalf("load schedule: %v", err)
+	}
+	if paying.Status != models.ScheduleStatusCompleted {
+		t.Errorf("funded schedule status = %s, want completed", paying.Status)
+	}
+
+	failing, err = scheduleRepo.FindByID(failing.ID)
+	if err != nil {
+		t.Fatalf("load schedule: %v", err)
+	}
+	if failing.Status != models.ScheduleStatusActive || failing.Attempts != 1 || !failing.NextRunAt.Equal(clk.Now().Add(time.Hour)) {
+		t.Fatalf("after first attempt: status = %s, attempts = %d, next_run_at = %s", failing.Status, failing.Attempts, failing.NextRunAt)
+	}
+
+	clk.Advance(time.Hour)
+	runDue(t, service, 1)
+
+	failing, err = scheduleRepo.FindByID(failing.ID)
+	if err != nil {
+		t.Fatalf("load schedule: %v", err)
+	}
+	if failing.Status != models.ScheduleStatusFailed || failing.RunCount != 0 {
+		t.Errorf("unfunded schedule status = %s with %d runs, want failed with 0", failing.Status, failing.RunCount)
+	}
+	if got := env.balance(t, payee); got != 2000 {
+		t.Errorf("payee balance = %d, want 2000", got)
+	}
+
+	env.assertBooksAgree(t)
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"mini-pay-backend/internal/models"
+)
+
+// flushWriter pushes buffered output to the client, if the writer buffers at all
+// flushWriter tamponlanmış çıktıyı, yazıcı tamponluyorsa istemciye iter
+func flushWriter(w io.Writer) error {
+	if flusher, ok := w.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// counterparty formats the other user of an entry ("" when there is none)
+// counterparty bir kaydın karşı tarafındaki kullanıcıyı biçimlendirir (yoksa "")
+func counterparty(transaction *models.Transaction) string {
+	if transaction.TargetUserID == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*transaction.TargetUserID), 10)
+}
+
+// csvStatementWriter writes one row per entry between an opening and a closing balance row
+// csvStatementWriter açılış ve kapanış bakiye satırları arasına her kayıt için bir satır yazar
+type csvStatementWriter struct {
+	out      io.Writer
+	csv      *csv.Writer
+	currency models.Currency
+}
+
+func newCSVStatementWriter(w io.Writer) *csvStatementWriter {
+	return &csvStatementWriter{out: w, csv: csv.NewWriter(w)}
+}
+
+func (w *csvStatementWriter) begin(statement *Statement) error {
+	w.currency = statement.Currency
+	if err := w.csv.Write([]string{"date", "transaction_id", "type", "counterparty_user_id", "reason", "amount", "currency", "balance"}); err != nil {
+		return err
+	}
+	return w.csv.Write([]string{
+		statement.From.UTC().Format(time.RFC3339), "", "opening_balance", "", "", "",
+		w.currency.Code, w.currency.FormatMinor(statement.OpeningBalance),
+	})
+}
+
+func (w *csvStatementWriter) entry(transaction *models.Transaction, delta int64) error {
+	return w.csv.Write([]string{
+		transaction.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatUint(uint64(transaction.ID), 10),
+		transaction.Type,
+		counterparty(transaction),
+		transaction.Reason,
+		w.currency.FormatMinor(delta),
+		w.currency.Code,
+		w.currency.FormatMinor(transaction.BalanceAfter),
+	})
+}
+
+func (w *csvStatementWriter) end(statement *Statement, closingBalance int64, _ int) error {
+	return w.csv.Write([]string{
+		statement.To.UTC().Format(time.RFC3339), "", "closing_balance", "", "", "",
+		w.currency.Code, w.currency.FormatMinor(closingBalance),
+	})
+}
+
+func (w *csvStatementWriter) flush() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return flushWriter(w.out)
+}
+
+// jsonlStatementRecord is one line of a JSON Lines statement; amounts are decimal strings
+// like everywhere else in the API
+//
+// jsonlStatementRecord JSON Lines hesap özetinin bir satırıdır; tutarlar API'nin geri
+// kalanında olduğu gibi ondalık metindir
+type jsonlStatementRecord struct {
+	Record         string              `json:"record"`
+	WalletID       uint                `json:"wallet_id,omitempty"`
+	Currency       string              `json:"currency,omitempty"`
+	From           *time.Time          `json:"from,omitempty"`
+	To             *time.Time          `json:"to,omitempty"`
+	OpeningBalance *models.Money       `json:"opening_balance,omitempty"`
+	ClosingBalance *models.Money       `json:"closing_balance,omitempty"`
+	Entries        *int                `json:"entries,omitempty"`
+	Delta          *models.Money       `json:"delta,omitempty"`
+	Transaction    *models.Transaction `json:"transaction,omitempty"`
+}
+
+// jsonlStatementWriter writes a header line, one line per entry and a summary line
+// jsonlStatementWriter bir başlık satırı, her kayıt için bir satır ve bir özet satırı yazar
+type jsonlStatementWriter struct {
+	out     io.Writer
+	encoder *json.Encoder
+}
+
+func newJSONLStatementWriter(w io.Writer) *jsonlStatementWriter {
+	return &jsonlStatementWriter{out: w, encoder: json.NewEncoder(w)}
+}
+
+func (w *jsonlStatementWriter) begin(statement *Statement) error {
+	opening := models.NewMoney(statement.OpeningBalance, statement.Currency.Code)
+	return w.encoder.Encode(jsonlStatementRecord{
+		Record:         "header",
+		WalletID:       statement.Wallet.ID,
+		Currency:       statement.Currency.Code,
+		From:           &statement.From,
+		To:             &statement.To,
+		OpeningBalance: &opening,
+	})
+}
+
+func (w *jsonlStatementWriter) entry(transaction *models.Transaction, delta int64) error {
+	change := models.NewMoney(delta, transaction.Currency)
+	return w.encoder.Encode(jsonlStatementRecord{
+		Record:      "transaction",
+		Delta:       &change,
+		Transaction: transaction,
+	})
+}
+
+func (w *jsonlStatementWriter) end(statement *Statement, closingBalance int64, count int) error {
+	closing := models.NewMoney(closingBalance, statement.Currency.Code)
+	return w.encoder.Encode(jsonlStatementRecord{
+		Record:         "summary",
+		ClosingBalance: &closing,
+		Entries:        &count,
+	})
+}
+
+func (w *jsonlStatementWriter) flush() error {
+	return flushWriter(w.out)
+}
+
+// ofxTime is the OFX date-time format (always written in UTC)
+// ofxTime OFX tarih-saat formatıdır (her zaman UTC yazılır)
+const ofxTime = "20060102150405"
+
+// ofxStatementWriter writes an OFX 1.0.2 bank statement, the version personal finance apps
+// import most reliably
+//
+// ofxStatementWriter kişisel finans uygulamalarının en sorunsuz içe aktardığı sürüm olan
+// OFX 1.0.2 banka hesap özeti yazar
+type ofxStatementWriter struct {
+	out      *bufio.Writer
+	currency models.Currency
+}
+
+func newOFXStatementWriter(w io.Writer) *ofxStatementWriter {
+	if buffered, ok := w.(*bufio.Writer); ok {
+		return &ofxStatementWriter{out: buffered}
+	}
+	return &ofxStatementWriter{out: bufio.NewWriter(w)}
+}
+
+// ofxEscape makes free text safe inside an SGML element
+// ofxEscape serbest metni bir SGML elemanı içinde güvenli hale getirir
+var ofxEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", " ", "\n", " ")
+
+func (w *ofxStatementWriter) begin(statement *Statement) error {
+	w.currency = statement.Currency
+	_, err := fmt.Fprintf(w.out, "OFXHEADER:100\r\nDATA:OFXSGML\r\nVERSION:102\r\nSECURITY:NONE\r\n"+
+		"ENCODING:USASCII\r\nCHARSET:1252\r\nCOMPRESSION:NONE\r\nOLDFILEUID:NONE\r\nNEWFILEUID:NONE\r\n\r\n"+
+		"<OFX>\r\n<SIGNONMSGSRSV1><SONRS>\r\n<STATUS><CODE>0<SEVERITY>INFO</STATUS>\r\n"+
+		"<DTSERVER>%s<LANGUAGE>ENG\r\n</SONRS></SIGNONMSGSRSV1>\r\n"+
+		"<BANKMSGSRSV1><STMTTRNRS>\r\n<TRNUID>0<STATUS><CODE>0<SEVERITY>INFO</STATUS>\r\n"+
+		"<STMTRS><CURDEF>%s\r\n"+
+		"<BANKACCTFROM><BANKID>MINIPAY<ACCTID>%d<ACCTTYPE>CHECKING</BANKACCTFROM>\r\n"+
+		"<BANKTRANLIST><DTSTART>%s<DTEND>%s\r\n",
+		statement.GeneratedAt.UTC().Format(ofxTime),
+		w.currency.Code,
+		statement.Wallet.ID,
+		statement.From.UTC().Format(ofxTime),
+		statement.To.UTC().Format(ofxTime),
+	)
+	return err
+}
+
+func (w *ofxStatementWriter) entry(transaction *models.Transaction, delta int64) error {
+	trnType := "CREDIT"
+	if delta < 0 {
+		trnType = "DEBIT"
+	}
+	switch transaction.Type {
+	case models.TransactionTypeDeposit:
+		trnType = "DEP"
+	case models.TransactionTypeTransferSent, models.TransactionTypeTransferReceived:
+		trnType = "XFER"
+	case models.TransactionTypeFee:
+		trnType = "FEE"
+	case models.TransactionTypePotIn, models.TransactionTypePotOut, models.TransactionTypeJointContribution:
+		trnType = "XFER"
+	}
+
+	// Empty elements are not allowed in OFX, so MEMO is only written when there is something to say
+	// OFX'te boş eleman olamaz, bu yüzden MEMO sadece içeriği varsa yazılır
+	memo := transaction.Reason
+	if other := counterparty(transaction); other != "" {
+		memo = strings.TrimSpace("user " + other + " " + memo)
+	}
+	if memo != "" {
+		memo = "<MEMO>" + ofxEscape.Replace(memo)
+	}
+
+	_, err := fmt.Fprintf(w.out, "<STMTTRN><TRNTYPE>%s<DTPOSTED>%s<TRNAMT>%s<FITID>%d<NAME>%s%s</STMTTRN>\r\n",
+		trnType,
+		transaction.CreatedAt.UTC().Format(ofxTime),
+		w.currency.FormatMinor(delta),
+		transaction.ID,
+		ofxEscape.Replace(transaction.Type),
+		memo,
+	)
+	return err
+}
+
+func (w *ofxStatementWriter) end(statement *Statement, closingBalance int64, _ int) error {
+	_, err := fmt.Fprintf(w.out, "</BANKTRANLIST>\r\n<LEDGERBAL><BALAMT>%s<DTASOF>%s</LEDGERBAL>\r\n"+
+		"</STMTRS></STMTTRNRS></BANKMSGSRSV1>\r\n</OFX>\r\n",
+		w.currency.FormatMinor(closingBalance),
+		statement.To.UTC().Format(ofxTime),
+	)
+	return err
+}
+
+func (w *ofxStatementWriter) flush() error {
+	return w.out.Flush()
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/repositories"
+)
+
+// Statement formats
+// Hesap özeti formatları
+const (
+	StatementFormatCSV   = "csv"
+	StatementFormatOFX   = "ofx"
+	StatementFormatJSONL = "jsonl"
+)
+
+// statementBatchSize bounds how many history rows are in memory while a statement is written
+// statementBatchSize hesap özeti yazılırken bellekte tutulan geçmiş satırı sayısını sınırlar
+const statementBatchSize = 500
+
+// Statement describes one wallet's statement for a period; the entries are streamed by Write
+// Statement bir cüzdanın bir döneme ait hesap özetini tanımlar; kayıtlar Write ile akıtılır
+type Statement struct {
+	Wallet         *models.Wallet
+	Currency       models.Currency
+	Format         string
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	GeneratedAt    time.Time
+}
+
+// statementWriter renders a statement in one file format
+// statementWriter hesap özetini bir dosya formatında yazar
+type statementWriter interface {
+	begin(statement *Statement) error
+	entry(transaction *models.Transaction, delta int64) error
+	end(statement *Statement, closingBalance int64, count int) error
+	flush() error
+}
+
+// StatementService builds downloadable statements from transaction history
+// StatementService işlem geçmişinden indirilebilir hesap özetleri oluşturur
+type StatementService struct {
+	walletRepo      *repositories.WalletRepository
+	transactionRepo *repositories.TransactionRepository
+	defaultCurrency string
+	log             logger.Logger
+}
+
+func NewStatementService(
+	walletRepo *repositories.WalletRepository,
+	transactionRepo *repositories.TransactionRepository,
+	defaultCurrency string,
+	log logger.Logger,
+) *StatementService {
+	return &StatementService{
+		walletRepo:      walletRepo,
+		transactionRepo: transactionRepo,
+		defaultCurrency: defaultCurrency,
+		log:             log,
+	}
+}
+
+// StatementContentType returns the MIME type and file extension of a statement format
+// StatementContentType bir hesap özeti formatının MIME türünü ve dosya uzantısını döndürür
+func StatementContentType(format string) (string, string) {
+	switch format {
+	case StatementFormatOFX:
+		return "application/x-ofx", "ofx"
+	case StatementFormatJSONL:
+		return "application/x-ndjson", "jsonl"
+	}
+	return "text/csv; charset=utf-8", "csv"
+}
+
+// Open validates the request and computes the opening balance of the period [from, to).
+// The wallet must exist; ErrRecordNotFound is returned otherwise.
+//
+// Open isteği doğrular ve [from, to) döneminin açılış bakiyesini hesaplar.
+// Cüzdan mevcut olmalıdır; aksi halde ErrRecordNotFound döner.
+func (s *StatementService) Open(userID uint, currency, format string, from, to time.Time) (*Statement, error) {
+	if format == "" {
+		format = StatementFormatCSV
+	}
+	if format != StatementFormatCSV && format != StatementFormatOFX && format != StatementFormatJSONL {
+		return nil, errors.New("format must be csv, ofx or jsonl")
+	}
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+
+	code, err := resolveCurrency(currency, s.defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+	definition, err := models.LookupCurrency(code)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.walletRepo.FindByUserAndCurrency(userID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	// The opening balance is where the wallet stood after the last entry before the period
+	// Açılış bakiyesi, cüzdanın dönemden önceki son kayıttan sonraki bakiyesidir
+	opening, err := s.transactionRepo.BalanceBefore(userID, code, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Statement{
+		Wallet:         wallet,
+		Currency:       definition,
+		Format:         format,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		GeneratedAt:    time.Now(),
+	}, nil
+}
+
+// Write streams the statement to w batch by batch. Entries that only change the available
+// balance (holds placed or released) are left out, since no money was booked.
+// The closing balance is written last, so a statement cut off by an error is visibly incomplete.
+//
+// Write hesap özetini w'ye grup grup akıtır. Sadece kullanılabilir bakiyeyi değiştiren kayıtlar
+// (verilen veya serbest bırakılan provizyonlar) para kaydedilmediği için dahil edilmez.
+// Kapanış bakiyesi en son yazılır, böylece hata ile kesilen bir özetin eksik olduğu görülür.
+func (s *StatementService) Write(w io.Writer, statement *Statement) error {
+	var writer statementWriter
+	switch statement.Format {
+	case StatementFormatOFX:
+		writer = newOFXStatementWriter(w)
+	case StatementFormatJSONL:
+		writer = newJSONLStatementWriter(w)
+	default:
+		writer = newCSVStatementWriter(w)
+	}
+
+	if err := writer.begin(statement); err != nil {
+		return err
+	}
+
+	closing := statement.OpeningBalance
+	count := 0
+	err := s.transactionRepo.EachInRange(statement.Wallet.UserID, statement.Currency.Code, statement.From, statement.To, statementBatchSize,
+		func(batch []models.Transaction) error {
+			for i := range batch {
+				transaction := &batch[i]
+				closing = transaction.BalanceAfter
+
+				delta, _ := transaction.BalanceDelta()
+				if delta == 0 {
+					continue
+				}
+				if err := writer.entry(transaction, delta); err != nil {
+					return err
+				}
+				count++
+			}
+			return writer.flush()
+		})
+	if err != nil {
+		s.log.Error("Statement export failed", map[string]interface{}{
+			"user_id":  statement.Wallet.UserID,
+			"currency": statement.Currency.Code,
+			"error":    err.Error(),
+		})
+		return err
+	}
+
+	if err := writer.end(statement, closing, count); err != nil {
+		return err
+	}
+	if err := writer.flush(); err != nil {
+		return err
+	}
+
+	s.log.Info("Statement exported", map[string]interface{}{
+		"user_id":  statement.Wallet.UserID,
+		"currency": statement.Currency.Code,
+		"format":   statement.Format,
+		"entries":  count,
+	})
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestStatementBalances writes statements for periods before, across and after a wallet's
+// entries and checks the opening and closing balances and the entry count of each.
+//
+// TestStatementBalances bir cüzdanın kayıtlarından önceki, onları kapsayan ve sonraki dönemler
+// için hesap özeti yazar ve her birinin açılış ve kapanış bakiyelerini ve kayıt sayısını kontrol eder.
+func TestStatementBalances(t *testing.T) {
+	env := newTestEnv(t)
+	owner := env.newUser(t, "owner@example.com")
+	other := env.newUser(t, "other@example.com")
+
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	// Each step is moved to a fixed date, so the periods below do not depend on the clock
+	// Her adım sabit bir tarihe taşınır, böylece aşağıdaki dönemler saate bağlı olmaz
+	steps := []struct {
+		at  time.Time
+		run func() error
+	}{
+		{at: day(time.January, 10), run: func() error { return env.walletService.Deposit(owner, "TRY", mustDecimal(t, "100.00")) }},
+		{at: day(time.January, 20), run: func() error { return env.walletService.Deposit(owner, "TRY", mustDecimal(t, "50.00")) }},
+		{at: day(time.February, 5), run: func() error {
+			_, err := env.walletService.Transfer(owner, other, "TRY", mustDecimal(t, "30.00"))
+			return err
+		}},
+		{at: day(time.February, 10), run: func() error { return env.walletService.Deposit(owner, "TRY", mustDecimal(t, "10.00")) }},
+	}
+	var lastID uint
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("step at %s: %v", step.at.Format(time.DateOnly), err)
+		}
+		if err := env.db.GetDB().Exec("UPDATE transactions SET created_at = ? WHERE id > ?", step.at, lastID).Error; err != nil {
+			t.Fatalf("date step: %v", err)
+		}
+		if err := env.db.GetDB().Raw("SELECT MAX(id) FROM transactions").Scan(&lastID).Error; err != nil {
+			t.Fatalf("last id: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		opening  string
+		closing  string
+		entries  int
+	}{
+		{name: "before the first entry", from: day(time.January, 1), to: day(time.January, 5), opening: "0.00", closing: "0.00"},
+		{name: "first entries", from: day(time.January, 1), to: day(time.February, 1), opening: "0.00", closing: "150.00", entries: 2},
+		{name: "starts between entries", from: day(time.January, 15), to: day(time.February, 1), opening: "100.00", closing: "150.00", entries: 1},
+		{name: "transfer and deposit", from: day(time.February, 1), to: day(time.March, 1), opening: "150.00", closing: "130.00", entries: 2},
+		{name: "ends before the last entry", from: day(time.February, 1), to: day(time.February, 10), opening: "150.00", closing: "120.00", entries: 1},
+		{name: "after the last entry", from: day(time.March, 1), to: day(time.April, 1), opening: "130.00", closing: "130.00"},
+	}
+
+	statements := NewStatementService(env.walletRepo, env.transactionRepo, "TRY", nopLogger{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, err := statements.Open(owner, "TRY", StatementFormatJSONL, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			var out bytes.Buffer
+			if err := statements.Write(&out, statement); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+
+			var header, summary struct {
+				OpeningBalance string `json:"opening_balance"`
+				ClosingBalance string `json:"closing_balance"`
+				Entries        int    `json:"entries"`
+			}
+			lines := 0
+			scanner := bufio.NewScanner(&out)
+			for scanner.Scan() {
+				var record struct {
+					Record string `json:"record"`
+				}
+				if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+					t.Fatalf("line %d: %v", lines+1, err)
+				}
+				switch record.Record {
+				case "header":
+					json.Unmarshal(scanner.Bytes(), &header)
+				case "summary":
+					json.Unmarshal(scanner.Bytes(), &summary)
+				}
+				lines++
+			}
+
+			if header.OpeningBalance != tt.opening {
+				t.Errorf("opening balance = %q, want %q", header.OpeningBalance, tt.opening)
+			}
+			if summary.ClosingBalance != tt.closing {
+				t.Errorf("closing balance = %q, want %q", summary.ClosingBalance, tt.closing)
+			}
+			if summary.Entries != tt.entries || lines != tt.entries+2 {
+				t.Errorf("entries = %d in %d lines, want %d", summary.Entries, lines, tt.entries)
+			}
+		})
+	}
+}
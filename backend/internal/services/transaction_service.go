@@ -1,51 +1,267 @@
 package services
 
 import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
 	"mini-pay-backend/internal/logger"
 	"mini-pay-backend/internal/models"
 	"mini-pay-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// History page sizes
+// Geçmiş sayfa boyutları
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 200
 )
 
+// ErrInvalidCursor is returned for a cursor this server did not hand out
+// ErrInvalidCursor bu sunucunun vermediği bir cursor için döner
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// HistoryPage is one page of history; NextCursor is empty on the last page
+// HistoryPage geçmişin bir sayfasıdır; son sayfada NextCursor boştur
+type HistoryPage struct {
+	Transactions []models.Transaction `json:"transactions"`
+	NextCursor   string               `json:"next_cursor"`
+}
+
+// Counterparty is the public face of the other user of a transaction
+// Counterparty bir işlemin karşı tarafındaki kullanıcının herkese açık görünümüdür
+type Counterparty struct {
+	DisplayName string `json:"display_name"`
+}
+
+// LinkedEntry is a row written by the same money movement. Rows of other users only
+// show what the caller already knows from its own row, never their balance.
+//
+// LinkedEntry aynı para hareketinin yazdığı bir satırdır. Başka kullanıcıların satırları
+// sadece çağıranın kendi satırından zaten bildiklerini gösterir, bakiyelerini asla.
+type LinkedEntry struct {
+	ID        uint         `json:"id"`
+	Type      string       `json:"type"`
+	Amount    models.Money `json:"amount"`
+	Currency  string       `json:"currency"`
+	Rate      string       `json:"rate,omitempty"`
+	Own       bool         `json:"own"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// TransactionDetail is one transaction with everything around it
+// TransactionDetail bir işlemi çevresindeki her şeyle birlikte gösterir
+type TransactionDetail struct {
+	Transaction   *models.Transaction `json:"transaction"`
+	Status        string              `json:"status"`
+	Counterparty  *Counterparty       `json:"counterparty,omitempty"`
+	LinkedEntries []LinkedEntry       `json:"linked_entries"`
+}
+
 // TransactionService provides business logic for transaction history
 // TransactionService, işlem geçmişi iş mantığını sağlar
 type TransactionService struct {
 	transactionRepo *repositories.TransactionRepository
+	userRepo        *repositories.UserRepository
 	log             logger.Logger
 }
 
-func NewTransactionService(repo *repositories.TransactionRepository, log logger.Logger) *TransactionService {
-	return &TransactionService{transactionRepo: repo, log: log}
+func NewTransactionService(repo *repositories.TransactionRepository, userRepo *repositories.UserRepository, log logger.Logger) *TransactionService {
+	return &TransactionService{transactionRepo: repo, userRepo: userRepo, log: log}
+}
+
+// WithTx returns a copy of the service bound to a unit of work
+// WithTx bir unit of work'e bağlı servis kopyası döndürür
+func (s *TransactionService) WithTx(repos *repositories.Repositories) *TransactionService {
+	return &TransactionService{transactionRepo: repos.Transactions, userRepo: repos.Users, log: s.log}
 }
 
-// Record creates a transaction record
-// Record yeni bir işlem kaydı oluşturur
-func (s *TransactionService) Record(userID uint, txType string, amount int64, balanceAfter int64, targetUserID *uint) {
-	transaction := &models.Transaction{
+// Record creates a transaction record; a failure must abort the money movement
+// Record yeni bir işlem kaydı oluşturur; hata para hareketini iptal etmelidir
+func (s *TransactionService) Record(userID uint, txType string, amount int64, currency string, balanceAfter int64, targetUserID *uint) error {
+	return s.RecordEntry(&models.Transaction{
 		UserID:       userID,
 		Type:         txType,
 		Amount:       amount,
+		Currency:     currency,
 		TargetUserID: targetUserID,
 		BalanceAfter: balanceAfter,
-	}
+	})
+}
+
+// RecordEntry saves a fully built transaction (reversal links, reasons, ...)
+// RecordEntry tamamen hazırlanmış bir işlem kaydını saklar (geri alma bağlantısı, neden, ...)
+func (s *TransactionService) RecordEntry(transaction *models.Transaction) error {
 	if err := s.transactionRepo.Create(transaction); err != nil {
 		s.log.Error("Failed to record transaction", map[string]interface{}{
-			"user_id": userID,
-			"type":    txType,
-		})
-	} else {
-		s.log.Info("Transaction recorded", map[string]interface{}{
-			"user_id": userID,
-			"type":    txType,
-			"amount":  amount,
+			"user_id": transaction.UserID,
+			"type":    transaction.Type,
 		})
+		return err
 	}
+
+	s.log.Info("Transaction recorded", map[string]interface{}{
+		"user_id": transaction.UserID,
+		"type":    transaction.Type,
+		"amount":  transaction.Amount,
+	})
+	return nil
 }
 
-// GetHistory retrieves user's transaction history
-// GetHistory kullanıcının işlem geçmişini döndürür
-func (s *TransactionService) GetHistory(userID uint) ([]models.Transaction, error) {
+// GetHistory retrieves one page of the user's history, newest first.
+// An empty cursor starts at the newest row; limit is clamped to MaxHistoryLimit.
+//
+// GetHistory kullanıcının geçmişinin bir sayfasını yeniden eskiye döndürür.
+// Boş cursor en yeni satırdan başlar; limit MaxHistoryLimit ile sınırlanır.
+func (s *TransactionService) GetHistory(userID uint, filter repositories.HistoryFilter, cursor string, limit int) (*HistoryPage, error) {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	if limit > MaxHistoryLimit {
+		limit = MaxHistoryLimit
+	}
+
+	var after *repositories.HistoryCursor
+	if cursor != "" {
+		decoded, err := decodeHistoryCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = decoded
+	}
+
 	s.log.Info("Fetching transaction history", map[string]interface{}{
 		"user_id": userID,
+		"limit":   limit,
 	})
-	return s.transactionRepo.FindByUser(userID)
+
+	// One extra row tells whether another page follows
+	// Fazladan bir satır başka bir sayfa olup olmadığını gösterir
+	transactions, err := s.transactionRepo.FindPage(userID, filter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &HistoryPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		last := page.Transactions[limit-1]
+		page.NextCursor = encodeHistoryCursor(repositories.HistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	return page, nil
+}
+
+// encodeHistoryCursor makes an opaque cursor. The timestamp keeps its full precision and
+// zone offset, so it compares equal to the stored value it came from.
+//
+// encodeHistoryCursor opak bir cursor üretir. Zaman damgası tam hassasiyetini ve saat dilimi
+// farkını korur, böylece geldiği kayıtlı değerle eşit karşılaştırılır.
+func encodeHistoryCursor(cursor repositories.HistoryCursor) string {
+	raw := cursor.CreatedAt.Format(time.RFC3339Nano) + "|" + strconv.FormatUint(uint64(cursor.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeHistoryCursor reverses encodeHistoryCursor
+// decodeHistoryCursor encodeHistoryCursor işlemini tersine çevirir
+func decodeHistoryCursor(cursor string) (*repositories.HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+	parsedTime, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parsedID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &repositories.HistoryCursor{CreatedAt: parsedTime, ID: uint(parsedID)}, nil
+}
+
+// GetDetail returns one transaction of the user with its counterparty and linked entries.
+// Transactions of other users are reported as not found.
+//
+// GetDetail kullanıcının bir işlemini karşı tarafı ve bağlı kayıtlarıyla döndürür.
+// Başka kullanıcıların işlemleri bulunamadı olarak raporlanır.
+func (s *TransactionService) GetDetail(userID, transactionID uint) (*TransactionDetail, error) {
+	transaction, err := s.transactionRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	// The counterparty is shown by display name only, so its user ID is left out of the copy sent back
+	// Karşı taraf sadece gösterim adıyla gösterilir, bu yüzden kullanıcı ID'si geri gönderilen kopyada yer almaz
+	shown := *transaction
+	shown.TargetUserID = nil
+
+	detail := &TransactionDetail{
+		Transaction:   &shown,
+		Status:        transaction.Status(),
+		LinkedEntries: []LinkedEntry{},
+	}
+
+	if transaction.TargetUserID != nil {
+		// A deleted counterparty simply has no display info
+		// Silinmiş bir karşı tarafın gösterim bilgisi yoktur
+		if user, err := s.userRepo.FindByID(*transaction.TargetUserID); err == nil {
+			detail.Counterparty = &Counterparty{DisplayName: user.DisplayName()}
+		}
+	}
+
+	// Rows written before correlation IDs existed have no linked entries
+	// Korelasyon ID'lerinden önce yazılmış satırların bağlı kaydı yoktur
+	if transaction.CorrelationID == "" {
+		return detail, nil
+	}
+
+	linked, err := s.transactionRepo.FindByCorrelation(transaction.CorrelationID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range linked {
+		entry := &linked[i]
+		if entry.ID == transaction.ID {
+			continue
+		}
+
+		// What the other side paid in fees is none of this user's business
+		// Karşı tarafın ödediği ücret bu kullanıcıyı ilgilendirmez
+		if entry.Type == models.TransactionTypeFee && entry.UserID != userID {
+			continue
+		}
+
+		detail.LinkedEntries = append(detail.LinkedEntries, LinkedEntry{
+			ID:        entry.ID,
+			Type:      entry.Type,
+			Amount:    models.NewMoney(entry.Amount, entry.Currency),
+			Currency:  entry.Currency,
+			Rate:      entry.Rate,
+			Own:       entry.UserID == userID,
+			CreatedAt: entry.CreatedAt,
+		})
+
+		// Reversals are booked against the sending side, so the receiving side
+		// takes its status from there
+		// Geri almalar gönderen tarafa işlenir, bu yüzden alan taraf durumunu oradan alır
+		if detail.Status == models.TransactionStatusCompleted {
+			detail.Status = entry.Status()
+		}
+	}
+
+	return detail, nil
 }
@@ -2,199 +2,627 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"time"
+
+	"mini-pay-backend/internal/fees"
 	"mini-pay-backend/internal/logger"
+	"mini-pay-backend/internal/models"
 	"mini-pay-backend/internal/repositories"
-
-	"gorm.io/gorm"
 )
 
+// ErrInsufficientFunds is returned when the available balance does not cover an amount
+// ErrInsufficientFunds kullanılabilir bakiye tutarı karşılamadığında döner
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
 // WalletService contains wallet-related business logic
 // WalletService cüzdan ile ilgili iş mantığını içerir
 type WalletService struct {
+	uow                *repositories.UnitOfWork
 	walletRepo         *repositories.WalletRepository
 	transactionService *TransactionService
+	ledgerService      *LedgerService
+	limitService       *LimitService
+	feeService         *FeeService
+	defaultCurrency    string
 	log                logger.Logger
 }
 
 // Constructor for WalletService
 // WalletService için constructor
 func NewWalletService(
+	uow *repositories.UnitOfWork,
 	walletRepo *repositories.WalletRepository,
 	transactionService *TransactionService,
+	ledgerService *LedgerService,
+	limitService *LimitService,
+	feeService *FeeService,
+	defaultCurrency string,
 	log logger.Logger,
 ) *WalletService {
 	return &WalletService{
+		uow:                uow,
 		walletRepo:         walletRepo,
 		transactionService: transactionService,
+		ledgerService:      ledgerService,
+		limitService:       limitService,
+		feeService:         feeService,
+		defaultCurrency:    defaultCurrency,
 		log:                log,
 	}
 }
 
-// GetBalance returns user's balance
-// GetBalance kullanıcının mevcut bakiyesini döndürür
-func (s *WalletService) GetBalance(userID uint) (int64, error) {
+// resolveCurrency validates a currency code; an empty code means the default currency
+// resolveCurrency para birimi kodunu doğrular; boş kod varsayılan para birimi demektir
+func resolveCurrency(code, defaultCurrency string) (string, error) {
+	if code == "" {
+		code = defaultCurrency
+	}
+	currency, err := models.LookupCurrency(code)
+	if err != nil {
+		return "", err
+	}
+	return currency.Code, nil
+}
+
+// resolveAmount validates a currency code and converts a client amount into its minor units
+// resolveAmount para birimi kodunu doğrular ve istemci tutarını alt birimlerine çevirir
+func resolveAmount(code, defaultCurrency string, value models.Decimal) (string, int64, error) {
+	if code == "" {
+		code = defaultCurrency
+	}
+	currency, err := models.LookupCurrency(code)
+	if err != nil {
+		return "", 0, err
+	}
+	amount, err := value.Minor(currency)
+	if err != nil {
+		return "", 0, err
+	}
+	return currency.Code, amount, nil
+}
 
-	s.log.Info("WalletService.GetBalance called", map[string]interface{}{
+// GetBalances returns every wallet of the user (one per currency)
+// GetBalances kullanıcının tüm cüzdanlarını döndürür (para birimi başına bir tane)
+func (s *WalletService) GetBalances(userID uint) ([]models.Wallet, error) {
+
+	s.log.Info("WalletService.GetBalances called", map[string]interface{}{
 		"user_id": userID,
 	})
 
-	wallet, err := s.walletRepo.FindByUserID(userID)
+	wallets, err := s.walletRepo.FindAllByUserID(userID)
 	if err != nil {
+		return nil, err
+	}
+	if len(wallets) == 0 {
 		s.log.Error("Wallet not found for user", map[string]interface{}{
 			"user_id": userID,
 		})
-		return 0, err
+		return nil, errors.New("wallet not found")
 	}
 
-	s.log.Info("Wallet balance retrieved", map[string]interface{}{
+	// Cross-check with the ledger; drift is logged for reconciliation and never fails the read
+	// Defter ile karşılaştır; sapma mutabakat için loglanır ve okumayı asla başarısız kılmaz
+	for i := range wallets {
+		if err := s.ledgerService.VerifyWallet(&wallets[i]); err != nil && !errors.Is(err, ErrLedgerMismatch) {
+			s.log.Error("Ledger check failed", map[string]interface{}{
+				"wallet_id": wallets[i].ID,
+				"error":     err.Error(),
+			})
+		}
+	}
+
+	s.log.Info("Wallet balances retrieved", map[string]interface{}{
 		"user_id": userID,
-		"balance": wallet.Balance,
+		"wallets": len(wallets),
 	})
 
-	return wallet.Balance, nil
+	return wallets, nil
+}
+
+// OpenWallet opens a wallet in another currency (no-op if it already exists)
+// OpenWallet başka bir para biriminde cüzdan açar (zaten varsa bir şey yapmaz)
+func (s *WalletService) OpenWallet(userID uint, currency string) (*models.Wallet, error) {
+	code, err := resolveCurrency(currency, s.defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.walletRepo.FindOrCreate(userID, code)
+	if err != nil {
+		s.log.Error("Wallet creation failed", map[string]interface{}{
+			"user_id":  userID,
+			"currency": code,
+		})
+		return nil, err
+	}
+
+	return wallet, nil
 }
 
 // Deposit adds money to wallet and records transaction
 // Deposit para ekler ve transaction kaydı oluşturur
-func (s *WalletService) Deposit(userID uint, amount int64) error {
+func (s *WalletService) Deposit(userID uint, currency string, value models.Decimal) error {
 
+	currency, amount, err := resolveAmount(currency, s.defaultCurrency, value)
+	if err != nil {
+		return err
+	}
 	if amount <= 0 {
 		return errors.New("invalid deposit amount")
 	}
 
-	wallet, err := s.walletRepo.FindByUserID(userID)
+	// Balance, ledger entry and history row commit together; retried on version conflict
+	// Bakiye, defter kaydı ve geçmiş satırı birlikte commit edilir; versiyon çakışmasında tekrar denenir
+	// Depositing in a new currency opens the wallet
+	// Yeni bir para biriminde yatırma cüzdanı açar
+	var wallet *models.Wallet
+	err = retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			ledger := s.ledgerService.WithTx(repos)
+
+			var err error
+			wallet, err = repos.Wallets.FindOrCreate(userID, currency)
+			if err != nil {
+				s.log.Error("Wallet not found", map[string]interface{}{"user_id": userID})
+				return err
+			}
+
+			walletAccount, err := ledger.WalletAccount(wallet)
+			if err != nil {
+				return err
+			}
+			depositAccount, err := ledger.SystemAccount(models.SystemAccountDeposits, currency)
+			if err != nil {
+				return err
+			}
+
+			// A balance that would overflow is refused instead of wrapping around
+			// Taşacak bir bakiye sarmak yerine reddedilir
+			balance, err := models.NewMoney(wallet.Balance, currency).Add(models.NewMoney(amount, currency))
+			if err != nil {
+				return err
+			}
+			if err := repos.Wallets.UpdateBalance(wallet, balance.Minor); err != nil {
+				return err
+			}
+
+			// POST LEDGER ENTRY: wallet up, deposits counter account down
+			// Defter kaydı: cüzdan artar, yatırma karşı hesabı azalır
+			if err := ledger.Post(models.TransactionTypeDeposit, fmt.Sprintf("deposit user:%d", userID),
+				LedgerLine{Account: walletAccount, Amount: amount},
+				LedgerLine{Account: depositAccount, Amount: -amount},
+			); err != nil {
+				return err
+			}
+
+			// RECORD TRANSACTION
+			return s.transactionService.WithTx(repos).Record(userID, models.TransactionTypeDeposit, amount, currency, wallet.Balance, nil)
+		})
+	})
 	if err != nil {
-		s.log.Error("Wallet not found", map[string]interface{}{"user_id": userID})
-		return err
-	}
-
-	wallet.Balance += amount
-
-	if err := s.walletRepo.Update(wallet); err != nil {
 		s.log.Error("Deposit failed", map[string]interface{}{"user_id": userID})
 		return err
 	}
 
-	// RECORD TRANSACTION
-	s.transactionService.Record(userID, "deposit", amount, wallet.Balance, nil)
-
 	s.log.Info("Deposit successful", map[string]interface{}{
-		"user_id": userID,
-		"amount":  amount,
-		"balance": wallet.Balance,
+		"user_id":  userID,
+		"amount":   amount,
+		"currency": currency,
+		"balance":  wallet.Balance,
 	})
 
 	return nil
 }
 
-// Withdraw subtracts money and records transaction
-// Withdraw para çeker ve transaction kaydı oluşturur
-func (s *WalletService) Withdraw(userID uint, amount int64) error {
+// Withdraw subtracts money plus the withdraw fee and records both
+// Withdraw parayı ve çekim ücretini düşer, ikisini de kaydeder
+func (s *WalletService) Withdraw(userID uint, currency string, value models.Decimal) (*Fee, error) {
 
+	currency, amount, err := resolveAmount(currency, s.defaultCurrency, value)
+	if err != nil {
+		return nil, err
+	}
 	if amount <= 0 {
-		return errors.New("invalid withdraw amount")
+		return nil, errors.New("invalid withdraw amount")
 	}
 
-	wallet, err := s.walletRepo.FindByUserID(userID)
+	fee, err := s.feeService.Quote(fees.OperationWithdraw, currency, amount)
 	if err != nil {
-		s.log.Error("Wallet not found", map[string]interface{}{"user_id": userID})
-		return err
+		return nil, err
 	}
-
-	if wallet.Balance < amount {
-		s.log.Error("Insufficient funds", map[string]interface{}{
-			"user_id": userID,
-			"balance": wallet.Balance,
-			"attempt": amount,
-		})
-		return errors.New("insufficient funds")
+	total, err := models.NewMoney(amount, currency).Add(fee.Amount)
+	if err != nil {
+		return nil, err
 	}
 
-	wallet.Balance -= amount
-
-	if err := s.walletRepo.Update(wallet); err != nil {
+	// The funds check runs on every attempt against the freshly read balance
+	// Bakiye kontrolü her denemede yeni okunan bakiye üzerinden yapılır
+	var wallet *models.Wallet
+	err = retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			ledger := s.ledgerService.WithTx(repos)
+
+			var err error
+			wallet, err = repos.Wallets.FindByUserAndCurrency(userID, currency)
+			if err != nil {
+				s.log.Error("Wallet not found", map[string]interface{}{"user_id": userID})
+				return err
+			}
+
+			if wallet.Available() < total.Minor {
+				s.log.Error("Insufficient funds", map[string]interface{}{
+					"user_id": userID,
+					"balance": wallet.Balance,
+					"attempt": amount,
+					"fee":     fee.Amount.Minor,
+				})
+				return ErrInsufficientFunds
+			}
+
+			if err := s.limitService.Check(repos, userID, models.LimitOperationWithdraw, currency, amount); err != nil {
+				return err
+			}
+
+			walletAccount, err := ledger.WalletAccount(wallet)
+			if err != nil {
+				return err
+			}
+			withdrawAccount, err := ledger.SystemAccount(models.SystemAccountWithdrawals, currency)
+			if err != nil {
+				return err
+			}
+
+			if err := repos.Wallets.UpdateBalance(wallet, wallet.Balance-amount); err != nil {
+				return err
+			}
+
+			// POST LEDGER ENTRY: wallet down, withdrawals counter account up
+			// Defter kaydı: cüzdan azalır, çekim karşı hesabı artar
+			if err := ledger.Post(models.TransactionTypeWithdraw, fmt.Sprintf("withdraw user:%d", userID),
+				LedgerLine{Account: walletAccount, Amount: -amount},
+				LedgerLine{Account: withdrawAccount, Amount: amount},
+			); err != nil {
+				return err
+			}
+
+			// RECORD TRANSACTION, then the fee linked to it by the correlation ID
+			// İşlemi kaydet, ardından correlation ID ile ona bağlı ücreti kaydet
+			correlationID := models.NewCorrelationID()
+			if err := s.transactionService.WithTx(repos).RecordEntry(&models.Transaction{
+				UserID:        userID,
+				Type:          models.TransactionTypeWithdraw,
+				Amount:        amount,
+				Currency:      currency,
+				BalanceAfter:  wallet.Balance,
+				CorrelationID: correlationID,
+			}); err != nil {
+				return err
+			}
+
+			return s.feeService.Charge(repos, wallet, fee, correlationID, nil)
+		})
+	})
+	if err != nil {
 		s.log.Error("Withdraw failed", map[string]interface{}{"user_id": userID})
-		return err
+		return nil, err
 	}
 
-	// RECORD TRANSACTION
-	s.transactionService.Record(userID, "withdraw", amount, wallet.Balance, nil)
-
 	s.log.Info("Withdraw successful", map[string]interface{}{
-		"user_id": userID,
-		"amount":  amount,
-		"balance": wallet.Balance,
+		"user_id":  userID,
+		"amount":   amount,
+		"fee":      fee.Amount.Minor,
+		"currency": currency,
+		"balance":  wallet.Balance,
 	})
 
-	return nil
+	return fee, nil
 }
 
-// Transfer moves money between two wallets atomically
-// Transfer iki kullanıcı arasında para aktarır ve her iki tarafa transaction kaydı ekler
-func (s *WalletService) Transfer(db *gorm.DB, fromUserID, toUserID uint, amount int64) error {
+// Transfer moves money between two wallets of the same currency atomically.
+// The recipient's wallet in that currency is opened if needed; the sender pays the transfer fee.
+//
+// Transfer aynı para birimindeki iki cüzdan arasında atomik olarak para aktarır.
+// Gerekirse alıcının o para birimindeki cüzdanı açılır; transfer ücretini gönderen öder.
+func (s *WalletService) Transfer(fromUserID, toUserID uint, currency string, value models.Decimal) (*Fee, error) {
+	currency, amount, err := resolveAmount(currency, s.defaultCurrency, value)
+	if err != nil {
+		return nil, err
+	}
+	return s.send(fromUserID, toUserID, currency, amount, "")
+}
+
+// send runs a transfer of minor units in a validated currency, for the given purpose, in its own unit of work
+// send doğrulanmış bir para biriminde alt birim cinsinden, verilen amaç için bir transferi kendi unit of work'ünde çalıştırır
+func (s *WalletService) send(fromUserID, toUserID uint, currency string, amount int64, purpose string) (*Fee, error) {
 
 	if fromUserID == toUserID {
-		return errors.New("cannot transfer to self")
+		return nil, errors.New("cannot transfer to self")
 	}
 
 	if amount <= 0 {
-		return errors.New("invalid transfer amount")
+		return nil, errors.New("invalid transfer amount")
 	}
 
-	return db.Transaction(func(tx *gorm.DB) error {
-
-		fromWallet, err := s.walletRepo.FindByUserID(fromUserID)
-		if err != nil {
+	// Balances, ledger entry and both history rows commit or roll back together.
+	// A version conflict rolls back and retries the whole transfer.
+	//
+	// Bakiyeler, defter kaydı ve iki geçmiş satırı birlikte commit edilir ya da geri alınır.
+	// Versiyon çakışması transferi geri alır ve baştan dener.
+	var fee *Fee
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			var err error
+			_, fee, err = s.transfer(repos, fromUserID, toUserID, currency, amount, purpose)
 			return err
-		}
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		toWallet, err := s.walletRepo.FindByUserID(toUserID)
-		if err != nil {
-			return err
-		}
+	s.log.Info("Transfer completed", map[string]interface{}{
+		"from_user": fromUserID,
+		"to_user":   toUserID,
+		"amount":    amount,
+		"fee":       fee.Amount.Minor,
+		"currency":  currency,
+	})
 
-		if fromWallet.Balance < amount {
-			return errors.New("insufficient funds")
-		}
+	return fee, nil
+}
+
+// transfer moves money inside the caller's unit of work and returns the sender's history row and the fee charged.
+// Inputs must already be validated; it is shared by Transfer and flows that
+// have to commit the transfer together with their own changes. Those flows pass a purpose
+// so the sender cannot reverse the transfer on its own.
+//
+// transfer parayı çağıranın unit of work'ü içinde taşır, göndericinin geçmiş satırını ve alınan ücreti döndürür.
+// Girdiler önceden doğrulanmış olmalıdır; Transfer ve transferi kendi değişiklikleriyle
+// birlikte commit etmesi gereken akışlar tarafından kullanılır. Bu akışlar bir amaç verir,
+// böylece gönderici transferi tek başına geri alamaz.
+func (s *WalletService) transfer(repos *repositories.Repositories, fromUserID, toUserID uint, currency string, amount int64, purpose string) (*models.Transaction, *Fee, error) {
+	ledger := s.ledgerService.WithTx(repos)
+	history := s.transactionService.WithTx(repos)
+
+	fromWallet, err := repos.Wallets.FindByUserAndCurrency(fromUserID, currency)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// Update balances
-		fromWallet.Balance -= amount
-		toWallet.Balance += amount
+	if _, err := repos.Users.FindByID(toUserID); err != nil {
+		return nil, nil, errors.New("recipient not found")
+	}
 
-		// Save changes
-		if err := tx.Save(fromWallet).Error; err != nil {
-			return err
-		}
-		if err := tx.Save(toWallet).Error; err != nil {
+	toWallet, err := repos.Wallets.FindOrCreate(toUserID, currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fee, err := s.feeService.Quote(fees.OperationTransfer, currency, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	total, err := models.NewMoney(amount, currency).Add(fee.Amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fromWallet.Available() < total.Minor {
+		return nil, nil, ErrInsufficientFunds
+	}
+
+	if err := s.limitService.Check(repos, fromUserID, models.LimitOperationTransfer, currency, amount); err != nil {
+		return nil, nil, err
+	}
+
+	fromAccount, err := ledger.WalletAccount(fromWallet)
+	if err != nil {
+		return nil, nil, err
+	}
+	toAccount, err := ledger.WalletAccount(toWallet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Update balances (guarded by wallet version)
+	if err := repos.Wallets.UpdateBalance(fromWallet, fromWallet.Balance-amount); err != nil {
+		return nil, nil, err
+	}
+	credited, err := models.NewMoney(toWallet.Balance, currency).Add(models.NewMoney(amount, currency))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := repos.Wallets.UpdateBalance(toWallet, credited.Minor); err != nil {
+		return nil, nil, err
+	}
+
+	// POST LEDGER ENTRY: sender down, receiver up
+	// Defter kaydı: gönderen azalır, alıcı artar
+	if err := ledger.Post(models.JournalEntryTransfer, fmt.Sprintf("transfer user:%d -> user:%d", fromUserID, toUserID),
+		LedgerLine{Account: fromAccount, Amount: -amount},
+		LedgerLine{Account: toAccount, Amount: amount},
+	); err != nil {
+		return nil, nil, err
+	}
+
+	// RECORD TRANSACTIONS (BOTH USERS), linked by one correlation ID
+	correlationID := models.NewCorrelationID()
+
+	// Sender’s transaction
+	sent := &models.Transaction{
+		UserID:        fromUserID,
+		Type:          models.TransactionTypeTransferSent,
+		Amount:        amount,
+		Currency:      currency,
+		TargetUserID:  &toUserID,
+		BalanceAfter:  fromWallet.Balance,
+		CorrelationID: correlationID,
+		Purpose:       purpose,
+	}
+	if err := history.RecordEntry(sent); err != nil {
+		return nil, nil, err
+	}
+
+	// Receiver’s transaction
+	if err := history.RecordEntry(&models.Transaction{
+		UserID:        toUserID,
+		Type:          models.TransactionTypeTransferReceived,
+		Amount:        amount,
+		Currency:      currency,
+		TargetUserID:  &fromUserID,
+		BalanceAfter:  toWallet.Balance,
+		CorrelationID: correlationID,
+		Purpose:       purpose,
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	// The sender's fee is booked last, so the transfer row shows the balance right after the transfer
+	// Göndericinin ücreti en son kaydedilir, böylece transfer satırı transferden hemen sonraki bakiyeyi gösterir
+	if err := s.feeService.Charge(repos, fromWallet, fee, correlationID, nil); err != nil {
+		return nil, nil, err
+	}
+
+	return sent, fee, nil
+}
+
+// TransferConverted sends money in one currency and delivers it in another,
+// using a quote the sender locked beforehand.
+//
+// TransferConverted parayı bir para biriminde gönderip diğerinde teslim eder,
+// göndericinin önceden sabitlediği teklifi kullanır.
+func (s *WalletService) TransferConverted(fromUserID, toUserID, quoteID uint, amount models.Decimal) (*Conversion, error) {
+
+	if fromUserID == toUserID {
+		return nil, errors.New("cannot transfer to self")
+	}
+
+	if amount.IsZero() {
+		return nil, errors.New("invalid transfer amount")
+	}
+
+	var conversion *Conversion
+	err := retryOnConflict(s.log, func() error {
+		return s.uow.Do(func(repos *repositories.Repositories) error {
+			ledger := s.ledgerService.WithTx(repos)
+			history := s.transactionService.WithTx(repos)
+
+			var err error
+			conversion, err = consumeQuote(repos, fromUserID, quoteID, amount)
+			if err != nil {
+				return err
+			}
+
+			fromWallet, err := repos.Wallets.FindByUserAndCurrency(fromUserID, conversion.FromCurrency)
+			if err != nil {
+				return err
+			}
+			fee, err := s.feeService.Quote(fees.OperationTransfer, conversion.FromCurrency, conversion.FromAmount)
+			if err != nil {
+				return err
+			}
+			total, err := models.NewMoney(conversion.FromAmount, conversion.FromCurrency).Add(fee.Amount)
+			if err != nil {
+				return err
+			}
+			if fromWallet.Available() < total.Minor {
+				return ErrInsufficientFunds
+			}
+			if err := s.limitService.Check(repos, fromUserID, models.LimitOperationTransfer, conversion.FromCurrency, conversion.FromAmount); err != nil {
+				return err
+			}
+
+			if _, err := repos.Users.FindByID(toUserID); err != nil {
+				return errors.New("recipient not found")
+			}
+			toWallet, err := repos.Wallets.FindOrCreate(toUserID, conversion.ToCurrency)
+			if err != nil {
+				return err
+			}
+
+			if err := postConversion(ledger, fmt.Sprintf("transfer user:%d -> user:%d quote:%d", fromUserID, toUserID, quoteID), fromWallet, toWallet, conversion); err != nil {
+				return err
+			}
+
+			if err := repos.Wallets.UpdateBalance(fromWallet, fromWallet.Balance-conversion.FromAmount); err != nil {
+				return err
+			}
+			credited, err := models.NewMoney(toWallet.Balance, conversion.ToCurrency).Add(models.NewMoney(conversion.ToAmount, conversion.ToCurrency))
+			if err != nil {
+				return err
+			}
+			if err := repos.Wallets.UpdateBalance(toWallet, credited.Minor); err != nil {
+				return err
+			}
+
+			// RECORD TRANSACTIONS (BOTH USERS) with the rate used
+			correlationID := models.NewCorrelationID()
+			if err := history.RecordEntry(&models.Transaction{
+				UserID:        fromUserID,
+				Type:          models.TransactionTypeTransferSent,
+				Amount:        conversion.FromAmount,
+				Currency:      conversion.FromCurrency,
+				Rate:          conversion.Rate,
+				TargetUserID:  &toUserID,
+				BalanceAfter:  fromWallet.Balance,
+				CorrelationID: correlationID,
+			}); err != nil {
+				return err
+			}
+
+			if err := history.RecordEntry(&models.Transaction{
+				UserID:        toUserID,
+				Type:          models.TransactionTypeTransferReceived,
+				Amount:        conversion.ToAmount,
+				Currency:      conversion.ToCurrency,
+				Rate:          conversion.Rate,
+				TargetUserID:  &fromUserID,
+				BalanceAfter:  toWallet.Balance,
+				CorrelationID: correlationID,
+			}); err != nil {
+				return err
+			}
+
+			conversion.Fee = fee
+			return s.feeService.Charge(repos, fromWallet, fee, correlationID, nil)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Cross-currency transfer completed", map[string]interface{}{
+		"from_user":   fromUserID,
+		"to_user":     toUserID,
+		"from_amount": conversion.FromAmount,
+		"to_amount":   conversion.ToAmount,
+		"rate":        conversion.Rate,
+	})
+
+	return conversion, nil
+}
+
+// maxBalanceRetries bounds how often a conflicting balance update is retried
+// maxBalanceRetries çakışan bakiye güncellemesinin en fazla kaç kez deneneceğini sınırlar
+const maxBalanceRetries = 10
+
+// retryOnConflict re-runs fn while it fails with a wallet version conflict
+// retryOnConflict, fn cüzdan versiyon çakışmasıyla başarısız oldukça tekrar çalıştırır
+func retryOnConflict(log logger.Logger, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= maxBalanceRetries; attempt++ {
+		err = fn()
+		if !errors.Is(err, repositories.ErrVersionConflict) {
 			return err
 		}
 
-		// RECORD TRANSACTIONS (BOTH USERS)
-
-		// Sender’s transaction
-		s.transactionService.Record(
-			fromUserID,
-			"transfer_sent",
-			amount,
-			fromWallet.Balance,
-			&toUserID,
-		)
-
-		// Receiver’s transaction
-		s.transactionService.Record(
-			toUserID,
-			"transfer_received",
-			amount,
-			toWallet.Balance,
-			&fromUserID,
-		)
-
-		s.log.Info("Transfer completed", map[string]interface{}{
-			"from_user": fromUserID,
-			"to_user":   toUserID,
-			"amount":    amount,
+		log.Info("Wallet version conflict, retrying", map[string]interface{}{
+			"attempt": attempt,
 		})
 
-		return nil
-	})
+		// Small linear backoff so competing writers spread out
+		// Rakip yazıcılar dağılsın diye küçük doğrusal bekleme
+		time.Sleep(time.Duration(attempt) * time.Millisecond)
+	}
+	return err
 }
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"mini-pay-backend/internal/config"
+	"mini-pay-backend/internal/database"
+	"mini-pay-backend/internal/fees"
+	"mini-pay-backend/internal/models"
+	"mini-pay-backend/internal/rates"
+	"mini-pay-backend/internal/repositories"
+)
+
+// nopLogger drops every log line so test output stays readable
+// nopLogger test çıktısı okunabilir kalsın diye tüm log satırlarını atar
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...map[string]interface{})  {}
+func (nopLogger) Error(string, ...map[string]interface{}) {}
+
+// testEnv wires the wallet services the way routes.go does, on an on-disk SQLite database
+// testEnv cüzdan servislerini routes.go'daki gibi, diskteki bir SQLite veritabanı üzerinde bağlar
+type testEnv struct {
+	db                 database.DB
+	uow                *repositories.UnitOfWork
+	userRepo           *repositories.UserRepository
+	walletRepo         *repositories.WalletRepository
+	transactionRepo    *repositories.TransactionRepository
+	ledgerService      *LedgerService
+	transactionService *TransactionService
+	walletService      *WalletService
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	db, err := database.NewGormDB(&config.AppConfig{
+		DBDriver: "sqlite",
+		DBName:   filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.GetDB().DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	rateProvider, err := rates.NewStaticProvider("")
+	if err != nil {
+		t.Fatalf("load rates: %v", err)
+	}
+	feeSchedule, err := fees.LoadSchedule("")
+	if err != nil {
+		t.Fatalf("load fees: %v", err)
+	}
+
+	log := nopLogger{}
+	env := &testEnv{
+		db:              db,
+		uow:             repositories.NewUnitOfWork(db),
+		userRepo:        repositories.NewUserRepository(db),
+		walletRepo:      repositories.NewWalletRepository(db),
+		transactionRepo: repositories.NewTransactionRepository(db),
+	}
+	env.transactionService = NewTransactionService(env.transactionRepo, env.userRepo, log)
+	env.ledgerService = NewLedgerService(repositories.NewLedgerRepository(db), log)
+	limitService := NewLimitService(repositories.NewLimitRepository(db), env.transactionRepo, env.userRepo, rateProvider, map[string]models.Limits{}, "TRY", log)
+	feeService := NewFeeService(feeSchedule, env.ledgerService, env.transactionService, "TRY", log)
+	env.walletService = NewWalletService(env.uow, env.walletRepo, env.transactionService, env.ledgerService, limitService, feeService, "TRY", log)
+	return env
+}
+
+// newUser creates a user with an empty TRY wallet
+// newUser boş bir TRY cüzdanı olan bir kullanıcı oluşturur
+func (e *testEnv) newUser(t *testing.T, email string) uint {
+	t.Helper()
+
+	user := &models.User{Email: email, PasswordHash: "x"}
+	if err := e.userRepo.Create(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if _, err := e.walletRepo.FindOrCreate(user.ID, "TRY"); err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+	return user.ID
+}
+
+func (e *testEnv) balance(t *testing.T, userID uint) int64 {
+	t.Helper()
+
+	wallet, err := e.walletRepo.FindByUserAndCurrency(userID, "TRY")
+	if err != nil {
+		t.Fatalf("load wallet: %v", err)
+	}
+	return wallet.Balance
+}
+
+// assertBooksAgree checks that every wallet matches its history and ledger account,
+// the ledger sums to zero and no hash chain is broken
+//
+// assertBooksAgree her cüzdanın geçmişi ve defter hesabıyla eşleştiğini, defterin
+// sıfıra toplandığını ve hiçbir hash zincirinin kırılmadığını kontrol eder
+func (e *testEnv) assertBooksAgree(t *testing.T) {
+	t.Helper()
+
+	report, err := NewReconciliationService(e.uow, e.walletRepo, nopLogger{}).Run(false)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	for _, discrepancy := range report.Discrepancies {
+		t.Errorf("wallet %d does not reconcile: %+v", discrepancy.WalletID, discrepancy.Issues)
+	}
+
+	totals, err := e.ledgerService.TrialBalance()
+	if err != nil {
+		t.Fatalf("trial balance: %v", err)
+	}
+	for currency, total := range totals {
+		if total != 0 {
+			t.Errorf("ledger for %s sums to %d, want 0", currency, total)
+		}
+	}
+
+	chains, err := NewChainService(e.transactionRepo, nopLogger{}).Verify()
+	if err != nil {
+		t.Fatalf("verify chains: %v", err)
+	}
+	for _, chainBreak := range chains.Breaks {
+		t.Errorf("broken chain: %+v", chainBreak)
+	}
+}
+
+// TestConcurrentWalletUpdates hammers one wallet with deposits and transfers
+// from many goroutines; no update may be lost and the ledger must agree with the wallets.
+//
+// TestConcurrentWalletUpdates tek bir cüzdana birçok goroutine'den para yatırma ve
+// transfer yağdırır; hiçbir güncelleme kaybolmamalı ve defter cüzdanlarla uyuşmalıdır.
+func TestConcurrentWalletUpdates(t *testing.T) {
+	env := newTestEnv(t)
+	hot := env.newUser(t, "hot@example.com")
+	other := env.newUser(t, "other@example.com")
+
+	if err := env.walletService.Deposit(hot, "TRY", mustDecimal(t, "1000.00")); err != nil {
+		t.Fatalf("initial deposit: %v", err)
+	}
+
+	const workers = 24
+	const rounds = 5
+	deposit, out, back := mustDecimal(t, "2.00"), mustDecimal(t, "3.00"), mustDecimal(t, "1.00")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*rounds*3)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				if err := env.walletService.Deposit(hot, "TRY", deposit); err != nil {
+					errs <- fmt.Errorf("deposit: %w", err)
+				}
+				if _, err := env.walletService.Transfer(hot, other, "TRY", out); err != nil {
+					errs <- fmt.Errorf("transfer out: %w", err)
+				}
+				if _, err := env.walletService.Transfer(other, hot, "TRY", back); err != nil {
+					errs <- fmt.Errorf("transfer in: %w", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("operation failed: %v", err)
+	}
+	if t.Failed() {
+		return
+	}
+
+	// Transfers below 1,000.00 TRY are free, so only the amounts move
+	// 1.000,00 TRY altındaki transferler ücretsizdir, bu yüzden yalnızca tutarlar taşınır
+	ops := int64(workers * rounds)
+	if got, want := env.balance(t, hot), int64(100000)+ops*(200-300+100); got != want {
+		t.Errorf("hot wallet balance = %d, want %d", got, want)
+	}
+	if got, want := env.balance(t, other), ops*(300-100); got != want {
+		t.Errorf("other wallet balance = %d, want %d", got, want)
+	}
+
+	env.assertBooksAgree(t)
+}
+
+func mustDecimal(t *testing.T, value string) models.Decimal {
+	t.Helper()
+
+	decimal, err := models.ParseDecimal(value)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return decimal
+}
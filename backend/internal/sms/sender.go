@@ -0,0 +1,35 @@
+package sms
+
+import "mini-pay-backend/internal/logger"
+
+// Sender interface defines how text messages reach a phone
+// Sender arayüzü kısa mesajların bir telefona nasıl ulaştığını tanımlar
+//
+// Why do we use this?
+// Development runs without an SMS provider; a real gateway can be swapped in later.
+// Geliştirme ortamı SMS sağlayıcısı olmadan çalışır; ileride gerçek bir ağ geçidi takılabilir.
+type Sender interface {
+	// Send delivers message to an E.164 phone number
+	// Send mesajı E.164 formatındaki bir telefon numarasına iletir
+	Send(phone, message string) error
+}
+
+// LogSender writes messages to the application log instead of sending them (development only)
+// LogSender mesajları göndermek yerine uygulama loguna yazar (sadece geliştirme için)
+type LogSender struct {
+	log logger.Logger
+}
+
+func NewLogSender(log logger.Logger) *LogSender {
+	return &LogSender{log: log}
+}
+
+// Send logs the message
+// Send mesajı loglar
+func (s *LogSender) Send(phone, message string) error {
+	s.log.Info("SMS (not sent, no provider configured)", map[string]interface{}{
+		"phone":   phone,
+		"message": message,
+	})
+	return nil
+}
@@ -21,6 +21,11 @@ func UnauthorizedError(c *fiber.Ctx, msg string) error {
 	return JSONError(c, fiber.StatusUnauthorized, msg)
 }
 
+// ForbiddenError shortcut for 403
+func ForbiddenError(c *fiber.Ctx, msg string) error {
+	return JSONError(c, fiber.StatusForbidden, msg)
+}
+
 // NotFoundError shortcut for 404
 func NotFoundError(c *fiber.Ctx, msg string) error {
 	return JSONError(c, fiber.StatusNotFound, msg)
@@ -30,3 +35,13 @@ func NotFoundError(c *fiber.Ctx, msg string) error {
 func InternalError(c *fiber.Ctx, msg string) error {
 	return JSONError(c, fiber.StatusInternalServerError, msg)
 }
+
+// ConflictError shortcut for 409
+func ConflictError(c *fiber.Ctx, msg string) error {
+	return JSONError(c, fiber.StatusConflict, msg)
+}
+
+// UnprocessableError shortcut for 422
+func UnprocessableError(c *fiber.Ctx, msg string) error {
+	return JSONError(c, fiber.StatusUnprocessableEntity, msg)
+}